@@ -92,7 +92,7 @@ var staticTests = []*cliTest{
 		args: "collect --host https://1.1.1.1 --username username --password password",
 		expectedErr: []string{
 			"remote error: tls: handshake failure",
-			"invalid character", // indicates that the server did not return a valid JSON response
+			"GET api/v1/fabric/virtual-machines failed with status", // an APIError: the host is not an NSX manager
 		},
 	},
 	{
@@ -33,15 +33,24 @@ func FileExist(path string) bool {
 }
 
 func WriteYamlUsingJSON[A any](content []A, file string) error {
+	out, err := YamlUsingJSON(content)
+	if err != nil {
+		return err
+	}
+	return WriteToFile(file, out)
+}
+
+// YamlUsingJSON returns a multi-document YAML of the given k8s resources
+func YamlUsingJSON[A any](content []A) (string, error) {
 	outs := make([]string, len(content))
 	for i := range content {
 		buf, err := marshalYamlUsingJSON(content[i])
 		if err != nil {
-			return err
+			return "", err
 		}
 		outs[i] = string(buf)
 	}
-	return WriteToFile(file, strings.Join(outs, "---\n"))
+	return strings.Join(outs, "---\n"), nil
 }
 
 func marshalYamlUsingJSON(content interface{}) ([]byte, error) {
@@ -1,10 +1,15 @@
 package common
 
+import "time"
+
 const (
 	CmdCollect  = "collect"
 	CmdAnalyze  = "analyze"
 	CmdGenerate = "generate"
 	CmdLint     = "lint"
+	CmdServe    = "serve"
+
+	DefaultNSXMaxRetries = 3
 )
 
 type InputArgs struct {
@@ -29,6 +34,19 @@ type InputArgs struct {
 	ResourceDumpFile          string
 	TopologyDumpFile          string
 	Anonymize                 bool
+	AnonymizationKey          string
+	NSXRecordDir              string
+	NSXReplayDir              string
+	NSXSessionAuth            bool
+	NSXClientCertFile         string
+	NSXClientKeyFile          string
+	NSXCACertFile             string
+	NSXRequestTimeout         time.Duration
+	NSXMaxRetries             int
+	ContinueOnError           bool
+	VCenterHost               string
+	VCenterUser               string
+	VCenterPassword           string
 
 	// analyzer args
 	OutputFile   string
@@ -44,6 +62,16 @@ type InputArgs struct {
 	EndpointsMapping        Endpoints
 	SegmentsMapping         Segments
 	PolicyOptimizationLevel PolicyOptimizationLevel
+	MigratedVMs             []string
+	MigratedSegments        []string
+	PolicyNamePrefix        string
+	ANPPriorityOffset       int
+	VMSpecs                 bool
+
+	// server args
+	ServerAddress string
+	MaxConfigs    int
+	PageSize      int
 }
 
 func (args *InputArgs) SetDefault() {
@@ -53,4 +81,5 @@ func (args *InputArgs) SetDefault() {
 	args.EndpointsMapping.SetDefault()
 	args.SegmentsMapping.SetDefault()
 	args.PolicyOptimizationLevel.SetDefault()
+	args.NSXMaxRetries = DefaultNSXMaxRetries
 }
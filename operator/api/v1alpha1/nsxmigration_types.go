@@ -33,7 +33,154 @@ type NSXMigrationSpec struct {
 	URL string `json:"url,omitempty"`
 	// References a secret containing credentials and
 	// other confidential information.
-	Secret core.ObjectReference `json:"secret" ref:"Secret"`
+	// Either secret or resourcesDump should be set.
+	// +optional
+	Secret core.ObjectReference `json:"secret,omitempty" ref:"Secret"`
+
+	// Options for the connection to the NSX manager, used with the secret.
+	// +optional
+	Connection ConnectionOptions `json:"connection,omitempty"`
+
+	// An NSX resources dump (the output of the nsxanalyzer collect command, possibly gzip compressed), used as the
+	// NSX config instead of collecting it from the NSX manager, for clusters which cannot reach the NSX manager.
+	// +optional
+	ResourcesDump *ResourcesDumpSource `json:"resourcesDump,omitempty"`
+
+	// Options for the synthesis of k8s network policies from the NSX config.
+	// +optional
+	SynthesisOptions SynthesisOptions `json:"synthesisOptions,omitempty"`
+
+	// If set, the generated resources are not applied to the cluster, and the planned changes
+	// are only reported in the status.
+	// +optional
+	DryRun bool `json:"dryRun,omitempty"`
+
+	// The interval for periodic re-collection of the NSX config and re-synthesis, to detect drift between
+	// the applied resources and the newly generated ones. If not set, the migration runs once per spec change.
+	// +optional
+	ResyncInterval *metav1.Duration `json:"resyncInterval,omitempty"`
+
+	// If set, drift detected by a periodic resync is applied to the cluster; otherwise it is only reported.
+	// +optional
+	AutoApplyDrift bool `json:"autoApplyDrift,omitempty"`
+
+	// What to do with the resources created by the migration when the NSXMigration is deleted:
+	// "Delete" removes the generated resources and the reports, "Orphan" leaves them in the cluster.
+	// Generated namespaces are never deleted.
+	// +kubebuilder:validation:Enum=Delete;Orphan
+	// +kubebuilder:default=Delete
+	// +optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+}
+
+// DeletionPolicy is the policy for the resources created by a migration upon deletion of the NSXMigration
+type DeletionPolicy string
+
+const (
+	// DeletionPolicyDelete means the resources created by the migration are deleted
+	DeletionPolicyDelete DeletionPolicy = "Delete"
+	// DeletionPolicyOrphan means the resources created by the migration are left in the cluster
+	DeletionPolicyOrphan DeletionPolicy = "Orphan"
+)
+
+// ResourcesDumpSource defines the source of an NSX resources dump; exactly one of its fields should be set.
+type ResourcesDumpSource struct {
+	// Selects a key of a ConfigMap in the namespace of the NSXMigration.
+	// A gzip compressed dump should be stored in the binaryData of the ConfigMap.
+	// +optional
+	ConfigMap *core.ConfigMapKeySelector `json:"configMap,omitempty"`
+
+	// Selects a key of a Secret in the namespace of the NSXMigration.
+	// +optional
+	Secret *core.SecretKeySelector `json:"secret,omitempty"`
+
+	// The absolute path of a file in the operator's pod, e.g. on a mounted PersistentVolumeClaim.
+	// The file should be under the resources dump root the operator is configured with (its --resources-dump-root flag).
+	// +optional
+	Path string `json:"path,omitempty"`
+}
+
+// ConnectionOptions defines the options for the connection to the NSX manager,
+// matching the connection options of the nsxanalyzer commands
+type ConnectionOptions struct {
+	// Authenticate by a session created with the credentials of the secret, instead of basic auth per request.
+	// +optional
+	SessionAuth bool `json:"sessionAuth,omitempty"`
+
+	// References a kubernetes.io/tls Secret in the namespace of the NSXMigration, whose tls.crt and tls.key are
+	// the client certificate and key to authenticate by, e.g. of an NSX principal identity.
+	// +optional
+	ClientCertificate *core.LocalObjectReference `json:"clientCertificate,omitempty"`
+
+	// Selects a key of a ConfigMap in the namespace of the NSXMigration, holding the PEM encoded CA certificates
+	// to verify the NSX manager certificate by.
+	// +optional
+	CABundle *core.ConfigMapKeySelector `json:"caBundle,omitempty"`
+
+	// The timeout of each request to the NSX manager. If not set, requests do not time out.
+	// +optional
+	RequestTimeout *metav1.Duration `json:"requestTimeout,omitempty"`
+}
+
+// SynthesisOptions defines the options for the synthesis of k8s resources from the NSX config,
+// matching the options of the nsxanalyzer generate command
+type SynthesisOptions struct {
+	// Include admin network policies in policy synthesis.
+	// +optional
+	SynthesizeAdminPolicies bool `json:"synthesizeAdminPolicies,omitempty"`
+
+	// Create a policy allowing access to target env dns pod.
+	// +optional
+	CreateDNSPolicy bool `json:"createDNSPolicy,omitempty"`
+
+	// NSX groups/tags that are always disjoint in their VM members, needed for an effective and sound synthesis.
+	// Each hint is a comma separated list of groups/tags (example: "frontend,backend").
+	// +optional
+	DisjointHints []string `json:"disjointHints,omitempty"`
+
+	// Automatic inference of NSX groups/tags that are always disjoint.
+	// +optional
+	InferDisjointHints bool `json:"inferDisjointHints,omitempty"`
+
+	// Target endpoints for synthesis.
+	// +kubebuilder:validation:Enum=vms;pods;both
+	// +optional
+	EndpointsMapping string `json:"endpointsMapping,omitempty"`
+
+	// Target mapping from segments.
+	// +kubebuilder:validation:Enum=pod-network;udns
+	// +optional
+	SegmentsMapping string `json:"segmentsMapping,omitempty"`
+
+	// Policy optimization level.
+	// +kubebuilder:validation:Enum=none;moderate;max
+	// +optional
+	PolicyOptimizationLevel string `json:"policyOptimizationLevel,omitempty"`
+
+	// Filter the synthesis results by VM names.
+	// +optional
+	VMs []string `json:"vms,omitempty"`
+
+	// Phased migration: names of the VMs migrated so far. Only migrated VMs get k8s resources and policies,
+	// while traffic to/from VMs remaining on NSX is allowed by their IP addresses.
+	// +optional
+	MigratedVMs []string `json:"migratedVMs,omitempty"`
+
+	// Phased migration: names of the segments whose VMs were migrated so far.
+	// +optional
+	MigratedSegments []string `json:"migratedSegments,omitempty"`
+
+	// Prefix for the names of the generated policies, so that the policies of multiple NSXMigrations
+	// of a phased migration do not conflict. Defaults to the NSXMigration name in a phased migration.
+	// +optional
+	PolicyNamePrefix string `json:"policyNamePrefix,omitempty"`
+
+	// Offset of the priorities of the generated admin network policies, so that the admin policies of multiple
+	// NSXMigrations of a phased migration do not conflict.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=999
+	// +optional
+	AdminPolicyPriorityOffset int32 `json:"adminPolicyPriorityOffset,omitempty"`
 }
 
 // NSXMigrationStatus defines the observed state of NSXMigration
@@ -42,7 +189,8 @@ type NSXMigrationStatus struct {
 	// Important: Run "make" to regenerate code after modifying this file
 
 	// Represents the observations of a MigrateNSX's current state.
-	// MigrateNSX.status.conditions.type are: "Available", "Progressing", and "Degraded"
+	// MigrateNSX.status.conditions.type are: "Available", "Drifted", "Degraded" (upon deletion), and per-phase conditions:
+	// "Collected", "Analyzed", "Synthesized" and "Applied"
 	// MigrateNSX.status.conditions.status are one of True, False, Unknown.
 	// MigrateNSX.status.conditions.reason the value should be a CamelCase string and producers of specific
 	// condition types may define expected values and meanings for this field, and whether the values
@@ -53,8 +201,126 @@ type NSXMigrationStatus struct {
 	// Conditions store the status conditions of the MigrateNSX instances
 	// +operator-sdk:csv:customresourcedefinitions:type=status
 	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,1,rep,name=conditions"`
+
+	// The changes to generated resources done by the last migration, or planned by it in dry-run mode
+	// or when a drift detected by a resync is not applied
+	// +optional
+	ResourceChanges []ResourceChange `json:"resourceChanges,omitempty"`
+
+	// The last time the NSX config was collected and the resources were synthesized
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+
+	// Summary of the NSX resources collected by the last migration
+	// +optional
+	Collection *CollectionStatus `json:"collection,omitempty"`
+
+	// Summary of the connectivity analysis of the last migration
+	// +optional
+	Analysis *AnalysisStatus `json:"analysis,omitempty"`
+
+	// Summary of the resources synthesized by the last migration
+	// +optional
+	Synthesis *SynthesisStatus `json:"synthesis,omitempty"`
+
+	// References to the ConfigMaps storing the reports of the last migration
+	// +optional
+	Reports []ReportReference `json:"reports,omitempty"`
 }
 
+// ReportReference references the ConfigMaps storing a report of a migration.
+// A report which exceeds the ConfigMap size limit is compressed, and split into multiple ConfigMaps if still required.
+type ReportReference struct {
+	// The name of the report (topology, segmentation, connectivity or generated-netpols).
+	Name string `json:"name"`
+	// The data key of the report in the ConfigMaps (binary data key if the report is compressed).
+	Key string `json:"key"`
+	// The names of the ConfigMaps storing the report, in the order of the report's parts.
+	ConfigMaps []string `json:"configMaps"`
+	// Whether the report is gzip compressed; the concatenation of the parts is the compressed report.
+	// +optional
+	Compressed bool `json:"compressed,omitempty"`
+	// The size of the report in bytes, before compression.
+	Size int `json:"size"`
+}
+
+// CollectionStatus summarizes the NSX resources collected by a migration
+type CollectionStatus struct {
+	// The time the NSX resources were collected.
+	// +optional
+	Time *metav1.Time `json:"time,omitempty"`
+	// The number of collected VMs.
+	VMs int `json:"vms"`
+	// The number of collected segments.
+	Segments int `json:"segments"`
+	// The number of collected groups.
+	Groups int `json:"groups"`
+	// The number of collected security policies.
+	SecurityPolicies int `json:"securityPolicies"`
+	// The number of rules of the collected security policies.
+	Rules int `json:"rules"`
+	// The number of collected services.
+	Services int `json:"services"`
+}
+
+// AnalysisStatus summarizes the connectivity analysis of a migration
+type AnalysisStatus struct {
+	// The number of analyzed pairs of VMs.
+	VMPairs int `json:"vmPairs"`
+	// The number of analyzed pairs of VMs with permitted connectivity.
+	PermittedVMPairs int `json:"permittedVMPairs"`
+	// The IDs of DFW rules which are not evaluated by the analysis, since they are not supported.
+	// +optional
+	RulesNotEvaluated []int `json:"rulesNotEvaluated,omitempty"`
+}
+
+// SynthesisStatus summarizes the resources synthesized by a migration
+type SynthesisStatus struct {
+	// The number of generated network policies.
+	NetworkPolicies int `json:"networkPolicies"`
+	// The number of generated admin network policies.
+	AdminNetworkPolicies int `json:"adminNetworkPolicies"`
+	// The number of generated namespaces.
+	Namespaces int `json:"namespaces"`
+	// The number of generated user defined networks.
+	UDNs int `json:"udns"`
+	// NSX constructs for which policies were not generated, since they are not supported.
+	// +optional
+	UnsupportedConstructs []string `json:"unsupportedConstructs,omitempty"`
+	// VMs for which the generated resources do not fully preserve the NSX connectivity.
+	// +optional
+	NotFullySupportedVMs []string `json:"notFullySupportedVMs,omitempty"`
+}
+
+// ResourceChange describes a change to a resource generated by the migration
+type ResourceChange struct {
+	// The kind of the resource.
+	Kind string `json:"kind"`
+	// The namespace of the resource, empty for cluster-scoped resources.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	// The name of the resource.
+	Name string `json:"name"`
+	// The change to the resource.
+	// +kubebuilder:validation:Enum=Create;Update;Delete;Release
+	Action ResourceAction `json:"action"`
+}
+
+// ResourceAction is the type of change to a generated resource
+type ResourceAction string
+
+const (
+	// ResourceActionCreate means the resource does not exist and is created
+	ResourceActionCreate ResourceAction = "Create"
+	// ResourceActionUpdate means the resource exists and differs from the generated content
+	ResourceActionUpdate ResourceAction = "Update"
+	// ResourceActionDelete means the resource was generated by a previous migration and is pruned
+	ResourceActionDelete ResourceAction = "Delete"
+	// ResourceActionRelease means the resource was generated by a previous migration and is still generated by another
+	// NSXMigration, thus it is not deleted but released from the migration
+	ResourceActionRelease ResourceAction = "Release"
+)
+
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 
@@ -21,16 +21,86 @@ limitations under the License.
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AnalysisStatus) DeepCopyInto(out *AnalysisStatus) {
+	*out = *in
+	if in.RulesNotEvaluated != nil {
+		in, out := &in.RulesNotEvaluated, &out.RulesNotEvaluated
+		*out = make([]int, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AnalysisStatus.
+func (in *AnalysisStatus) DeepCopy() *AnalysisStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AnalysisStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CollectionStatus) DeepCopyInto(out *CollectionStatus) {
+	*out = *in
+	if in.Time != nil {
+		in, out := &in.Time, &out.Time
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CollectionStatus.
+func (in *CollectionStatus) DeepCopy() *CollectionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CollectionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConnectionOptions) DeepCopyInto(out *ConnectionOptions) {
+	*out = *in
+	if in.ClientCertificate != nil {
+		in, out := &in.ClientCertificate, &out.ClientCertificate
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+	if in.CABundle != nil {
+		in, out := &in.CABundle, &out.CABundle
+		*out = new(corev1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RequestTimeout != nil {
+		in, out := &in.RequestTimeout, &out.RequestTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConnectionOptions.
+func (in *ConnectionOptions) DeepCopy() *ConnectionOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(ConnectionOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NSXMigration) DeepCopyInto(out *NSXMigration) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
@@ -88,6 +158,18 @@ func (in *NSXMigrationList) DeepCopyObject() runtime.Object {
 func (in *NSXMigrationSpec) DeepCopyInto(out *NSXMigrationSpec) {
 	*out = *in
 	out.Secret = in.Secret
+	in.Connection.DeepCopyInto(&out.Connection)
+	if in.ResourcesDump != nil {
+		in, out := &in.ResourcesDump, &out.ResourcesDump
+		*out = new(ResourcesDumpSource)
+		(*in).DeepCopyInto(*out)
+	}
+	in.SynthesisOptions.DeepCopyInto(&out.SynthesisOptions)
+	if in.ResyncInterval != nil {
+		in, out := &in.ResyncInterval, &out.ResyncInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NSXMigrationSpec.
@@ -110,6 +192,37 @@ func (in *NSXMigrationStatus) DeepCopyInto(out *NSXMigrationStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ResourceChanges != nil {
+		in, out := &in.ResourceChanges, &out.ResourceChanges
+		*out = make([]ResourceChange, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastSyncTime != nil {
+		in, out := &in.LastSyncTime, &out.LastSyncTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Collection != nil {
+		in, out := &in.Collection, &out.Collection
+		*out = new(CollectionStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Analysis != nil {
+		in, out := &in.Analysis, &out.Analysis
+		*out = new(AnalysisStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Synthesis != nil {
+		in, out := &in.Synthesis, &out.Synthesis
+		*out = new(SynthesisStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Reports != nil {
+		in, out := &in.Reports, &out.Reports
+		*out = make([]ReportReference, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NSXMigrationStatus.
@@ -121,3 +234,123 @@ func (in *NSXMigrationStatus) DeepCopy() *NSXMigrationStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportReference) DeepCopyInto(out *ReportReference) {
+	*out = *in
+	if in.ConfigMaps != nil {
+		in, out := &in.ConfigMaps, &out.ConfigMaps
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportReference.
+func (in *ReportReference) DeepCopy() *ReportReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceChange) DeepCopyInto(out *ResourceChange) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceChange.
+func (in *ResourceChange) DeepCopy() *ResourceChange {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceChange)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourcesDumpSource) DeepCopyInto(out *ResourcesDumpSource) {
+	*out = *in
+	if in.ConfigMap != nil {
+		in, out := &in.ConfigMap, &out.ConfigMap
+		*out = new(corev1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Secret != nil {
+		in, out := &in.Secret, &out.Secret
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourcesDumpSource.
+func (in *ResourcesDumpSource) DeepCopy() *ResourcesDumpSource {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourcesDumpSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SynthesisOptions) DeepCopyInto(out *SynthesisOptions) {
+	*out = *in
+	if in.DisjointHints != nil {
+		in, out := &in.DisjointHints, &out.DisjointHints
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.VMs != nil {
+		in, out := &in.VMs, &out.VMs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MigratedVMs != nil {
+		in, out := &in.MigratedVMs, &out.MigratedVMs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MigratedSegments != nil {
+		in, out := &in.MigratedSegments, &out.MigratedSegments
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SynthesisOptions.
+func (in *SynthesisOptions) DeepCopy() *SynthesisOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(SynthesisOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SynthesisStatus) DeepCopyInto(out *SynthesisStatus) {
+	*out = *in
+	if in.UnsupportedConstructs != nil {
+		in, out := &in.UnsupportedConstructs, &out.UnsupportedConstructs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NotFullySupportedVMs != nil {
+		in, out := &in.NotFullySupportedVMs, &out.NotFullySupportedVMs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SynthesisStatus.
+func (in *SynthesisStatus) DeepCopy() *SynthesisStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SynthesisStatus)
+	in.DeepCopyInto(out)
+	return out
+}
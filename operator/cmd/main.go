@@ -25,6 +25,7 @@ import (
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	udnv1 "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/userdefinednetwork/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
@@ -34,9 +35,11 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	admin "sigs.k8s.io/network-policy-api/apis/v1alpha1"
 
 	nsxv1alpha1 "github.com/np-guard/vmware-analyzer-operator/api/v1alpha1"
 	"github.com/np-guard/vmware-analyzer-operator/internal/controller"
+	webhooknsxv1alpha1 "github.com/np-guard/vmware-analyzer-operator/internal/webhook/v1alpha1"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -47,6 +50,9 @@ var (
 
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	// generated resources types
+	utilruntime.Must(admin.AddToScheme(scheme))
+	utilruntime.Must(udnv1.AddToScheme(scheme))
 
 	utilruntime.Must(nsxv1alpha1.AddToScheme(scheme))
 	// +kubebuilder:scaffold:scheme
@@ -58,6 +64,7 @@ func main() {
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var resourcesDumpRoot string
 	var tlsOpts []func(*tls.Config)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
@@ -69,6 +76,9 @@ func main() {
 		"If set, the metrics endpoint is served securely via HTTPS. Use --metrics-secure=false to use HTTP instead.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.StringVar(&resourcesDumpRoot, "resources-dump-root", "",
+		"The directory NSX resources dump files are mounted on, e.g. a PersistentVolumeClaim. "+
+			"NSXMigrations may read dump files only under it; if not set, dump files are not read.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -145,13 +155,21 @@ func main() {
 	}
 
 	if err = (&controller.NSXMigrationReconciler{
-		Client:   mgr.GetClient(),
-		Scheme:   mgr.GetScheme(),
-		Recorder: mgr.GetEventRecorderFor("NSXMigration-controller"),
+		Client:            mgr.GetClient(),
+		Scheme:            mgr.GetScheme(),
+		Recorder:          mgr.GetEventRecorderFor("NSXMigration-controller"),
+		ResourcesDumpRoot: resourcesDumpRoot,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "NSXMigration")
 		os.Exit(1)
 	}
+	// nolint:goconst
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		if err = webhooknsxv1alpha1.SetupNSXMigrationWebhookWithManager(mgr, resourcesDumpRoot); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "NSXMigration")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
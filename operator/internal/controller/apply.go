@@ -0,0 +1,236 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	udnv1 "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/userdefinednetwork/v1"
+	v1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	admin "sigs.k8s.io/network-policy-api/apis/v1alpha1"
+
+	nsxv1alpha1 "github.com/np-guard/vmware-analyzer-operator/api/v1alpha1"
+	"github.com/np-guard/vmware-analyzer/pkg/synthesis/ocpvirt/resources"
+)
+
+// generatedObjects returns the generated resources to apply, ordered such that namespaces are applied first
+func generatedObjects(generated *resources.Generated) []client.Object {
+	res := []client.Object{}
+	for _, ns := range generated.Namespaces {
+		res = append(res, ns)
+	}
+	for _, udn := range generated.UDNs {
+		res = append(res, udn)
+	}
+	for _, anp := range generated.AdminNetworkPolicies {
+		res = append(res, anp)
+	}
+	for _, policy := range generated.NetworkPolicies {
+		if policy.Namespace == "" {
+			policy.Namespace = v1.NamespaceDefault
+		}
+		res = append(res, policy)
+	}
+	return res
+}
+
+// prunableObjectLists returns lists of the kinds of generated resources that are pruned when no longer generated.
+// Namespaces are never pruned, since a generated namespace may have existed before the migration.
+func prunableObjectLists() []client.ObjectList {
+	return []client.ObjectList{
+		&networking.NetworkPolicyList{},
+		&admin.AdminNetworkPolicyList{},
+		&udnv1.UserDefinedNetworkList{},
+	}
+}
+
+type objectKey struct {
+	kind      string
+	namespace string
+	name      string
+}
+
+func (r *NSXMigrationReconciler) objectKey(obj client.Object) (objectKey, schema.GroupVersionKind, error) {
+	gvk, err := apiutil.GVKForObject(obj, r.Scheme)
+	if err != nil {
+		return objectKey{}, gvk, err
+	}
+	return objectKey{kind: gvk.Kind, namespace: obj.GetNamespace(), name: obj.GetName()}, gvk, nil
+}
+
+func newResourceChange(key objectKey, action nsxv1alpha1.ResourceAction) nsxv1alpha1.ResourceChange {
+	return nsxv1alpha1.ResourceChange{Kind: key.kind, Namespace: key.namespace, Name: key.name, Action: action}
+}
+
+// applyGeneratedResources server-side applies the generated resources, and prunes resources generated
+// by a previous migration of cr which are no longer generated. If apply is false the cluster is not changed.
+// It returns the changes done (or planned if apply is false); resources which are up to date are not included.
+func (r *NSXMigrationReconciler) applyGeneratedResources(ctx context.Context, cr *nsxv1alpha1.NSXMigration,
+	generated *resources.Generated, apply bool, log logr.Logger) ([]nsxv1alpha1.ResourceChange, error) {
+	changes := []nsxv1alpha1.ResourceChange{}
+	desired := map[objectKey]bool{}
+	for _, obj := range generatedObjects(generated) {
+		key, gvk, err := r.objectKey(obj)
+		if err != nil {
+			return nil, err
+		}
+		desired[key] = true
+		action, err := r.applyObject(ctx, cr, obj, gvk, apply, log)
+		if err != nil {
+			return nil, err
+		}
+		if action != "" {
+			changes = append(changes, newResourceChange(key, action))
+		}
+	}
+
+	pruned, err := r.pruneResources(ctx, cr, desired, apply, log)
+	if err != nil {
+		return nil, err
+	}
+	return append(changes, pruned...), nil
+}
+
+// applyObject applies a generated resource, and returns the action required for it (empty if it is up to date)
+func (r *NSXMigrationReconciler) applyObject(ctx context.Context, cr *nsxv1alpha1.NSXMigration, obj client.Object,
+	gvk schema.GroupVersionKind, apply bool, log logr.Logger) (nsxv1alpha1.ResourceAction, error) {
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	for k, v := range migrationLabels(cr) {
+		labels[k] = v
+	}
+	obj.SetLabels(labels)
+	// the resource may be shared with other NSXMigrations, thus none of them is its controller
+	if obj.GetNamespace() == cr.Namespace {
+		if err := controllerutil.SetOwnerReference(cr, obj, r.Scheme); err != nil {
+			return "", err
+		}
+	}
+
+	action, err := r.requiredAction(ctx, cr, obj, gvk)
+	if err != nil || action == "" || !apply {
+		return action, err
+	}
+
+	if err := r.Patch(ctx, obj, client.Apply, client.FieldOwner(fieldManager(cr)), client.ForceOwnership); err != nil {
+		log.Error(err, "Failed to apply generated resource", "Namespace", obj.GetNamespace(), "Name", obj.GetName())
+		return "", err
+	}
+	return action, nil
+}
+
+// requiredAction returns the action required to apply obj: create if it does not exist, update if applying it
+// would change the existing resource (checked by a server-side dry-run apply), and empty if it is up to date
+func (r *NSXMigrationReconciler) requiredAction(ctx context.Context, cr *nsxv1alpha1.NSXMigration, obj client.Object,
+	gvk schema.GroupVersionKind) (nsxv1alpha1.ResourceAction, error) {
+	existing, err := r.Scheme.New(gvk)
+	if err != nil {
+		return "", err
+	}
+	existingObj, ok := existing.(client.Object)
+	if !ok {
+		return "", fmt.Errorf("unexpected type %T of kind %s", existing, gvk.Kind)
+	}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(obj), existingObj); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nsxv1alpha1.ResourceActionCreate, nil
+		}
+		return "", err
+	}
+	applied, ok := obj.DeepCopyObject().(client.Object)
+	if !ok {
+		return "", fmt.Errorf("unexpected type %T of kind %s", obj, gvk.Kind)
+	}
+	if err := r.Patch(ctx, applied, client.Apply, client.FieldOwner(fieldManager(cr)), client.ForceOwnership,
+		client.DryRunAll); err != nil {
+		return "", err
+	}
+	// managed fields and type meta may differ even if the content of the resource does not change
+	for _, o := range []client.Object{existingObj, applied} {
+		o.SetManagedFields(nil)
+		o.GetObjectKind().SetGroupVersionKind(gvk)
+	}
+	if equality.Semantic.DeepEqual(existingObj, applied) {
+		return "", nil
+	}
+	return nsxv1alpha1.ResourceActionUpdate, nil
+}
+
+func (r *NSXMigrationReconciler) pruneResources(ctx context.Context, cr *nsxv1alpha1.NSXMigration, desired map[objectKey]bool,
+	apply bool, log logr.Logger) ([]nsxv1alpha1.ResourceChange, error) {
+	changes := []nsxv1alpha1.ResourceChange{}
+	for _, list := range prunableObjectLists() {
+		if err := r.List(ctx, list, client.MatchingLabels(migrationLabels(cr))); err != nil {
+			if meta.IsNoMatchError(err) {
+				// the resource kind is not installed on the cluster, thus there is nothing to prune
+				continue
+			}
+			return nil, err
+		}
+		objs, err := meta.ExtractList(list)
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range objs {
+			obj, ok := o.(client.Object)
+			if !ok {
+				continue
+			}
+			key, _, err := r.objectKey(obj)
+			if err != nil {
+				return nil, err
+			}
+			if desired[key] {
+				continue
+			}
+			// a resource still generated by other NSXMigrations is only released by cr
+			shared := ownedByOtherMigrations(cr, obj)
+			action := nsxv1alpha1.ResourceActionDelete
+			if shared {
+				action = nsxv1alpha1.ResourceActionRelease
+			}
+			changes = append(changes, newResourceChange(key, action))
+			if !apply {
+				continue
+			}
+			if shared {
+				err = r.releaseObject(ctx, cr, obj)
+			} else {
+				err = client.IgnoreNotFound(r.Delete(ctx, obj))
+			}
+			if err != nil {
+				log.Error(err, "Failed to prune resource", "Namespace", obj.GetNamespace(), "Name", obj.GetName())
+				return nil, err
+			}
+			log.Info("pruned resource no longer generated", "Kind", key.kind, "Namespace", key.namespace, "Name", key.name,
+				"Action", action)
+		}
+	}
+	return changes, nil
+}
@@ -0,0 +1,42 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	admin "sigs.k8s.io/network-policy-api/apis/v1alpha1"
+
+	"github.com/np-guard/vmware-analyzer/pkg/synthesis/ocpvirt/resources"
+)
+
+var _ = Describe("Generated resources", func() {
+	It("Should order namespaces first and default the namespace of network policies", func() {
+		generated := &resources.Generated{
+			NetworkPolicies:      []*networking.NetworkPolicy{{ObjectMeta: metav1.ObjectMeta{Name: "policy_0"}}},
+			AdminNetworkPolicies: []*admin.AdminNetworkPolicy{{ObjectMeta: metav1.ObjectMeta{Name: "admin-policy-0"}}},
+			Namespaces:           []*v1.Namespace{{ObjectMeta: metav1.ObjectMeta{Name: "frontend"}}},
+		}
+		objs := generatedObjects(generated)
+		Expect(objs).To(HaveLen(3))
+		Expect(objs[0].GetName()).To(Equal("frontend"))
+		Expect(objs[2].GetName()).To(Equal("policy_0"))
+		Expect(objs[2].GetNamespace()).To(Equal(v1.NamespaceDefault))
+	})
+})
@@ -0,0 +1,114 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	nsxv1alpha1 "github.com/np-guard/vmware-analyzer-operator/api/v1alpha1"
+)
+
+// createdObjectLists returns lists of the kinds of resources created by a migration, which are
+// deleted or orphaned upon deletion of the NSXMigration. Namespaces are never deleted, as in pruning.
+func createdObjectLists() []client.ObjectList {
+	return append(prunableObjectLists(), &v1.ConfigMapList{})
+}
+
+// orphanResources returns whether the resources created by the migration of cr are left in the cluster upon its deletion
+func orphanResources(cr *nsxv1alpha1.NSXMigration) bool {
+	return cr.Spec.DeletionPolicy == nsxv1alpha1.DeletionPolicyOrphan
+}
+
+// doFinalizerOperationsForMigrateNSX deletes the resources created by the migration of cr, or orphans them
+// according to its deletion policy. Orphaned resources are released from the owner reference to cr, so that
+// they are not garbage collected with it. Resources still generated by other NSXMigrations are released
+// rather than deleted. It returns the number of resources deleted or orphaned.
+func (r *NSXMigrationReconciler) doFinalizerOperationsForMigrateNSX(ctx context.Context, cr *nsxv1alpha1.NSXMigration,
+	log logr.Logger) (int, error) {
+	orphan := orphanResources(cr)
+	count := 0
+	for _, list := range createdObjectLists() {
+		if err := r.List(ctx, list, client.MatchingLabels(migrationLabels(cr))); err != nil {
+			if meta.IsNoMatchError(err) {
+				// the resource kind is not installed on the cluster, thus there is nothing to clean up
+				continue
+			}
+			return count, err
+		}
+		objs, err := meta.ExtractList(list)
+		if err != nil {
+			return count, err
+		}
+		for _, o := range objs {
+			obj, ok := o.(client.Object)
+			if !ok {
+				continue
+			}
+			switch {
+			case orphan:
+				err = r.orphanObject(ctx, cr, obj)
+			case ownedByOtherMigrations(cr, obj):
+				err = r.releaseObject(ctx, cr, obj)
+			default:
+				err = client.IgnoreNotFound(r.Delete(ctx, obj))
+			}
+			if err != nil {
+				log.Error(err, "Failed to clean up resource", "Namespace", obj.GetNamespace(), "Name", obj.GetName(), "orphan", orphan)
+				return count, err
+			}
+			count++
+		}
+	}
+	log.Info("cleaned up resources created by the migration", "count", count, "orphan", orphan)
+	return count, nil
+}
+
+func (r *NSXMigrationReconciler) orphanObject(ctx context.Context, cr *nsxv1alpha1.NSXMigration, obj client.Object) error {
+	if !hasOwnerReference(cr, obj) {
+		return nil
+	}
+	original, ok := obj.DeepCopyObject().(client.Object)
+	if !ok {
+		return fmt.Errorf("unexpected type %T of resource %s", obj, obj.GetName())
+	}
+	patch := client.MergeFrom(original)
+	if err := controllerutil.RemoveOwnerReference(cr, obj, r.Scheme); err != nil {
+		return err
+	}
+	return client.IgnoreNotFound(r.Patch(ctx, obj, patch))
+}
+
+// cleanupAction returns the action done upon deletion of cr to the resources created by its migration
+func cleanupAction(cr *nsxv1alpha1.NSXMigration) string {
+	if orphanResources(cr) {
+		return "orphaned"
+	}
+	return "deleted"
+}
+
+// finalizeMessage returns the message of the Degraded condition after finalizer operations completed
+func finalizeMessage(cr *nsxv1alpha1.NSXMigration, count int) string {
+	return fmt.Sprintf("Finalizer operations for custom resource %s were successfully accomplished, %d resources were %s",
+		cr.Name, count, cleanupAction(cr))
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	udnv1 "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/userdefinednetwork/v1"
+	v1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	admin "sigs.k8s.io/network-policy-api/apis/v1alpha1"
+
+	nsxv1alpha1 "github.com/np-guard/vmware-analyzer-operator/api/v1alpha1"
+)
+
+var _ = Describe("NSXMigration finalizer", func() {
+	var (
+		reconciler *NSXMigrationReconciler
+		cr         *nsxv1alpha1.NSXMigration
+		configMap  *v1.ConfigMap
+		policy     *networking.NetworkPolicy
+		unrelated  *networking.NetworkPolicy
+	)
+
+	BeforeEach(func() {
+		testScheme := newTestScheme()
+
+		cr = &nsxv1alpha1.NSXMigration{ObjectMeta: metav1.ObjectMeta{Name: "migration", Namespace: "nsx", UID: "migration-uid"}}
+		configMap = &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "topology-migration", Namespace: "nsx",
+			Labels: migrationLabels(cr)}}
+		Expect(controllerutil.SetControllerReference(cr, configMap, testScheme)).To(Succeed())
+		policy = &networking.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy-0", Namespace: "frontend",
+			Labels: migrationLabels(cr)}}
+		unrelated = &networking.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy-0", Namespace: "backend"}}
+
+		reconciler = &NSXMigrationReconciler{
+			Client: fake.NewClientBuilder().WithScheme(testScheme).WithObjects(cr, configMap, policy, unrelated).Build(),
+			Scheme: testScheme,
+		}
+	})
+
+	It("Should delete the resources created by the migration", func() {
+		count, err := reconciler.doFinalizerOperationsForMigrateNSX(context.Background(), cr, log.Log)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(Equal(2))
+		for _, obj := range []client.Object{configMap, policy} {
+			err := reconciler.Get(context.Background(), client.ObjectKeyFromObject(obj), obj)
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		}
+		Expect(reconciler.Get(context.Background(), client.ObjectKeyFromObject(unrelated), unrelated)).To(Succeed())
+	})
+
+	It("Should release the resources created by the migration when orphaning them", func() {
+		cr.Spec.DeletionPolicy = nsxv1alpha1.DeletionPolicyOrphan
+		count, err := reconciler.doFinalizerOperationsForMigrateNSX(context.Background(), cr, log.Log)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(Equal(2))
+		Expect(reconciler.Get(context.Background(), client.ObjectKeyFromObject(configMap), configMap)).To(Succeed())
+		Expect(configMap.GetOwnerReferences()).To(BeEmpty())
+		Expect(reconciler.Get(context.Background(), client.ObjectKeyFromObject(policy), policy)).To(Succeed())
+		Expect(finalizeMessage(cr, count)).To(ContainSubstring("2 resources were orphaned"))
+	})
+})
+
+// newTestScheme returns a scheme with the kinds of resources used by the migration, for tests with a fake client
+func newTestScheme() *runtime.Scheme {
+	testScheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(testScheme))
+	utilruntime.Must(admin.AddToScheme(testScheme))
+	utilruntime.Must(udnv1.AddToScheme(testScheme))
+	utilruntime.Must(nsxv1alpha1.AddToScheme(testScheme))
+	return testScheme
+}
@@ -0,0 +1,190 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	nsxv1alpha1 "github.com/np-guard/vmware-analyzer-operator/api/v1alpha1"
+	"github.com/np-guard/vmware-analyzer/pkg/collector"
+)
+
+// gzipMagic is the header of gzip compressed data
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// maxResourcesDumpSize is the max size of a decompressed NSX resources dump
+const maxResourcesDumpSize = 512 << 20
+
+var errResourcesDumpTooLarge = fmt.Errorf("decompressed NSX resources dump exceeds %d bytes", maxResourcesDumpSize)
+
+// ValidateResourcesDumpPath checks that path, of an NSX resources dump file, is an absolute path under root, the directory
+// the dumps are mounted on in the operator's pod, so that other files of the pod cannot be read.
+// Reading dumps from files is disabled if root is empty.
+func ValidateResourcesDumpPath(root, path string) error {
+	if root == "" {
+		return errors.New("reading NSX resources dumps from files is disabled, since the operator has no resources dump root")
+	}
+	if !filepath.IsAbs(path) {
+		return errors.New("path should be absolute")
+	}
+	if slices.Contains(strings.Split(filepath.ToSlash(path), "/"), "..") {
+		return errors.New("path should not contain \"..\"")
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("path should be under the resources dump root %s", root)
+	}
+	return nil
+}
+
+// getNSXResourcesDump reads the NSX resources dump referenced by the spec of cr, and parses it
+func (r *NSXMigrationReconciler) getNSXResourcesDump(cr *nsxv1alpha1.NSXMigration, ctx context.Context,
+	log logr.Logger) (*collector.ResourcesContainerModel, error) {
+	data, err := r.readResourcesDump(ctx, cr, cr.Spec.ResourcesDump)
+	if err != nil {
+		log.Error(err, "Failed to read NSX resources dump")
+		return nil, err
+	}
+	data, err = decompressResourcesDump(data)
+	if err != nil {
+		return nil, err
+	}
+	rc, err := collector.FromJSONString(data)
+	if err != nil {
+		log.Error(err, "Failed to parse NSX resources dump")
+		return nil, err
+	}
+	log.Info("read NSX resources dump", "size", len(data), "numVMs", len(rc.VirtualMachineList))
+	return rc, nil
+}
+
+func (r *NSXMigrationReconciler) readResourcesDump(ctx context.Context, cr *nsxv1alpha1.NSXMigration,
+	src *nsxv1alpha1.ResourcesDumpSource) ([]byte, error) {
+	switch {
+	case src.ConfigMap != nil:
+		return r.readConfigMapKey(ctx, cr.Namespace, src.ConfigMap)
+	case src.Secret != nil:
+		secret := &v1.Secret{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: cr.Namespace, Name: src.Secret.Name}, secret); err != nil {
+			return nil, err
+		}
+		if data, ok := secret.Data[src.Secret.Key]; ok {
+			return data, nil
+		}
+		return nil, fmt.Errorf("key %s not found in Secret %s", src.Secret.Key, src.Secret.Name)
+	case src.Path != "":
+		return r.readValidateResourcesDumpPath(src.Path)
+	}
+	return nil, fmt.Errorf("no source of the NSX resources dump is set for the custom resource %s", cr.Name)
+}
+
+// readResourcesDumpFile reads an NSX resources dump file under the resources dump root, also after resolving symlinks
+func (r *NSXMigrationReconciler) readValidateResourcesDumpPath(path string) ([]byte, error) {
+	if err := ValidateResourcesDumpPath(r.ResourcesDumpRoot, path); err != nil {
+		return nil, err
+	}
+	root, err := filepath.EvalSymlinks(r.ResourcesDumpRoot)
+	if err != nil {
+		return nil, err
+	}
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateResourcesDumpPath(root, resolved); err != nil {
+		return nil, err
+	}
+	return os.ReadFile(resolved)
+}
+
+// readConfigMapKey returns the data of the key selected in a ConfigMap of the given namespace,
+// either binary data or string data
+func (r *NSXMigrationReconciler) readConfigMapKey(ctx context.Context, namespace string,
+	selector *v1.ConfigMapKeySelector) ([]byte, error) {
+	cm := &v1.ConfigMap{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: selector.Name}, cm); err != nil {
+		return nil, err
+	}
+	if data, ok := cm.BinaryData[selector.Key]; ok {
+		return data, nil
+	}
+	if data, ok := cm.Data[selector.Key]; ok {
+		return []byte(data), nil
+	}
+	return nil, fmt.Errorf("key %s not found in ConfigMap %s", selector.Key, selector.Name)
+}
+
+// getConnectionOptions sets the options of the connection to the NSX manager of conn by the spec of cr,
+// reading the client certificate and the CA bundle they reference
+func (r *NSXMigrationReconciler) getConnectionOptions(ctx context.Context, cr *nsxv1alpha1.NSXMigration, conn *nsxConn) error {
+	opts := cr.Spec.Connection
+	conn.sessionAuth = opts.SessionAuth
+	if opts.RequestTimeout != nil {
+		conn.requestTimeout = opts.RequestTimeout.Duration
+	}
+	if opts.ClientCertificate != nil {
+		secret := &v1.Secret{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: cr.Namespace, Name: opts.ClientCertificate.Name}, secret); err != nil {
+			return err
+		}
+		conn.clientCert, conn.clientKey = secret.Data[v1.TLSCertKey], secret.Data[v1.TLSPrivateKeyKey]
+		if len(conn.clientCert) == 0 || len(conn.clientKey) == 0 {
+			return fmt.Errorf("keys %s and %s not found in Secret %s", v1.TLSCertKey, v1.TLSPrivateKeyKey, opts.ClientCertificate.Name)
+		}
+	}
+	if opts.CABundle != nil {
+		ca, err := r.readConfigMapKey(ctx, cr.Namespace, opts.CABundle)
+		if err != nil {
+			return err
+		}
+		conn.caBundle = ca
+	}
+	return nil
+}
+
+// decompressResourcesDump returns the dump decompressed if it is gzip compressed, and as is otherwise
+func decompressResourcesDump(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, gzipMagic) {
+		return data, nil
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	data, err = io.ReadAll(io.LimitReader(reader, maxResourcesDumpSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxResourcesDumpSize {
+		return nil, errResourcesDumpTooLarge
+	}
+	return data, nil
+}
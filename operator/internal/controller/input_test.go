@@ -0,0 +1,146 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	nsxv1alpha1 "github.com/np-guard/vmware-analyzer-operator/api/v1alpha1"
+)
+
+const resourcesDumpJSON = `{"virtual_machines": [{"display_name": "vm1", "external_id": "vm1-id"}]}`
+
+var _ = Describe("NSX resources dump", func() {
+	var (
+		reconciler *NSXMigrationReconciler
+		cr         *nsxv1alpha1.NSXMigration
+	)
+
+	BeforeEach(func() {
+		var compressed bytes.Buffer
+		w := gzip.NewWriter(&compressed)
+		_, err := w.Write([]byte(resourcesDumpJSON))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(w.Close()).To(Succeed())
+
+		cr = &nsxv1alpha1.NSXMigration{ObjectMeta: metav1.ObjectMeta{Name: "migration", Namespace: "nsx"}}
+		configMap := &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "nsx-dump", Namespace: "nsx"},
+			Data:       map[string]string{"resources.json": resourcesDumpJSON},
+			BinaryData: map[string][]byte{"resources.json.gz": compressed.Bytes()}}
+		secret := &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "nsx-dump", Namespace: "nsx"},
+			Data: map[string][]byte{"resources.json.gz": compressed.Bytes()}}
+		testScheme := newTestScheme()
+		reconciler = &NSXMigrationReconciler{
+			Client: fake.NewClientBuilder().WithScheme(testScheme).WithObjects(configMap, secret).Build(),
+			Scheme: testScheme,
+		}
+	})
+
+	DescribeTable("Should read the NSX resources from the dump",
+		func(src *nsxv1alpha1.ResourcesDumpSource) {
+			cr.Spec.ResourcesDump = src
+			rc, err := reconciler.getNSXResourcesDump(cr, context.Background(), log.Log)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rc.VirtualMachineList).To(HaveLen(1))
+			Expect(*rc.VirtualMachineList[0].DisplayName).To(Equal("vm1"))
+		},
+		Entry("from a ConfigMap", &nsxv1alpha1.ResourcesDumpSource{ConfigMap: &v1.ConfigMapKeySelector{
+			LocalObjectReference: v1.LocalObjectReference{Name: "nsx-dump"}, Key: "resources.json"}}),
+		Entry("from a compressed ConfigMap", &nsxv1alpha1.ResourcesDumpSource{ConfigMap: &v1.ConfigMapKeySelector{
+			LocalObjectReference: v1.LocalObjectReference{Name: "nsx-dump"}, Key: "resources.json.gz"}}),
+		Entry("from a compressed Secret", &nsxv1alpha1.ResourcesDumpSource{Secret: &v1.SecretKeySelector{
+			LocalObjectReference: v1.LocalObjectReference{Name: "nsx-dump"}, Key: "resources.json.gz"}}),
+	)
+
+	It("Should read a dump file only under the resources dump root", func() {
+		root := GinkgoT().TempDir()
+		Expect(os.WriteFile(filepath.Join(root, "resources.json"), []byte(resourcesDumpJSON), 0o600)).To(Succeed())
+		outside := filepath.Join(GinkgoT().TempDir(), "secret")
+		Expect(os.WriteFile(outside, []byte("secret"), 0o600)).To(Succeed())
+		Expect(os.Symlink(outside, filepath.Join(root, "link"))).To(Succeed())
+		reconciler.ResourcesDumpRoot = root
+
+		cr.Spec.ResourcesDump = &nsxv1alpha1.ResourcesDumpSource{Path: filepath.Join(root, "resources.json")}
+		rc, err := reconciler.getNSXResourcesDump(cr, context.Background(), log.Log)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rc.VirtualMachineList).To(HaveLen(1))
+
+		for _, path := range []string{outside, filepath.Join(root, "link")} {
+			cr.Spec.ResourcesDump.Path = path
+			_, err = reconciler.getNSXResourcesDump(cr, context.Background(), log.Log)
+			Expect(err).To(MatchError(ContainSubstring("path should be under the resources dump root")))
+		}
+	})
+
+	It("Should fail to decompress a dump exceeding the max size", func() {
+		var compressed bytes.Buffer
+		w := gzip.NewWriter(&compressed)
+		_, err := w.Write(make([]byte, maxResourcesDumpSize+1))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(w.Close()).To(Succeed())
+		_, err = decompressResourcesDump(compressed.Bytes())
+		Expect(err).To(MatchError(errResourcesDumpTooLarge))
+	})
+
+	It("Should fail if the key is missing in the ConfigMap", func() {
+		cr.Spec.ResourcesDump = &nsxv1alpha1.ResourcesDumpSource{ConfigMap: &v1.ConfigMapKeySelector{
+			LocalObjectReference: v1.LocalObjectReference{Name: "nsx-dump"}, Key: "missing"}}
+		_, err := reconciler.getNSXResourcesDump(cr, context.Background(), log.Log)
+		Expect(err).To(MatchError(ContainSubstring("key missing not found in ConfigMap nsx-dump")))
+	})
+})
+
+var _ = Describe("NSX connection options", func() {
+	It("Should read the client certificate and the CA bundle referenced by the connection options", func() {
+		cr := &nsxv1alpha1.NSXMigration{ObjectMeta: metav1.ObjectMeta{Name: "migration", Namespace: "nsx"},
+			Spec: nsxv1alpha1.NSXMigrationSpec{Connection: nsxv1alpha1.ConnectionOptions{SessionAuth: true,
+				ClientCertificate: &v1.LocalObjectReference{Name: "nsx-client-cert"},
+				CABundle:          &v1.ConfigMapKeySelector{LocalObjectReference: v1.LocalObjectReference{Name: "nsx-ca"}, Key: "ca.crt"},
+				RequestTimeout:    &metav1.Duration{Duration: time.Minute}}}}
+		secret := &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "nsx-client-cert", Namespace: "nsx"}, Type: v1.SecretTypeTLS,
+			Data: map[string][]byte{v1.TLSCertKey: []byte("cert"), v1.TLSPrivateKeyKey: []byte("key")}}
+		configMap := &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "nsx-ca", Namespace: "nsx"},
+			Data: map[string]string{"ca.crt": "ca"}}
+		testScheme := newTestScheme()
+		reconciler := &NSXMigrationReconciler{
+			Client: fake.NewClientBuilder().WithScheme(testScheme).WithObjects(secret, configMap).Build(),
+			Scheme: testScheme,
+		}
+
+		conn := &nsxConn{}
+		Expect(reconciler.getConnectionOptions(context.Background(), cr, conn)).To(Succeed())
+		Expect(*conn).To(Equal(nsxConn{sessionAuth: true, clientCert: []byte("cert"), clientKey: []byte("key"),
+			caBundle: []byte("ca"), requestTimeout: time.Minute}))
+
+		secret.Data = map[string][]byte{v1.TLSCertKey: []byte("cert")}
+		Expect(reconciler.Update(context.Background(), secret)).To(Succeed())
+		Expect(reconciler.getConnectionOptions(context.Background(), cr, &nsxConn{})).To(
+			MatchError(ContainSubstring("keys tls.crt and tls.key not found in Secret nsx-client-cert")))
+	})
+})
@@ -0,0 +1,148 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	nsxv1alpha1 "github.com/np-guard/vmware-analyzer-operator/api/v1alpha1"
+	"github.com/np-guard/vmware-analyzer/pkg/collector"
+	"github.com/np-guard/vmware-analyzer/pkg/runner"
+)
+
+const metricsNamespace = "nsxmigration"
+
+// phaseApply is the phase of applying the generated resources, run by the operator after the runner's phases
+const phaseApply = "apply"
+
+// kinds of the collected resources and generated policies, used as the values of the kind label
+const (
+	kindVMs                  = "vms"
+	kindSegments             = "segments"
+	kindGroups               = "groups"
+	kindSecurityPolicies     = "security_policies"
+	kindRules                = "rules"
+	kindServices             = "services"
+	kindNetworkPolicies      = "network_policies"
+	kindAdminNetworkPolicies = "admin_network_policies"
+)
+
+var (
+	reconcilePhaseDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "reconcile_phase_duration_seconds",
+		Help:      "Duration of the phases of a migration run: collection, analysis, synthesis and apply",
+		Buckets:   prometheus.ExponentialBuckets(0.1, 2, 12),
+	}, []string{"phase"})
+
+	nsxAPIRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "nsx_api_requests_total",
+		Help:      "Number of REST API requests to the NSX manager, by method and status code (0 if no response was received)",
+	}, []string{"method", "code"})
+
+	nsxAPIRequestErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "nsx_api_request_errors_total",
+		Help:      "Number of failed REST API requests to the NSX manager, with no response or an error status code",
+	}, []string{"method"})
+
+	nsxAPIRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "nsx_api_request_duration_seconds",
+		Help:      "Latency of REST API requests to the NSX manager",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+
+	collectedResources = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "collected_resources",
+		Help:      "Number of NSX resources collected by the last run of a migration, by kind",
+	}, []string{"namespace", "name", "kind"})
+
+	generatedPolicies = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "generated_policies",
+		Help:      "Number of policies generated by the last run of a migration, by kind",
+	}, []string{"namespace", "name", "kind"})
+
+	driftDetected = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "drift_detected_total",
+		Help:      "Number of periodic resyncs of a migration which detected drift",
+	}, []string{"namespace", "name"})
+
+	driftChanges = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "drift_changes",
+		Help:      "Number of changes to the generated resources detected by the last periodic resync of a migration",
+	}, []string{"namespace", "name"})
+)
+
+func init() {
+	// Register custom metrics with the global prometheus registry
+	metrics.Registry.MustRegister(reconcilePhaseDuration, nsxAPIRequests, nsxAPIRequestErrors, nsxAPIRequestDuration,
+		collectedResources, generatedPolicies, driftDetected, driftChanges)
+	collector.SetRequestObserver(observeNSXRequest)
+}
+
+func observeNSXRequest(method string, statusCode int, duration time.Duration, err error) {
+	nsxAPIRequests.WithLabelValues(method, strconv.Itoa(statusCode)).Inc()
+	nsxAPIRequestDuration.WithLabelValues(method).Observe(duration.Seconds())
+	if err != nil || statusCode == 0 || statusCode >= http.StatusBadRequest {
+		nsxAPIRequestErrors.WithLabelValues(method).Inc()
+	}
+}
+
+// recordRunMetrics records the metrics of the runner's phases in a migration run of cr (skipped phases have no duration)
+func recordRunMetrics(cr *nsxv1alpha1.NSXMigration, summary *runner.Summary) {
+	for phase, duration := range summary.Durations {
+		reconcilePhaseDuration.WithLabelValues(string(phase)).Observe(duration.Seconds())
+	}
+	if c := summary.Collection; c != nil {
+		for kind, count := range map[string]int{kindVMs: c.VMs, kindSegments: c.Segments, kindGroups: c.Groups,
+			kindSecurityPolicies: c.SecurityPolicies, kindRules: c.Rules, kindServices: c.Services} {
+			collectedResources.WithLabelValues(cr.Namespace, cr.Name, kind).Set(float64(count))
+		}
+	}
+	if s := summary.Synthesis; s != nil {
+		generatedPolicies.WithLabelValues(cr.Namespace, cr.Name, kindNetworkPolicies).Set(float64(s.NetworkPolicies))
+		generatedPolicies.WithLabelValues(cr.Namespace, cr.Name, kindAdminNetworkPolicies).Set(float64(s.AdminNetworkPolicies))
+	}
+}
+
+// recordDriftMetrics records the drift detected by a periodic resync of cr
+func recordDriftMetrics(cr *nsxv1alpha1.NSXMigration, drift int) {
+	driftChanges.WithLabelValues(cr.Namespace, cr.Name).Set(float64(drift))
+	if drift > 0 {
+		driftDetected.WithLabelValues(cr.Namespace, cr.Name).Inc()
+	}
+}
+
+// deleteMigrationMetrics deletes the metrics of cr upon its deletion
+func deleteMigrationMetrics(cr *nsxv1alpha1.NSXMigration) {
+	labels := prometheus.Labels{"namespace": cr.Namespace, "name": cr.Name}
+	collectedResources.DeletePartialMatch(labels)
+	generatedPolicies.DeletePartialMatch(labels)
+	driftDetected.DeletePartialMatch(labels)
+	driftChanges.DeletePartialMatch(labels)
+}
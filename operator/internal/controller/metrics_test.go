@@ -0,0 +1,72 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	nsxv1alpha1 "github.com/np-guard/vmware-analyzer-operator/api/v1alpha1"
+	"github.com/np-guard/vmware-analyzer/pkg/runner"
+)
+
+var _ = Describe("NSXMigration metrics", func() {
+	cr := &nsxv1alpha1.NSXMigration{ObjectMeta: metav1.ObjectMeta{Name: "metrics-migration", Namespace: "nsx"}}
+
+	It("Should record the metrics of a migration run", func() {
+		summary := &runner.Summary{
+			Collection: &runner.CollectionSummary{VMs: 5, Rules: 7},
+			Synthesis:  &runner.SynthesisSummary{NetworkPolicies: 3, AdminNetworkPolicies: 1},
+			Durations:  map[runner.Phase]time.Duration{runner.PhaseSynthesis: time.Second},
+		}
+		recordRunMetrics(cr, summary)
+		Expect(testutil.CollectAndCount(reconcilePhaseDuration, metricsNamespace+"_reconcile_phase_duration_seconds")).To(Equal(1))
+		Expect(testutil.ToFloat64(collectedResources.WithLabelValues(cr.Namespace, cr.Name, kindVMs))).To(Equal(5.0))
+		Expect(testutil.ToFloat64(collectedResources.WithLabelValues(cr.Namespace, cr.Name, kindRules))).To(Equal(7.0))
+		Expect(testutil.ToFloat64(generatedPolicies.WithLabelValues(cr.Namespace, cr.Name, kindNetworkPolicies))).To(Equal(3.0))
+		Expect(testutil.ToFloat64(generatedPolicies.WithLabelValues(cr.Namespace, cr.Name, kindAdminNetworkPolicies))).To(Equal(1.0))
+	})
+
+	It("Should record the drift detected by a resync", func() {
+		recordDriftMetrics(cr, 0)
+		recordDriftMetrics(cr, 4)
+		Expect(testutil.ToFloat64(driftChanges.WithLabelValues(cr.Namespace, cr.Name))).To(Equal(4.0))
+		Expect(testutil.ToFloat64(driftDetected.WithLabelValues(cr.Namespace, cr.Name))).To(Equal(1.0))
+	})
+
+	It("Should count the NSX API requests and errors", func() {
+		errorsBefore := testutil.ToFloat64(nsxAPIRequestErrors.WithLabelValues(http.MethodGet))
+		observeNSXRequest(http.MethodGet, http.StatusOK, time.Millisecond, nil)
+		observeNSXRequest(http.MethodGet, http.StatusTooManyRequests, time.Millisecond, nil)
+		observeNSXRequest(http.MethodGet, 0, time.Millisecond, errors.New("connection refused"))
+		Expect(testutil.ToFloat64(nsxAPIRequests.WithLabelValues(http.MethodGet, "429"))).To(BeNumerically(">=", 1))
+		Expect(testutil.ToFloat64(nsxAPIRequestErrors.WithLabelValues(http.MethodGet)) - errorsBefore).To(Equal(2.0))
+	})
+
+	It("Should delete the metrics of a deleted migration", func() {
+		recordDriftMetrics(cr, 1)
+		deleteMigrationMetrics(cr)
+		Expect(testutil.CollectAndCount(driftChanges)).To(BeZero())
+		Expect(testutil.CollectAndCount(collectedResources)).To(BeZero())
+	})
+})
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -31,6 +32,7 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	"github.com/go-logr/logr"
@@ -39,14 +41,16 @@ import (
 	"github.com/np-guard/vmware-analyzer/pkg/runner"
 )
 
-//const migratensxFinalizer = "nsx.npguard.io/finalizer"
+const migratensxFinalizer = "nsx.npguard.io/finalizer"
 
 // Definitions to manage status conditions
 const (
 	// typeAvailableNSXMigration represents the status of the Deployment reconciliation
 	typeAvailableNSXMigration = "Available"
 	// typeDegradedNSXMigration represents the status used when the custom resource is deleted and the finalizer operations are yet to occur.
-	//typeDegradedNSXMigration = "Degraded"
+	typeDegradedNSXMigration = "Degraded"
+	// typeDriftedNSXMigration represents the drift between the applied resources and the resources generated by a periodic resync
+	typeDriftedNSXMigration = "Drifted"
 )
 
 // NSXMigrationReconciler reconciles a NSXMigration object
@@ -55,6 +59,8 @@ type NSXMigrationReconciler struct {
 	Scheme *runtime.Scheme
 	// The recorder will be used within the reconcile method of the controller to emit events
 	Recorder record.EventRecorder
+	// ResourcesDumpRoot is the directory NSX resources dump files are mounted on; dump files are not read if it is empty
+	ResourcesDumpRoot string
 }
 
 // +kubebuilder:rbac:groups=nsx.npguard.io,resources=nsxmigrations,verbs=get;list;watch;create;update;patch;delete
@@ -64,6 +70,9 @@ type NSXMigrationReconciler struct {
 // +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
 // +kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups=policy.networking.k8s.io,resources=adminnetworkpolicies,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=k8s.ovn.org,resources=userdefinednetworks,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -118,7 +127,8 @@ func (r *NSXMigrationReconciler) Reconcile(ctx context.Context, req ctrl.Request
 	// Let's add a finalizer. Then, we can define some operations which should
 	// occur before the custom resource is deleted.
 	// More info: https://kubernetes.io/docs/concepts/overview/working-with-objects/finalizers
-	/*if !controllerutil.ContainsFinalizer(migratensx, migratensxFinalizer) {
+	// A finalizer cannot be added once the custom resource is marked to be deleted.
+	if migratensx.GetDeletionTimestamp() == nil && !controllerutil.ContainsFinalizer(migratensx, migratensxFinalizer) {
 		log.Info("Adding Finalizer for NSXMigration")
 		if ok := controllerutil.AddFinalizer(migratensx, migratensxFinalizer); !ok {
 			log.Error(err, "Failed to add finalizer into the custom resource")
@@ -129,84 +139,42 @@ func (r *NSXMigrationReconciler) Reconcile(ctx context.Context, req ctrl.Request
 			log.Error(err, "Failed to update custom resource to add finalizer")
 			return ctrl.Result{}, err
 		}
-	}*/
+	}
 
 	// Check if the MigrateNSX instance is marked to be deleted, which is
 	// indicated by the deletion timestamp being set.
 	isMigrateNSXMarkedToBeDeleted := migratensx.GetDeletionTimestamp() != nil
 	if isMigrateNSXMarkedToBeDeleted {
 		log.Info("the MigrateNSX instance is marked to be deleted")
-		/*if controllerutil.ContainsFinalizer(migratensx, migratensxFinalizer) {
-			log.Info("Performing Finalizer Operations for MigrateNSX before delete CR")
-
-			// Let's add here a status "Downgrade" to reflect that this resource began its process to be terminated.
-			meta.SetStatusCondition(&migratensx.Status.Conditions, metav1.Condition{Type: typeDegradedNSXMigration,
-				Status: metav1.ConditionUnknown, Reason: "Finalizing",
-				Message: fmt.Sprintf("Performing finalizer operations for the custom resource: %s ", migratensx.Name)})
-
-			if err := r.Status().Update(ctx, migratensx); err != nil {
-				log.Error(err, "Failed to update Memcached status")
-				return ctrl.Result{}, err
-			}
-
-			// Perform all operations required before removing the finalizer and allow
-			// the Kubernetes API to remove the custom resource.
-			r.doFinalizerOperationsForMigrateNSX(migratensx)
-
-			// TODO(user): If you add operations to the doFinalizerOperationsForMigrateNSX method
-			// then you need to ensure that all worked fine before deleting and updating the Downgrade status
-			// otherwise, you should requeue here.
-
-			// Re-fetch the migratensx Custom Resource before updating the status
-			// so that we have the latest state of the resource on the cluster and we will avoid
-			// raising the error "the object has been modified, please apply
-			// your changes to the latest version and try again" which would re-trigger the reconciliation
-			if err := r.Get(ctx, req.NamespacedName, migratensx); err != nil {
-				log.Error(err, "Failed to re-fetch migratensx")
-				return ctrl.Result{}, err
-			}
-
-			meta.SetStatusCondition(&migratensx.Status.Conditions, metav1.Condition{Type: typeDegradedNSXMigration,
-				Status: metav1.ConditionTrue, Reason: "Finalizing",
-				Message: fmt.Sprintf("Finalizer operations for custom resource %s name were successfully accomplished", migratensx.Name)})
-
-			if err := r.Status().Update(ctx, migratensx); err != nil {
-				log.Error(err, "Failed to update Memcached status")
-				return ctrl.Result{}, err
-			}
-
-			log.Info("Removing Finalizer for MigrateNSX after successfully perform the operations")
-			if ok := controllerutil.RemoveFinalizer(migratensx, migratensxFinalizer); !ok {
-				log.Error(err, "Failed to remove finalizer for Memcached")
-				return ctrl.Result{Requeue: true}, nil
-			}
-
-			if err := r.Update(ctx, migratensx); err != nil {
-				log.Error(err, "Failed to remove finalizer for Memcached")
-				return ctrl.Result{}, err
-			}
-
-		}*/
+		if controllerutil.ContainsFinalizer(migratensx, migratensxFinalizer) {
+			return r.finalizeMigrateNSX(ctx, req, migratensx, log)
+		}
 		// stop the Reconcile
 		return ctrl.Result{}, nil
 	}
 
-	if meta.IsStatusConditionTrue(migratensx.Status.Conditions, typeAvailableNSXMigration) {
-		// migration completed successfully - no need to re-run...
+	if cond := meta.FindStatusCondition(migratensx.Status.Conditions, typeAvailableNSXMigration); cond != nil &&
+		cond.Status == metav1.ConditionTrue && cond.ObservedGeneration == migratensx.Generation {
+		// migration of the current spec completed successfully - no need to re-run, unless periodic resync is set
 		// (currently reconcile is triggered  by status update after success )
-		log.Info("exit Reconcile without error - no need to call nsxMigration() because status Available true is already set")
-		// stop the Reconcile
-		return ctrl.Result{}, nil
+		if migratensx.Spec.ResyncInterval == nil {
+			log.Info("exit Reconcile without error - no need to call nsxMigration() because status Available true is already set")
+			// stop the Reconcile
+			return ctrl.Result{}, nil
+		}
+		if wait := timeToResync(migratensx); wait > 0 {
+			return ctrl.Result{RequeueAfter: wait}, nil
+		}
+		return r.resync(ctx, migratensx, log)
 	}
 
 	// MigrateNSX instance should trigger nsxMigration() [on create/update action]
-	// TODO: add migration logic here ...
-	if err := r.nsxMigration(migratensx, ctx, log); err != nil {
+	if err := r.nsxMigration(migratensx, !migratensx.Spec.DryRun, false, ctx, log); err != nil {
 		log.Error(err, "Failed to run nsxMigration")
 
 		// The following implementation will update the status
 		meta.SetStatusCondition(&migratensx.Status.Conditions, metav1.Condition{Type: typeAvailableNSXMigration,
-			Status: metav1.ConditionFalse, Reason: "Reconciling",
+			Status: metav1.ConditionFalse, Reason: "Reconciling", ObservedGeneration: migratensx.Generation,
 			Message: fmt.Sprintf("Failed to run nsxMigration for the custom resource (%s): (%s)", migratensx.Name, err)})
 
 		if err := r.Status().Update(ctx, migratensx); err != nil {
@@ -218,9 +186,16 @@ func (r *NSXMigrationReconciler) Reconcile(ctx context.Context, req ctrl.Request
 	}
 
 	// The following implementation will update the status
+	message := fmt.Sprintf("migrate nsx for migration spec at %s completed successfully", migratensx.Name)
+	if migratensx.Spec.DryRun {
+		message = fmt.Sprintf("dry-run of migrate nsx for migration spec at %s completed successfully with %d planned changes",
+			migratensx.Name, len(migratensx.Status.ResourceChanges))
+	}
 	meta.SetStatusCondition(&migratensx.Status.Conditions, metav1.Condition{Type: typeAvailableNSXMigration,
-		Status: metav1.ConditionTrue, Reason: "Reconciling",
-		Message: fmt.Sprintf("migrate nsx for migration spec at %s completed successfully", migratensx.Name)})
+		Status: metav1.ConditionTrue, Reason: "Reconciling", ObservedGeneration: migratensx.Generation,
+		Message: message})
+	// a drift reported for a previous spec is no longer relevant
+	meta.RemoveStatusCondition(&migratensx.Status.Conditions, typeDriftedNSXMigration)
 
 	if err := r.Status().Update(ctx, migratensx); err != nil {
 		log.Error(err, "Failed to update migratensx status")
@@ -228,8 +203,66 @@ func (r *NSXMigrationReconciler) Reconcile(ctx context.Context, req ctrl.Request
 	}
 
 	log.Info("finished the Reconcile without error")
-	// stop the Reconcile
-	return ctrl.Result{}, nil
+	// stop the Reconcile, or requeue for the next resync
+	return ctrl.Result{RequeueAfter: resyncInterval(migratensx)}, nil
+}
+
+func resyncInterval(cr *nsxv1alpha1.NSXMigration) time.Duration {
+	if cr.Spec.ResyncInterval == nil {
+		return 0
+	}
+	return cr.Spec.ResyncInterval.Duration
+}
+
+// timeToResync returns the remaining time until the next resync of cr (non-positive if it is due)
+func timeToResync(cr *nsxv1alpha1.NSXMigration) time.Duration {
+	if cr.Status.LastSyncTime == nil {
+		return 0
+	}
+	return time.Until(cr.Status.LastSyncTime.Add(resyncInterval(cr)))
+}
+
+// resync re-collects the NSX config and re-synthesizes the resources, and reports the drift between the resources
+// in the cluster and the newly generated ones. The drift is applied if autoApplyDrift is set (and not in dry-run mode).
+func (r *NSXMigrationReconciler) resync(ctx context.Context, cr *nsxv1alpha1.NSXMigration, log logr.Logger) (ctrl.Result, error) {
+	log.Info("periodic resync of migratensx", "resyncInterval", resyncInterval(cr).String())
+	autoApply := cr.Spec.AutoApplyDrift && !cr.Spec.DryRun
+	if err := r.nsxMigration(cr, autoApply, true, ctx, log); err != nil {
+		log.Error(err, "Failed to resync nsxMigration")
+		r.Recorder.Event(cr, "Warning", "ResyncFailed", fmt.Sprintf("Failed to resync the custom resource %s: %s", cr.Name, err))
+		meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{Type: typeDriftedNSXMigration,
+			Status: metav1.ConditionUnknown, Reason: "ResyncFailed", ObservedGeneration: cr.Generation,
+			Message: fmt.Sprintf("Failed to resync the custom resource (%s): (%s)", cr.Name, err)})
+		if err := r.Status().Update(ctx, cr); err != nil {
+			log.Error(err, "Failed to update migratensx status")
+		}
+		return ctrl.Result{}, err
+	}
+
+	drift := len(cr.Status.ResourceChanges)
+	recordDriftMetrics(cr, drift)
+	condition := metav1.Condition{Type: typeDriftedNSXMigration, ObservedGeneration: cr.Generation}
+	switch {
+	case drift == 0:
+		condition.Status, condition.Reason = metav1.ConditionFalse, "NoDrift"
+		condition.Message = "the generated resources are up to date with the NSX config"
+	case autoApply:
+		condition.Status, condition.Reason = metav1.ConditionFalse, "DriftApplied"
+		condition.Message = fmt.Sprintf("%d changes to generated resources were applied following NSX config changes", drift)
+		r.Recorder.Event(cr, "Normal", condition.Reason, condition.Message)
+	default:
+		condition.Status, condition.Reason = metav1.ConditionTrue, "DriftDetected"
+		condition.Message = fmt.Sprintf("%d changes to generated resources are required following NSX config changes", drift)
+		r.Recorder.Event(cr, "Warning", condition.Reason, condition.Message)
+	}
+	meta.SetStatusCondition(&cr.Status.Conditions, condition)
+
+	if err := r.Status().Update(ctx, cr); err != nil {
+		log.Error(err, "Failed to update migratensx status")
+		return ctrl.Result{}, err
+	}
+	log.Info("finished the resync without error", "drift", drift)
+	return ctrl.Result{RequeueAfter: resyncInterval(cr)}, nil
 }
 
 type nsxConn struct {
@@ -237,6 +270,23 @@ type nsxConn struct {
 	password           string
 	url                string
 	insecureSkipVerify bool
+	sessionAuth        bool
+	clientCert         []byte
+	clientKey          []byte
+	caBundle           []byte
+	requestTimeout     time.Duration
+}
+
+// clientOptions returns the options of the client of the NSX manager, by the connection options of the spec
+func (n *nsxConn) clientOptions() []collector.HTTPClientOption {
+	opts := []collector.HTTPClientOption{collector.WithSessionAuth(n.sessionAuth), collector.WithRequestTimeout(n.requestTimeout)}
+	if n.clientCert != nil {
+		opts = append(opts, collector.WithClientCertificate(n.clientCert, n.clientKey))
+	}
+	if n.caBundle != nil {
+		opts = append(opts, collector.WithCACertificates(n.caBundle))
+	}
+	return opts
 }
 
 func (n *nsxConn) getUser(s *v1.Secret) {
@@ -271,29 +321,6 @@ func (n *nsxConn) getInsecureSkipVerify(s *v1.Secret) {
 	n.insecureSkipVerify = insecureSkipVerify
 }
 
-func (r *NSXMigrationReconciler) genConfigMap(data map[string]string, name string, ctx context.Context, log logr.Logger) error {
-	// test create configmap
-	cm := &v1.ConfigMap{
-		TypeMeta: metav1.TypeMeta{
-			Kind:       "ConfigMap",
-			APIVersion: "v1",
-		},
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      name,
-			Namespace: "default",
-		},
-		Data: data,
-	}
-	if err := r.Create(ctx, cm); err != nil {
-		log.Error(err, "Failed to create ConfigMap",
-			"ConfigMap.Namespace", cm.Namespace, "ConfigMap.Name", cm.Name)
-		return err
-	}
-
-	log.Info("generated configmap", "ConfigMap.Namespace", cm.Namespace, "ConfigMap.Name", cm.Name)
-	return nil
-}
-
 func (r *NSXMigrationReconciler) getNSXCredentials(cr *nsxv1alpha1.NSXMigration, ctx context.Context, log logr.Logger) (conn *nsxConn, err error) {
 	// TODO: initial step: connect to NSX host from spec, validate connection is OK, print to log the results.
 	ref := cr.Spec.Secret
@@ -318,11 +345,16 @@ func (r *NSXMigrationReconciler) getNSXCredentials(cr *nsxv1alpha1.NSXMigration,
 	conn.getPassword(secret)
 	conn.getURL(secret)
 	conn.getInsecureSkipVerify(secret)
+	if err := r.getConnectionOptions(ctx, cr, conn); err != nil {
+		log.Error(err, "Failed to get the NSX connection options")
+		return nil, err
+	}
 
 	log.Info("extracted nsx credentials", "user", conn.user, "url", conn.url)
 
 	// next: validate nsx connection with given credentials
-	res, err := collector.ValidateNSXConnection(conn.url, conn.user, conn.password, !conn.insecureSkipVerify)
+	res, err := collector.ValidateNSXConnection(ctx, conn.url, conn.user, conn.password, !conn.insecureSkipVerify,
+		conn.clientOptions()...)
 	if err != nil {
 		log.Error(err, "REST API call error", "errStr", err.Error())
 		return nil, err
@@ -332,93 +364,176 @@ func (r *NSXMigrationReconciler) getNSXCredentials(cr *nsxv1alpha1.NSXMigration,
 	return conn, nil
 }
 
-func (r *NSXMigrationReconciler) nsxMigration(cr *nsxv1alpha1.NSXMigration, ctx context.Context, log logr.Logger) error {
-
+// nsxInputRunnerOptions returns the runner options for the NSX config input: an offline resources dump if set,
+// and otherwise the credentials for collecting the config from the NSX manager
+func (r *NSXMigrationReconciler) nsxInputRunnerOptions(cr *nsxv1alpha1.NSXMigration, ctx context.Context,
+	log logr.Logger) ([]runner.RunnerOption, error) {
+	if cr.Spec.ResourcesDump != nil {
+		rc, err := r.getNSXResourcesDump(cr, ctx, log)
+		if err != nil {
+			return nil, err
+		}
+		return []runner.RunnerOption{runner.WithNSXResources(rc)}, nil
+	}
 	conn, err := r.getNSXCredentials(cr, ctx, log)
 	if err != nil {
-		return err
+		return nil, err
 	}
-
-	runnerObj, err := runner.NewRunnerWithOptionsList(
-		runner.WithHighVerbosity(true),
-		runner.WithLogFile("debug/log.txt"),
+	return []runner.RunnerOption{
 		runner.WithNSXURL(conn.url),
 		runner.WithNSXUser(conn.user),
 		runner.WithNSXPassword(conn.password),
 		runner.WithDisableInsecureSkipVerify(!conn.insecureSkipVerify),
+		runner.WithNSXSessionAuth(conn.sessionAuth),
+		runner.WithNSXClientCertificatePEM(conn.clientCert, conn.clientKey),
+		runner.WithNSXCACertificatePEM(conn.caBundle),
+		runner.WithNSXRequestTimeout(conn.requestTimeout),
+	}, nil
+}
+
+// nsxMigration collects the NSX config, synthesizes k8s resources from it, and applies them if apply is set.
+// The changes to the generated resources, the summary of the run and the per-phase conditions are stored in the status of cr.
+// resync is set for a periodic resync, which keeps the applied condition if it does not apply.
+func (r *NSXMigrationReconciler) nsxMigration(cr *nsxv1alpha1.NSXMigration, apply, resync bool, ctx context.Context,
+	log logr.Logger) error {
+	failedType, err := r.runMigration(cr, apply, ctx, log)
+	setPhasesConditions(cr, failedType, err, apply, resync)
+	return err
+}
+
+// runMigration runs the migration phases, and returns the condition type of the failed phase upon error
+func (r *NSXMigrationReconciler) runMigration(cr *nsxv1alpha1.NSXMigration, apply bool, ctx context.Context,
+	log logr.Logger) (string, error) {
+	runnerOptions := []runner.RunnerOption{
+		runner.WithContext(ctx),
+		runner.WithHighVerbosity(true),
+		runner.WithLogFile("debug/log.txt"),
 		runner.WithCmd("generate"),
-	)
+		runner.WithAnalysisForSynthesis(true),
+	}
+	inputOptions, err := r.nsxInputRunnerOptions(cr, ctx, log)
+	if err != nil {
+		return typeCollectedNSXMigration, err
+	}
+	runnerOptions = append(runnerOptions, inputOptions...)
+	runnerOptions = append(runnerOptions, SynthesisRunnerOptions(&cr.Spec.SynthesisOptions)...)
+	runnerObj, err := runner.NewRunnerWithOptionsList(runnerOptions...)
 	if err != nil {
-		return err
+		return typeCollectedNSXMigration, err
 	}
 
 	runObservations, err := runnerObj.Run()
 	if err != nil {
 		log.Error(err, "runner.Run() returned with error", "errStr", err.Error())
-		return err
+		return runnerPhaseConditionType(err), err
 	}
+	summary := runObservations.Summary()
+	setSummaryStatus(cr, summary)
+	recordRunMetrics(cr, summary)
 
 	policies, _ := runnerObj.GetGeneratedPolicies()
 	jsonOut, err := runObservations.ConfigAsJSON()
 	if err != nil {
 		log.Error(err, "runner.ConfigAsJSON() returned with error", "errStr", err.Error())
-		return err
+		return typeAnalyzedNSXMigration, err
 	}
 
-	// build configmaps from jsonOut
+	// the reports are stored in configmaps, compressed and split if exceeding the configmap size limit
+	reports, err := r.storeReports(ctx, cr, migrationReports(jsonOut), log)
+	if err != nil {
+		return typeAppliedNSXMigration, err
+	}
+	cr.Status.Reports = reports
 
-	if err := r.genConfigMap(map[string]string{"topology": jsonOut.Topology}, "topology-"+cr.Name, ctx, log); err != nil {
-		return err
+	log.Info("NSXToK8sSynthesis returned with policies", "numPolicies", len(policies))
+
+	generated := runnerObj.GetGeneratedResources()
+	if generated == nil {
+		return typeSynthesizedNSXMigration, fmt.Errorf("no resources were generated for the custom resource %s", cr.Name)
 	}
-	if err := r.genConfigMap(map[string]string{"segmentation": jsonOut.Segmentation}, "segmentation-"+cr.Name, ctx, log); err != nil {
-		return err
+	applyStart := time.Now()
+	changes, err := r.applyGeneratedResources(ctx, cr, generated, apply, log)
+	reconcilePhaseDuration.WithLabelValues(phaseApply).Observe(time.Since(applyStart).Seconds())
+	if err != nil {
+		return typeAppliedNSXMigration, err
 	}
-	if err := r.genConfigMap(map[string]string{"connectivity": jsonOut.Connectivity}, "connectivity-"+cr.Name, ctx, log); err != nil {
-		return err
+	cr.Status.ResourceChanges = changes
+	now := metav1.Now()
+	cr.Status.LastSyncTime = &now
+
+	if !apply {
+		log.Info("generated resources were not applied", "numPlannedChanges", len(changes))
+		return "", nil
 	}
-	if err := r.genConfigMap(map[string]string{"generated-netpols": jsonOut.GeneratedNetpols}, "generated-netpols-"+cr.Name, ctx, log); err != nil {
-		return err
+	log.Info("generated resources applied successfully", "numChanges", len(changes))
+
+	return "", nil
+}
+
+// finalizeMigrateNSX performs the finalizer operations for the MigrateNSX instance marked to be deleted,
+// and removes the finalizer to allow the Kubernetes API to remove the custom resource.
+func (r *NSXMigrationReconciler) finalizeMigrateNSX(ctx context.Context, req ctrl.Request, migratensx *nsxv1alpha1.NSXMigration,
+	log logr.Logger) (ctrl.Result, error) {
+	log.Info("Performing Finalizer Operations for MigrateNSX before delete CR")
+
+	// Let's add here a status "Downgrade" to reflect that this resource began its process to be terminated.
+	meta.SetStatusCondition(&migratensx.Status.Conditions, metav1.Condition{Type: typeDegradedNSXMigration,
+		Status: metav1.ConditionUnknown, Reason: "Finalizing",
+		Message: fmt.Sprintf("Performing finalizer operations for the custom resource: %s ", migratensx.Name)})
+
+	if err := r.Status().Update(ctx, migratensx); err != nil {
+		log.Error(err, "Failed to update migratensx status")
+		return ctrl.Result{}, err
 	}
 
-	log.Info("NSXToK8sSynthesis returned with policies", "numPolicies", len(policies))
+	r.Recorder.Event(migratensx, "Warning", "Deleting",
+		fmt.Sprintf("Custom Resource %s is being deleted from the namespace %s, resources created by it are %s",
+			migratensx.Name, migratensx.Namespace, cleanupAction(migratensx)))
 
-	/*for _, policy := range policies {
-		if policy.Namespace == "" {
-			policy.Namespace = v1.NamespaceDefault
-		}
-		if err = r.Create(ctx, policy); err != nil {
-			log.Error(err, "Failed to create new NetworkPolicy",
-				"NetworkPolicy.Namespace", policy.Namespace, "NetworkPolicy.Name", policy.Name)
-			return err
+	// Perform all operations required before removing the finalizer; upon failure the finalizer
+	// is kept and the request is requeued, so that the operations are retried.
+	count, err := r.doFinalizerOperationsForMigrateNSX(ctx, migratensx, log)
+	if err != nil {
+		meta.SetStatusCondition(&migratensx.Status.Conditions, metav1.Condition{Type: typeDegradedNSXMigration,
+			Status: metav1.ConditionFalse, Reason: "FinalizingFailed",
+			Message: fmt.Sprintf("Failed to perform finalizer operations for the custom resource %s: %s", migratensx.Name, err)})
+		if err := r.Status().Update(ctx, migratensx); err != nil {
+			log.Error(err, "Failed to update migratensx status")
 		}
-	}*/
+		return ctrl.Result{}, err
+	}
 
-	// NetworkPolicy created successfully
+	// Re-fetch the migratensx Custom Resource before updating the status
+	// so that we have the latest state of the resource on the cluster and we will avoid
+	// raising the error "the object has been modified, please apply
+	// your changes to the latest version and try again" which would re-trigger the reconciliation
+	if err := r.Get(ctx, req.NamespacedName, migratensx); err != nil {
+		log.Error(err, "Failed to re-fetch migratensx")
+		return ctrl.Result{}, err
+	}
 
-	log.Info("NetworkPolicy created successfully")
+	meta.SetStatusCondition(&migratensx.Status.Conditions, metav1.Condition{Type: typeDegradedNSXMigration,
+		Status: metav1.ConditionTrue, Reason: "Finalizing", Message: finalizeMessage(migratensx, count)})
 
-	return nil
-}
+	if err := r.Status().Update(ctx, migratensx); err != nil {
+		log.Error(err, "Failed to update migratensx status")
+		return ctrl.Result{}, err
+	}
+
+	log.Info("Removing Finalizer for MigrateNSX after successfully perform the operations")
+	if ok := controllerutil.RemoveFinalizer(migratensx, migratensxFinalizer); !ok {
+		log.Info("Failed to remove finalizer for migratensx")
+		return ctrl.Result{Requeue: true}, nil
+	}
 
-// finalizeMemcached will perform the required operations before delete the CR.
-/*func (r *NSXMigrationReconciler) doFinalizerOperationsForMigrateNSX(cr *nsxv1alpha1.NSXMigration) {
-	// TODO(user): Add the cleanup steps that the operator
-	// needs to do before the CR can be deleted. Examples
-	// of finalizers include performing backups and deleting
-	// resources that are not owned by this CR, like a PVC.
-
-	// Note: It is not recommended to use finalizers with the purpose of deleting resources which are
-	// created and managed in the reconciliation. These ones, such as the Deployment created on this reconcile,
-	// are defined as dependent of the custom resource. See that we use the method ctrl.SetControllerReference.
-	// to set the ownerRef which means that the Deployment will be deleted by the Kubernetes API.
-	// More info: https://kubernetes.io/docs/tasks/administer-cluster/use-cascading-deletion/
-
-	// The following implementation will raise an event
-	r.Recorder.Event(cr, "Warning", "Deleting",
-		fmt.Sprintf("Custom Resource %s is being deleted from the namespace %s",
-			cr.Name,
-			cr.Namespace))
-}*/
+	if err := r.Update(ctx, migratensx); err != nil {
+		log.Error(err, "Failed to remove finalizer for migratensx")
+		return ctrl.Result{}, err
+	}
+	deleteMigrationMetrics(migratensx)
+	// stop the Reconcile
+	return ctrl.Result{}, nil
+}
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *NSXMigrationReconciler) SetupWithManager(mgr ctrl.Manager) error {
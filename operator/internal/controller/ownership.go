@@ -0,0 +1,112 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	nsxv1alpha1 "github.com/np-guard/vmware-analyzer-operator/api/v1alpha1"
+)
+
+// A resource may be generated by multiple NSXMigrations of a phased migration, e.g. the UDN of a namespace whose VMs
+// are migrated by several phases. Such a resource is owned by each of them: each NSXMigration applies with its own
+// field manager, sets its own owner label, and a non-controller owner reference if the resource is in its namespace.
+// A shared resource which is no longer generated by an NSXMigration is released by it, rather than deleted.
+
+const (
+	// fieldManagerPrefix prefixes the field manager of the server-side apply of the resources generated by an NSXMigration
+	fieldManagerPrefix = "nsx-migration-operator/"
+	// maxFieldManagerLength is the max length of a field manager allowed by the API server
+	maxFieldManagerLength = 128
+
+	// labelOwnerPrefix prefixes the label identifying an NSXMigration that generated a resource, used for pruning.
+	// The label key is suffixed by a hash of the namespaced name of the NSXMigration, and its value is its name.
+	// Owner references are set only on resources in the namespace of the NSXMigration, since k8s does not allow
+	// cluster-scoped or cross-namespace dependents of a namespaced owner.
+	labelOwnerPrefix = "nsx.npguard.io/owner-"
+	ownerHashLength  = 16
+)
+
+func ownerHash(cr *nsxv1alpha1.NSXMigration) string {
+	sum := sha256.Sum256([]byte(cr.Namespace + "/" + cr.Name))
+	return hex.EncodeToString(sum[:])[:ownerHashLength]
+}
+
+// fieldManager returns the field manager of the resources generated by cr
+func fieldManager(cr *nsxv1alpha1.NSXMigration) string {
+	manager := fieldManagerPrefix + cr.Namespace + "/" + cr.Name
+	if len(manager) > maxFieldManagerLength {
+		manager = manager[:maxFieldManagerLength-ownerHashLength] + ownerHash(cr)
+	}
+	return manager
+}
+
+func ownerLabelKey(cr *nsxv1alpha1.NSXMigration) string {
+	return labelOwnerPrefix + ownerHash(cr)
+}
+
+// migrationLabels returns the owner label of cr, set on the resources it generates
+func migrationLabels(cr *nsxv1alpha1.NSXMigration) map[string]string {
+	value := cr.Name
+	if len(value) > validation.LabelValueMaxLength {
+		value = value[:validation.LabelValueMaxLength]
+	}
+	return map[string]string{ownerLabelKey(cr): strings.TrimRight(value, "-.")}
+}
+
+// ownedByOtherMigrations returns whether obj was generated also by NSXMigrations other than cr
+func ownedByOtherMigrations(cr *nsxv1alpha1.NSXMigration, obj client.Object) bool {
+	own := ownerLabelKey(cr)
+	for key := range obj.GetLabels() {
+		if strings.HasPrefix(key, labelOwnerPrefix) && key != own {
+			return true
+		}
+	}
+	return false
+}
+
+// releaseObject releases a resource shared with other NSXMigrations from cr: applying it empty by the field manager
+// of cr removes the owner label and owner reference of cr, and the fields which are not owned by other NSXMigrations
+func (r *NSXMigrationReconciler) releaseObject(ctx context.Context, cr *nsxv1alpha1.NSXMigration, obj client.Object) error {
+	gvk, err := apiutil.GVKForObject(obj, r.Scheme)
+	if err != nil {
+		return err
+	}
+	released := &unstructured.Unstructured{}
+	released.SetGroupVersionKind(gvk)
+	released.SetNamespace(obj.GetNamespace())
+	released.SetName(obj.GetName())
+	return client.IgnoreNotFound(r.Patch(ctx, released, client.Apply, client.FieldOwner(fieldManager(cr)), client.ForceOwnership))
+}
+
+// hasOwnerReference returns whether obj has an owner reference to cr
+func hasOwnerReference(cr *nsxv1alpha1.NSXMigration, obj client.Object) bool {
+	for _, ref := range obj.GetOwnerReferences() {
+		if ref.UID == cr.UID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	networking "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	nsxv1alpha1 "github.com/np-guard/vmware-analyzer-operator/api/v1alpha1"
+	"github.com/np-guard/vmware-analyzer/pkg/synthesis/ocpvirt/resources"
+)
+
+var _ = Describe("Resources ownership", func() {
+	It("Should identify each NSXMigration by its own field manager and owner label", func() {
+		phase1 := &nsxv1alpha1.NSXMigration{ObjectMeta: metav1.ObjectMeta{Name: "phase-1", Namespace: "nsx"}}
+		phase2 := &nsxv1alpha1.NSXMigration{ObjectMeta: metav1.ObjectMeta{Name: "phase-2", Namespace: "nsx"}}
+		Expect(fieldManager(phase1)).To(Equal("nsx-migration-operator/nsx/phase-1"))
+		Expect(fieldManager(phase1)).NotTo(Equal(fieldManager(phase2)))
+		long := &nsxv1alpha1.NSXMigration{ObjectMeta: metav1.ObjectMeta{Name: strings.Repeat("a", 200), Namespace: "nsx"}}
+		Expect(len(fieldManager(long))).To(Equal(maxFieldManagerLength))
+		Expect(migrationLabels(long)[ownerLabelKey(long)]).To(HaveLen(63))
+
+		policy := &networking.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{Labels: migrationLabels(phase1)}}
+		Expect(ownedByOtherMigrations(phase1, policy)).To(BeFalse())
+		Expect(ownedByOtherMigrations(phase2, policy)).To(BeTrue())
+		policy.Labels[ownerLabelKey(phase2)] = phase2.Name
+		Expect(ownedByOtherMigrations(phase1, policy)).To(BeTrue())
+	})
+
+	Context("When two phases of a migration share a namespace", func() {
+		ctx := context.Background()
+		var (
+			reconciler     *NSXMigrationReconciler
+			phase1, phase2 *nsxv1alpha1.NSXMigration
+		)
+
+		// generated returns the resources generated by a phase: a policy of its own, and a policy of the shared namespace
+		generated := func(cr *nsxv1alpha1.NSXMigration, shared bool) *resources.Generated {
+			policy := func(name string) *networking.NetworkPolicy {
+				return &networking.NetworkPolicy{
+					TypeMeta:   metav1.TypeMeta{Kind: "NetworkPolicy", APIVersion: networking.SchemeGroupVersion.String()},
+					ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+					Spec:       networking.NetworkPolicySpec{PolicyTypes: []networking.PolicyType{networking.PolicyTypeIngress}},
+				}
+			}
+			res := &resources.Generated{NetworkPolicies: []*networking.NetworkPolicy{policy(cr.Name + "-policy-0")}}
+			if shared {
+				res.NetworkPolicies = append(res.NetworkPolicies, policy("shared-policy"))
+			}
+			return res
+		}
+		sharedPolicy := func() *networking.NetworkPolicy {
+			policy := &networking.NetworkPolicy{}
+			Expect(k8sClient.Get(ctx, client.ObjectKey{Name: "shared-policy", Namespace: "default"}, policy)).To(Succeed())
+			return policy
+		}
+
+		BeforeEach(func() {
+			reconciler = &NSXMigrationReconciler{Client: k8sClient, Scheme: k8sClient.Scheme()}
+			phase1 = &nsxv1alpha1.NSXMigration{ObjectMeta: metav1.ObjectMeta{Name: "phase-1", Namespace: "default"}}
+			phase2 = &nsxv1alpha1.NSXMigration{ObjectMeta: metav1.ObjectMeta{Name: "phase-2", Namespace: "default"}}
+			for _, cr := range []*nsxv1alpha1.NSXMigration{phase1, phase2} {
+				Expect(k8sClient.Create(ctx, cr)).To(Succeed())
+				_, err := reconciler.applyGeneratedResources(ctx, cr, generated(cr, true), true, log.Log)
+				Expect(err).NotTo(HaveOccurred())
+			}
+		})
+
+		AfterEach(func() {
+			for _, cr := range []*nsxv1alpha1.NSXMigration{phase1, phase2} {
+				Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, cr))).To(Succeed())
+			}
+			Expect(k8sClient.DeleteAllOf(ctx, &networking.NetworkPolicy{}, client.InNamespace("default"))).To(Succeed())
+		})
+
+		It("Should not detect a drift of the shared resources", func() {
+			policy := sharedPolicy()
+			Expect(ownedByOtherMigrations(phase1, policy)).To(BeTrue())
+			Expect(ownedByOtherMigrations(phase2, policy)).To(BeTrue())
+			Expect(policy.OwnerReferences).To(HaveLen(2))
+			for _, cr := range []*nsxv1alpha1.NSXMigration{phase1, phase2} {
+				changes, err := reconciler.applyGeneratedResources(ctx, cr, generated(cr, true), false, log.Log)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(changes).To(BeEmpty())
+			}
+		})
+
+		It("Should release a shared resource no longer generated by a phase, rather than prune it", func() {
+			changes, err := reconciler.applyGeneratedResources(ctx, phase1, generated(phase1, false), true, log.Log)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(changes).To(ConsistOf(nsxv1alpha1.ResourceChange{Kind: "NetworkPolicy", Namespace: "default",
+				Name: "shared-policy", Action: nsxv1alpha1.ResourceActionRelease}))
+			policy := sharedPolicy()
+			Expect(policy.Labels).NotTo(HaveKey(ownerLabelKey(phase1)))
+			Expect(policy.Labels).To(HaveKey(ownerLabelKey(phase2)))
+			Expect(hasOwnerReference(phase1, policy)).To(BeFalse())
+			Expect(hasOwnerReference(phase2, policy)).To(BeTrue())
+			Expect(policy.Spec.PolicyTypes).To(ConsistOf(networking.PolicyTypeIngress))
+		})
+
+		It("Should delete a shared resource only upon deletion of its last phase", func() {
+			count, err := reconciler.doFinalizerOperationsForMigrateNSX(ctx, phase1, log.Log)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(Equal(2))
+			Expect(sharedPolicy().Labels).NotTo(HaveKey(ownerLabelKey(phase1)))
+
+			count, err = reconciler.doFinalizerOperationsForMigrateNSX(ctx, phase2, log.Log)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(Equal(2))
+			err = k8sClient.Get(ctx, client.ObjectKey{Name: "shared-policy", Namespace: "default"}, &networking.NetworkPolicy{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,200 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	nsxv1alpha1 "github.com/np-guard/vmware-analyzer-operator/api/v1alpha1"
+	"github.com/np-guard/vmware-analyzer/pkg/runner"
+)
+
+const (
+	// maxConfigMapDataSize is the max size of the data stored in a single ConfigMap.
+	// k8s limits the size of a ConfigMap to 1MiB, some of which is left for its metadata.
+	maxConfigMapDataSize = 900 * 1024
+
+	// labelReport identifies the report stored in a ConfigMap, used for pruning parts of a report no longer required
+	labelReport = "nsx.npguard.io/report"
+
+	compressedKeySuffix = ".gz"
+)
+
+// report names, also used as the keys of the reports in their ConfigMaps
+const (
+	reportTopology         = "topology"
+	reportSegmentation     = "segmentation"
+	reportConnectivity     = "connectivity"
+	reportGeneratedNetpols = "generated-netpols"
+)
+
+type report struct {
+	name    string
+	content string
+}
+
+func migrationReports(jsonOut *runner.JSONResults) []report {
+	return []report{
+		{name: reportTopology, content: jsonOut.Topology},
+		{name: reportSegmentation, content: jsonOut.Segmentation},
+		{name: reportConnectivity, content: jsonOut.Connectivity},
+		{name: reportGeneratedNetpols, content: jsonOut.GeneratedNetpols},
+	}
+}
+
+// encodeReport returns the parts of a report to store in ConfigMaps, each of at most maxSize bytes.
+// A report larger than maxSize is gzip compressed, and then split into parts if still larger than maxSize.
+func encodeReport(content string, maxSize int) (parts [][]byte, compressed bool, err error) {
+	if len(content) <= maxSize {
+		return [][]byte{[]byte(content)}, false, nil
+	}
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write([]byte(content)); err != nil {
+		return nil, false, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, false, err
+	}
+	data := buf.Bytes()
+	for len(data) > maxSize {
+		parts = append(parts, data[:maxSize])
+		data = data[maxSize:]
+	}
+	return append(parts, data), true, nil
+}
+
+// reportConfigMapName returns the name of the ConfigMap storing the i-th part of a report.
+// The first part keeps the name of the ConfigMap of a report stored as a whole.
+func reportConfigMapName(reportName, crName string, i int) string {
+	if i == 0 {
+		return reportName + "-" + crName
+	}
+	return fmt.Sprintf("%s-%s-%d", reportName, crName, i)
+}
+
+// storeReports stores the reports of a migration in ConfigMaps, and returns references to them
+func (r *NSXMigrationReconciler) storeReports(ctx context.Context, cr *nsxv1alpha1.NSXMigration, reports []report,
+	log logr.Logger) ([]nsxv1alpha1.ReportReference, error) {
+	res := make([]nsxv1alpha1.ReportReference, len(reports))
+	for i := range reports {
+		ref, err := r.storeReport(ctx, cr, &reports[i], log)
+		if err != nil {
+			return nil, err
+		}
+		res[i] = ref
+	}
+	return res, nil
+}
+
+func (r *NSXMigrationReconciler) storeReport(ctx context.Context, cr *nsxv1alpha1.NSXMigration, rep *report,
+	log logr.Logger) (nsxv1alpha1.ReportReference, error) {
+	ref := nsxv1alpha1.ReportReference{Name: rep.name, Key: rep.name, Size: len(rep.content)}
+	parts, compressed, err := encodeReport(rep.content, maxConfigMapDataSize)
+	if err != nil {
+		return ref, err
+	}
+	ref.Compressed = compressed
+	if compressed {
+		ref.Key += compressedKeySuffix
+		log.Info("report exceeds the ConfigMap size limit, storing it compressed",
+			"report", rep.name, "size", len(rep.content), "numParts", len(parts))
+	}
+	for i, part := range parts {
+		name := reportConfigMapName(rep.name, cr.Name, i)
+		var data map[string]string
+		var binaryData map[string][]byte
+		if compressed {
+			binaryData = map[string][]byte{ref.Key: part}
+		} else {
+			data = map[string]string{ref.Key: string(part)}
+		}
+		if err := r.genConfigMap(cr, rep.name, data, binaryData, name, ctx, log); err != nil {
+			return ref, err
+		}
+		ref.ConfigMaps = append(ref.ConfigMaps, name)
+	}
+	return ref, r.pruneReportConfigMaps(ctx, cr, rep.name, ref.ConfigMaps, log)
+}
+
+// pruneReportConfigMaps deletes ConfigMaps storing parts of a report by a previous migration, which are no longer used
+func (r *NSXMigrationReconciler) pruneReportConfigMaps(ctx context.Context, cr *nsxv1alpha1.NSXMigration, reportName string,
+	used []string, log logr.Logger) error {
+	labels := migrationLabels(cr)
+	labels[labelReport] = reportName
+	list := &v1.ConfigMapList{}
+	if err := r.List(ctx, list, client.InNamespace(cr.Namespace), client.MatchingLabels(labels)); err != nil {
+		return err
+	}
+	usedNames := map[string]bool{}
+	for _, name := range used {
+		usedNames[name] = true
+	}
+	for i := range list.Items {
+		cm := &list.Items[i]
+		if usedNames[cm.Name] {
+			continue
+		}
+		if err := r.Delete(ctx, cm); client.IgnoreNotFound(err) != nil {
+			log.Error(err, "Failed to prune ConfigMap", "ConfigMap.Namespace", cm.Namespace, "ConfigMap.Name", cm.Name)
+			return err
+		}
+		log.Info("pruned configmap no longer used", "ConfigMap.Namespace", cm.Namespace, "ConfigMap.Name", cm.Name)
+	}
+	return nil
+}
+
+func (r *NSXMigrationReconciler) genConfigMap(cr *nsxv1alpha1.NSXMigration, reportName string, data map[string]string,
+	binaryData map[string][]byte, name string, ctx context.Context, log logr.Logger) error {
+	labels := migrationLabels(cr)
+	labels[labelReport] = reportName
+	cm := &v1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ConfigMap",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: cr.Namespace,
+			Labels:    labels,
+		},
+		Data:       data,
+		BinaryData: binaryData,
+	}
+	if err := controllerutil.SetControllerReference(cr, cm, r.Scheme); err != nil {
+		return err
+	}
+	// the configmap is applied, since it already exists on re-reconcile.
+	// keys no longer applied (e.g. of a report that is now compressed) are removed by the apply.
+	if err := r.Patch(ctx, cm, client.Apply, client.FieldOwner(fieldManager(cr)), client.ForceOwnership); err != nil {
+		log.Error(err, "Failed to apply ConfigMap",
+			"ConfigMap.Namespace", cm.Namespace, "ConfigMap.Name", cm.Name)
+		return err
+	}
+
+	log.Info("generated configmap", "ConfigMap.Namespace", cm.Namespace, "ConfigMap.Name", cm.Name)
+	return nil
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package controller
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NSXMigration reports", func() {
+	It("Should store a small report as is", func() {
+		parts, compressed, err := encodeReport("small report", 100)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(compressed).To(BeFalse())
+		Expect(parts).To(Equal([][]byte{[]byte("small report")}))
+	})
+
+	It("Should compress a large report", func() {
+		content := strings.Repeat("a repetitive report line\n", 1000)
+		parts, compressed, err := encodeReport(content, 1000)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(compressed).To(BeTrue())
+		Expect(parts).To(HaveLen(1))
+		Expect(decodeReport(parts)).To(Equal(content))
+	})
+
+	It("Should split a large report which is still too large after compression", func() {
+		random := make([]byte, 5000)
+		_, err := rand.Read(random)
+		Expect(err).NotTo(HaveOccurred())
+		content := hex.EncodeToString(random)
+		parts, compressed, err := encodeReport(content, 1000)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(compressed).To(BeTrue())
+		Expect(len(parts)).To(BeNumerically(">", 1))
+		for _, part := range parts {
+			Expect(len(part)).To(BeNumerically("<=", 1000))
+		}
+		Expect(decodeReport(parts)).To(Equal(content))
+	})
+
+	It("Should keep the name of the first ConfigMap of a report", func() {
+		Expect(reportConfigMapName(reportTopology, "migration", 0)).To(Equal("topology-migration"))
+		Expect(reportConfigMapName(reportTopology, "migration", 2)).To(Equal("topology-migration-2"))
+	})
+})
+
+func decodeReport(parts [][]byte) string {
+	r, err := gzip.NewReader(bytes.NewReader(bytes.Join(parts, nil)))
+	Expect(err).NotTo(HaveOccurred())
+	content, err := io.ReadAll(r)
+	Expect(err).NotTo(HaveOccurred())
+	return string(content)
+}
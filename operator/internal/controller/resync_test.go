@@ -0,0 +1,40 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	nsxv1alpha1 "github.com/np-guard/vmware-analyzer-operator/api/v1alpha1"
+)
+
+var _ = Describe("NSXMigration resync", func() {
+	It("Should compute the time to the next resync", func() {
+		cr := &nsxv1alpha1.NSXMigration{}
+		Expect(resyncInterval(cr)).To(BeZero())
+
+		cr.Spec.ResyncInterval = &metav1.Duration{Duration: time.Hour}
+		Expect(timeToResync(cr)).To(BeNumerically("<=", 0))
+
+		lastSync := metav1.NewTime(time.Now().Add(-time.Minute))
+		cr.Status.LastSyncTime = &lastSync
+		Expect(timeToResync(cr)).To(BeNumerically("~", 59*time.Minute, time.Second))
+	})
+})
@@ -0,0 +1,111 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package controller
+
+import (
+	"errors"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	nsxv1alpha1 "github.com/np-guard/vmware-analyzer-operator/api/v1alpha1"
+	"github.com/np-guard/vmware-analyzer/pkg/runner"
+)
+
+// Definitions of the per-phase status conditions of a migration, ordered by the phases order
+const (
+	// typeCollectedNSXMigration represents the status of the NSX resources collection
+	typeCollectedNSXMigration = "Collected"
+	// typeAnalyzedNSXMigration represents the status of the NSX config parsing and connectivity analysis
+	typeAnalyzedNSXMigration = "Analyzed"
+	// typeSynthesizedNSXMigration represents the status of the k8s resources synthesis
+	typeSynthesizedNSXMigration = "Synthesized"
+	// typeAppliedNSXMigration represents the status of applying the generated resources to the cluster
+	typeAppliedNSXMigration = "Applied"
+)
+
+var phasesConditionTypes = []string{typeCollectedNSXMigration, typeAnalyzedNSXMigration, typeSynthesizedNSXMigration,
+	typeAppliedNSXMigration}
+
+// runnerPhaseConditionType maps the phase of a runner error to its condition type
+func runnerPhaseConditionType(err error) string {
+	var phaseErr *runner.PhaseError
+	if !errors.As(err, &phaseErr) {
+		return typeCollectedNSXMigration
+	}
+	switch phaseErr.Phase {
+	case runner.PhaseAnalysis:
+		return typeAnalyzedNSXMigration
+	case runner.PhaseSynthesis:
+		return typeSynthesizedNSXMigration
+	default:
+		return typeCollectedNSXMigration
+	}
+}
+
+// setPhasesConditions sets the per-phase conditions of cr: the phases before failedType succeeded,
+// the phase failedType failed with err, and the phases after it were not run.
+// If failedType is empty, all phases succeeded; the applied phase is reported as not applied if apply is false.
+// On a periodic resync without apply, the applied condition is left as is, since the resources applied before
+// are still in the cluster.
+func setPhasesConditions(cr *nsxv1alpha1.NSXMigration, failedType string, err error, apply, resync bool) {
+	failed := false
+	for _, conditionType := range phasesConditionTypes {
+		if conditionType == typeAppliedNSXMigration && resync && !apply {
+			continue
+		}
+		condition := metav1.Condition{Type: conditionType, ObservedGeneration: cr.Generation}
+		switch {
+		case failed:
+			condition.Status, condition.Reason = metav1.ConditionUnknown, "NotRun"
+			condition.Message = fmt.Sprintf("not run since %s failed", failedType)
+		case conditionType == failedType:
+			failed = true
+			condition.Status, condition.Reason = metav1.ConditionFalse, "Failed"
+			condition.Message = err.Error()
+		case conditionType == typeAppliedNSXMigration && !apply:
+			condition.Status, condition.Reason = metav1.ConditionFalse, "NotApplied"
+			condition.Message = "the generated resources were not applied, the planned changes are reported in the status"
+		default:
+			condition.Status, condition.Reason = metav1.ConditionTrue, "Succeeded"
+			condition.Message = conditionType + " successfully"
+		}
+		meta.SetStatusCondition(&cr.Status.Conditions, condition)
+	}
+}
+
+// setSummaryStatus sets the status summaries of cr from the runner's summary
+func setSummaryStatus(cr *nsxv1alpha1.NSXMigration, summary *runner.Summary) {
+	cr.Status.Collection, cr.Status.Analysis, cr.Status.Synthesis = nil, nil, nil
+	if c := summary.Collection; c != nil {
+		cr.Status.Collection = &nsxv1alpha1.CollectionStatus{VMs: c.VMs, Segments: c.Segments, Groups: c.Groups,
+			SecurityPolicies: c.SecurityPolicies, Rules: c.Rules, Services: c.Services}
+		if !c.Time.IsZero() {
+			collectionTime := metav1.NewTime(c.Time)
+			cr.Status.Collection.Time = &collectionTime
+		}
+	}
+	if a := summary.Analysis; a != nil {
+		cr.Status.Analysis = &nsxv1alpha1.AnalysisStatus{VMPairs: a.VMPairs, PermittedVMPairs: a.PermittedVMPairs,
+			RulesNotEvaluated: a.RulesNotEvaluated}
+	}
+	if s := summary.Synthesis; s != nil {
+		cr.Status.Synthesis = &nsxv1alpha1.SynthesisStatus{NetworkPolicies: s.NetworkPolicies,
+			AdminNetworkPolicies: s.AdminNetworkPolicies, Namespaces: s.Namespaces, UDNs: s.UDNs,
+			UnsupportedConstructs: s.UnsupportedConstructs, NotFullySupportedVMs: s.NotFullySupportedVMs}
+	}
+}
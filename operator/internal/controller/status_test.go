@@ -0,0 +1,66 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package controller
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	nsxv1alpha1 "github.com/np-guard/vmware-analyzer-operator/api/v1alpha1"
+	"github.com/np-guard/vmware-analyzer/pkg/runner"
+)
+
+var _ = Describe("NSXMigration phases conditions", func() {
+	It("Should set the conditions of the phases before and after a failed phase", func() {
+		cr := &nsxv1alpha1.NSXMigration{}
+		err := &runner.PhaseError{Phase: runner.PhaseSynthesis, Err: errors.New("synthesis error")}
+		setPhasesConditions(cr, runnerPhaseConditionType(err), err, true, false)
+
+		Expect(meta.IsStatusConditionTrue(cr.Status.Conditions, typeCollectedNSXMigration)).To(BeTrue())
+		Expect(meta.IsStatusConditionTrue(cr.Status.Conditions, typeAnalyzedNSXMigration)).To(BeTrue())
+		synthesized := meta.FindStatusCondition(cr.Status.Conditions, typeSynthesizedNSXMigration)
+		Expect(synthesized.Status).To(Equal(metav1.ConditionFalse))
+		Expect(synthesized.Message).To(ContainSubstring("synthesis error"))
+		applied := meta.FindStatusCondition(cr.Status.Conditions, typeAppliedNSXMigration)
+		Expect(applied.Status).To(Equal(metav1.ConditionUnknown))
+	})
+
+	It("Should report the applied phase as not applied in dry-run", func() {
+		cr := &nsxv1alpha1.NSXMigration{}
+		setPhasesConditions(cr, "", nil, false, false)
+
+		Expect(meta.IsStatusConditionTrue(cr.Status.Conditions, typeSynthesizedNSXMigration)).To(BeTrue())
+		applied := meta.FindStatusCondition(cr.Status.Conditions, typeAppliedNSXMigration)
+		Expect(applied.Status).To(Equal(metav1.ConditionFalse))
+		Expect(applied.Reason).To(Equal("NotApplied"))
+	})
+
+	It("Should keep the applied condition on a resync without apply", func() {
+		cr := &nsxv1alpha1.NSXMigration{}
+		setPhasesConditions(cr, "", nil, true, false)
+		setPhasesConditions(cr, "", nil, false, true)
+		Expect(meta.IsStatusConditionTrue(cr.Status.Conditions, typeAppliedNSXMigration)).To(BeTrue())
+
+		err := &runner.PhaseError{Phase: runner.PhaseSynthesis, Err: errors.New("synthesis error")}
+		setPhasesConditions(cr, runnerPhaseConditionType(err), err, false, true)
+		Expect(meta.IsStatusConditionFalse(cr.Status.Conditions, typeSynthesizedNSXMigration)).To(BeTrue())
+		Expect(meta.IsStatusConditionTrue(cr.Status.Conditions, typeAppliedNSXMigration)).To(BeTrue())
+	})
+})
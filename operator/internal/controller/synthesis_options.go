@@ -0,0 +1,48 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	nsxv1alpha1 "github.com/np-guard/vmware-analyzer-operator/api/v1alpha1"
+	"github.com/np-guard/vmware-analyzer/pkg/runner"
+)
+
+// SynthesisRunnerOptions returns the runner options matching the synthesis options of a NSXMigration spec.
+// Enum options which are not set are left to the runner defaults.
+func SynthesisRunnerOptions(opts *nsxv1alpha1.SynthesisOptions) []runner.RunnerOption {
+	res := []runner.RunnerOption{
+		runner.WithSynthAdminPolicies(opts.SynthesizeAdminPolicies),
+		runner.WithSynthDNSPolicies(opts.CreateDNSPolicy),
+		runner.WithSynthesisHints(opts.DisjointHints),
+		runner.WithInferHints(opts.InferDisjointHints),
+		runner.WithAnalysisVMsFilter(opts.VMs),
+		runner.WithMigratedVMs(opts.MigratedVMs),
+		runner.WithMigratedSegments(opts.MigratedSegments),
+		runner.WithPolicyNamePrefix(opts.PolicyNamePrefix),
+		runner.WithANPPriorityOffset(int(opts.AdminPolicyPriorityOffset)),
+	}
+	if opts.EndpointsMapping != "" {
+		res = append(res, runner.WithEndpointsMapping(opts.EndpointsMapping))
+	}
+	if opts.SegmentsMapping != "" {
+		res = append(res, runner.WithSegmentsMapping(opts.SegmentsMapping))
+	}
+	if opts.PolicyOptimizationLevel != "" {
+		res = append(res, runner.WithPolicyOptimizationLevel(opts.PolicyOptimizationLevel))
+	}
+	return res
+}
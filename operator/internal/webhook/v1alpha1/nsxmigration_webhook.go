@@ -0,0 +1,253 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	nsxv1alpha1 "github.com/np-guard/vmware-analyzer-operator/api/v1alpha1"
+	"github.com/np-guard/vmware-analyzer-operator/internal/controller"
+	"github.com/np-guard/vmware-analyzer/pkg/runner"
+)
+
+// nolint:unused
+// log is for logging in this package.
+var nsxmigrationlog = logf.Log.WithName("nsxmigration-resource")
+
+// default values of the synthesis options, matching the defaults of the nsxanalyzer generate command
+const (
+	defaultEndpointsMapping        = "both"
+	defaultSegmentsMapping         = "udns"
+	defaultPolicyOptimizationLevel = "max"
+)
+
+// SetupNSXMigrationWebhookWithManager registers the webhook for NSXMigration in the manager.
+// resourcesDumpRoot is the directory NSX resources dump files are mounted on, as configured for the controller.
+func SetupNSXMigrationWebhookWithManager(mgr ctrl.Manager, resourcesDumpRoot string) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&nsxv1alpha1.NSXMigration{}).
+		WithValidator(&NSXMigrationCustomValidator{ResourcesDumpRoot: resourcesDumpRoot}).
+		WithDefaulter(&NSXMigrationCustomDefaulter{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-nsx-npguard-io-v1alpha1-nsxmigration,mutating=true,failurePolicy=fail,sideEffects=None,groups=nsx.npguard.io,resources=nsxmigrations,verbs=create;update,versions=v1alpha1,name=mnsxmigration-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// NSXMigrationCustomDefaulter sets default values on the NSXMigration resource when it is created or updated.
+type NSXMigrationCustomDefaulter struct{}
+
+var _ webhook.CustomDefaulter = &NSXMigrationCustomDefaulter{}
+
+// Default implements webhook.CustomDefaulter so a webhook will be registered for the Kind NSXMigration.
+func (d *NSXMigrationCustomDefaulter) Default(_ context.Context, obj runtime.Object) error {
+	nsxmigration, ok := obj.(*nsxv1alpha1.NSXMigration)
+	if !ok {
+		return fmt.Errorf("expected an NSXMigration object but got %T", obj)
+	}
+	nsxmigrationlog.Info("Defaulting for NSXMigration", "name", nsxmigration.GetName())
+
+	// the secret is looked up in the namespace of the NSXMigration resource, if not specified
+	if nsxmigration.Spec.Secret.Name != "" && nsxmigration.Spec.Secret.Namespace == "" {
+		nsxmigration.Spec.Secret.Namespace = nsxmigration.GetNamespace()
+	}
+	if nsxmigration.Spec.DeletionPolicy == "" {
+		nsxmigration.Spec.DeletionPolicy = nsxv1alpha1.DeletionPolicyDelete
+	}
+	opts := &nsxmigration.Spec.SynthesisOptions
+	if opts.EndpointsMapping == "" {
+		opts.EndpointsMapping = defaultEndpointsMapping
+	}
+	if opts.SegmentsMapping == "" {
+		opts.SegmentsMapping = defaultSegmentsMapping
+	}
+	if opts.PolicyOptimizationLevel == "" {
+		opts.PolicyOptimizationLevel = defaultPolicyOptimizationLevel
+	}
+	// the policies of NSXMigrations of different phases should not conflict
+	if isPhased(opts) && opts.PolicyNamePrefix == "" {
+		opts.PolicyNamePrefix = defaultPolicyNamePrefix(nsxmigration.GetName())
+	}
+	return nil
+}
+
+func isPhased(opts *nsxv1alpha1.SynthesisOptions) bool {
+	return len(opts.MigratedVMs) > 0 || len(opts.MigratedSegments) > 0
+}
+
+// defaultPolicyNamePrefix returns a DNS label derived from the name of the NSXMigration, which may be a DNS subdomain
+func defaultPolicyNamePrefix(name string) string {
+	prefix := strings.ReplaceAll(name, ".", "-")
+	if len(prefix) > validation.DNS1123LabelMaxLength {
+		prefix = prefix[:validation.DNS1123LabelMaxLength]
+	}
+	return strings.TrimRight(prefix, "-")
+}
+
+// +kubebuilder:webhook:path=/validate-nsx-npguard-io-v1alpha1-nsxmigration,mutating=false,failurePolicy=fail,sideEffects=None,groups=nsx.npguard.io,resources=nsxmigrations,verbs=create;update,versions=v1alpha1,name=vnsxmigration-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// NSXMigrationCustomValidator validates the NSXMigration resource when it is created or updated.
+type NSXMigrationCustomValidator struct {
+	// ResourcesDumpRoot is the directory NSX resources dump files are mounted on
+	ResourcesDumpRoot string
+}
+
+var _ webhook.CustomValidator = &NSXMigrationCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type NSXMigration.
+func (v *NSXMigrationCustomValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	nsxmigration, ok := obj.(*nsxv1alpha1.NSXMigration)
+	if !ok {
+		return nil, fmt.Errorf("expected a NSXMigration object but got %T", obj)
+	}
+	nsxmigrationlog.Info("Validation for NSXMigration upon creation", "name", nsxmigration.GetName())
+	return nil, v.validateNSXMigration(nsxmigration)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type NSXMigration.
+func (v *NSXMigrationCustomValidator) ValidateUpdate(_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	nsxmigration, ok := newObj.(*nsxv1alpha1.NSXMigration)
+	if !ok {
+		return nil, fmt.Errorf("expected a NSXMigration object for the newObj but got %T", newObj)
+	}
+	nsxmigrationlog.Info("Validation for NSXMigration upon update", "name", nsxmigration.GetName())
+	return nil, v.validateNSXMigration(nsxmigration)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type NSXMigration.
+func (v *NSXMigrationCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	// no validation on deletion
+	return nil, nil
+}
+
+func (v *NSXMigrationCustomValidator) validateNSXMigration(nsxmigration *nsxv1alpha1.NSXMigration) error {
+	specPath := field.NewPath("spec")
+	allErrs := field.ErrorList{}
+	switch {
+	case nsxmigration.Spec.ResourcesDump != nil && nsxmigration.Spec.Secret.Name != "":
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("secret"),
+			"a secret with NSX credentials should not be set together with an NSX resources dump"))
+	case nsxmigration.Spec.ResourcesDump != nil:
+		allErrs = append(allErrs, v.validateResourcesDump(nsxmigration.Spec.ResourcesDump, specPath.Child("resourcesDump"))...)
+	case nsxmigration.Spec.Secret.Name == "":
+		allErrs = append(allErrs, field.Required(specPath.Child("secret", "name"),
+			"a secret with NSX credentials is required, unless an NSX resources dump is set"))
+	}
+	if interval := nsxmigration.Spec.ResyncInterval; interval != nil && interval.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("resyncInterval"), interval.Duration.String(),
+			"resync interval should be positive"))
+	}
+	allErrs = append(allErrs, validateConnectionOptions(&nsxmigration.Spec.Connection, specPath.Child("connection"))...)
+	allErrs = append(allErrs, validateSynthesisOptions(&nsxmigration.Spec.SynthesisOptions, specPath.Child("synthesisOptions"))...)
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(schema.GroupKind{Group: nsxv1alpha1.GroupVersion.Group, Kind: "NSXMigration"},
+		nsxmigration.Name, allErrs)
+}
+
+func (v *NSXMigrationCustomValidator) validateResourcesDump(src *nsxv1alpha1.ResourcesDumpSource, srcPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	sources := 0
+	if src.ConfigMap != nil {
+		sources++
+		allErrs = append(allErrs, validateKeySelector(src.ConfigMap.Name, src.ConfigMap.Key, srcPath.Child("configMap"))...)
+	}
+	if src.Secret != nil {
+		sources++
+		allErrs = append(allErrs, validateKeySelector(src.Secret.Name, src.Secret.Key, srcPath.Child("secret"))...)
+	}
+	if src.Path != "" {
+		sources++
+		if err := controller.ValidateResourcesDumpPath(v.ResourcesDumpRoot, src.Path); err != nil {
+			allErrs = append(allErrs, field.Invalid(srcPath.Child("path"), src.Path, err.Error()))
+		}
+	}
+	if sources != 1 {
+		allErrs = append(allErrs, field.Invalid(srcPath, sources, "exactly one of configMap, secret and path should be set"))
+	}
+	return allErrs
+}
+
+func validateKeySelector(name, key string, selectorPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if name == "" {
+		allErrs = append(allErrs, field.Required(selectorPath.Child("name"), "name is required"))
+	}
+	if key == "" {
+		allErrs = append(allErrs, field.Required(selectorPath.Child("key"), "key is required"))
+	}
+	return allErrs
+}
+
+func validateConnectionOptions(opts *nsxv1alpha1.ConnectionOptions, optsPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if opts.ClientCertificate != nil && opts.ClientCertificate.Name == "" {
+		allErrs = append(allErrs, field.Required(optsPath.Child("clientCertificate", "name"), "name is required"))
+	}
+	if opts.CABundle != nil {
+		allErrs = append(allErrs, validateKeySelector(opts.CABundle.Name, opts.CABundle.Key, optsPath.Child("caBundle"))...)
+	}
+	if timeout := opts.RequestTimeout; timeout != nil && timeout.Duration < 0 {
+		allErrs = append(allErrs, field.Invalid(optsPath.Child("requestTimeout"), timeout.Duration.String(),
+			"request timeout should not be negative"))
+	}
+	return allErrs
+}
+
+func validateSynthesisOptions(opts *nsxv1alpha1.SynthesisOptions, optsPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for i, hint := range opts.DisjointHints {
+		tags := strings.Split(hint, ",")
+		if len(tags) < 2 || slicesContainEmpty(tags) {
+			allErrs = append(allErrs, field.Invalid(optsPath.Child("disjointHints").Index(i), hint,
+				"a hint should be a comma separated list of at least two groups/tags"))
+		}
+	}
+	for i, vm := range opts.VMs {
+		if strings.TrimSpace(vm) == "" {
+			allErrs = append(allErrs, field.Invalid(optsPath.Child("vms").Index(i), vm, "vm name should not be empty"))
+		}
+	}
+	if isPhased(opts) && len(opts.VMs) > 0 {
+		allErrs = append(allErrs, field.Forbidden(optsPath.Child("vms"), "filtering by vms is not supported in a phased migration"))
+	}
+	// the runner validates the values of enum options
+	if _, err := runner.NewRunnerWithOptionsList(controller.SynthesisRunnerOptions(opts)...); err != nil {
+		allErrs = append(allErrs, field.Invalid(optsPath, opts, err.Error()))
+	}
+	return allErrs
+}
+
+func slicesContainEmpty(s []string) bool {
+	for _, str := range s {
+		if strings.TrimSpace(str) == "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,206 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1alpha1
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	nsxv1alpha1 "github.com/np-guard/vmware-analyzer-operator/api/v1alpha1"
+)
+
+var _ = Describe("NSXMigration Webhook", func() {
+	var (
+		obj       *nsxv1alpha1.NSXMigration
+		validator NSXMigrationCustomValidator
+		defaulter NSXMigrationCustomDefaulter
+		ctx       = context.Background()
+	)
+
+	BeforeEach(func() {
+		obj = &nsxv1alpha1.NSXMigration{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-resource", Namespace: "default"},
+		}
+		obj.Spec.Secret.Name = "nsx-credentials"
+		validator = NSXMigrationCustomValidator{ResourcesDumpRoot: "/data"}
+		defaulter = NSXMigrationCustomDefaulter{}
+	})
+
+	Context("When creating NSXMigration under Defaulting Webhook", func() {
+		It("Should apply defaults when the synthesis options are not set", func() {
+			By("calling the Default method to apply defaults")
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+			By("checking that the default values are set")
+			Expect(obj.Spec.Secret.Namespace).To(Equal("default"))
+			Expect(obj.Spec.DeletionPolicy).To(Equal(nsxv1alpha1.DeletionPolicyDelete))
+			Expect(obj.Spec.SynthesisOptions.EndpointsMapping).To(Equal(defaultEndpointsMapping))
+			Expect(obj.Spec.SynthesisOptions.SegmentsMapping).To(Equal(defaultSegmentsMapping))
+			Expect(obj.Spec.SynthesisOptions.PolicyOptimizationLevel).To(Equal(defaultPolicyOptimizationLevel))
+		})
+
+		It("Should not override the synthesis options which are set", func() {
+			obj.Spec.Secret.Namespace = "nsx"
+			obj.Spec.DeletionPolicy = nsxv1alpha1.DeletionPolicyOrphan
+			obj.Spec.SynthesisOptions.EndpointsMapping = "vms"
+			obj.Spec.SynthesisOptions.PolicyOptimizationLevel = "none"
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+			Expect(obj.Spec.Secret.Namespace).To(Equal("nsx"))
+			Expect(obj.Spec.DeletionPolicy).To(Equal(nsxv1alpha1.DeletionPolicyOrphan))
+			Expect(obj.Spec.SynthesisOptions.EndpointsMapping).To(Equal("vms"))
+			Expect(obj.Spec.SynthesisOptions.SegmentsMapping).To(Equal(defaultSegmentsMapping))
+			Expect(obj.Spec.SynthesisOptions.PolicyOptimizationLevel).To(Equal("none"))
+		})
+
+		It("Should default the policy name prefix to the name of a phased migration", func() {
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+			Expect(obj.Spec.SynthesisOptions.PolicyNamePrefix).To(BeEmpty())
+			obj.Name = "phase.1"
+			obj.Spec.SynthesisOptions.MigratedSegments = []string{"seg-1"}
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+			Expect(obj.Spec.SynthesisOptions.PolicyNamePrefix).To(Equal("phase-1"))
+		})
+	})
+
+	Context("When creating or updating NSXMigration under Validating Webhook", func() {
+		It("Should admit creation with valid synthesis options", func() {
+			obj.Spec.SynthesisOptions = nsxv1alpha1.SynthesisOptions{
+				SynthesizeAdminPolicies: true,
+				DisjointHints:           []string{"frontend,backend", "app,db,web"},
+				EndpointsMapping:        "pods",
+				SegmentsMapping:         "pod-network",
+				PolicyOptimizationLevel: "moderate",
+				VMs:                     []string{"vm1"},
+			}
+			Expect(validator.ValidateCreate(ctx, obj)).To(BeNil())
+		})
+
+		It("Should deny creation if the secret name is missing", func() {
+			obj.Spec.Secret.Name = ""
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.secret.name"))
+		})
+
+		It("Should admit creation with an NSX resources dump instead of a secret", func() {
+			obj.Spec.Secret = corev1.ObjectReference{}
+			obj.Spec.ResourcesDump = &nsxv1alpha1.ResourcesDumpSource{ConfigMap: &corev1.ConfigMapKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "nsx-dump"}, Key: "resources.json.gz"}}
+			Expect(validator.ValidateCreate(ctx, obj)).To(BeNil())
+		})
+
+		It("Should deny creation with both a secret and an NSX resources dump", func() {
+			obj.Spec.ResourcesDump = &nsxv1alpha1.ResourcesDumpSource{Path: "/data/resources.json"}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.secret"))
+		})
+
+		It("Should deny creation with an invalid NSX resources dump source", func() {
+			obj.Spec.Secret = corev1.ObjectReference{}
+			obj.Spec.ResourcesDump = &nsxv1alpha1.ResourcesDumpSource{Path: "resources.json",
+				Secret: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "nsx-dump"}}}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.resourcesDump.path"))
+			Expect(err.Error()).To(ContainSubstring("spec.resourcesDump.secret.key"))
+			Expect(err.Error()).To(ContainSubstring("exactly one of configMap, secret and path should be set"))
+		})
+
+		DescribeTable("Should admit an NSX resources dump file only under the resources dump root",
+			func(root, path string, admitted bool) {
+				validator.ResourcesDumpRoot = root
+				obj.Spec.Secret = corev1.ObjectReference{}
+				obj.Spec.ResourcesDump = &nsxv1alpha1.ResourcesDumpSource{Path: path}
+				_, err := validator.ValidateCreate(ctx, obj)
+				if admitted {
+					Expect(err).NotTo(HaveOccurred())
+				} else {
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("spec.resourcesDump.path"))
+				}
+			},
+			Entry("a file under the root", "/data", "/data/nsx/resources.json", true),
+			Entry("a file outside the root", "/data", "/var/run/secrets/kubernetes.io/serviceaccount/token", false),
+			Entry("a file of a sibling of the root", "/data", "/data-other/resources.json", false),
+			Entry("a path escaping the root", "/data", "/data/../etc/passwd", false),
+			Entry("a path with .. under the root", "/data", "/data/nsx/../resources.json", false),
+			Entry("no root", "", "/data/resources.json", false),
+		)
+
+		It("Should admit creation with NSX connection options", func() {
+			obj.Spec.Connection = nsxv1alpha1.ConnectionOptions{SessionAuth: true,
+				ClientCertificate: &corev1.LocalObjectReference{Name: "nsx-client-cert"},
+				CABundle: &corev1.ConfigMapKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "nsx-ca"}, Key: "ca.crt"},
+				RequestTimeout: &metav1.Duration{Duration: time.Minute}}
+			Expect(validator.ValidateCreate(ctx, obj)).To(BeNil())
+		})
+
+		It("Should deny creation with invalid NSX connection options", func() {
+			obj.Spec.Connection = nsxv1alpha1.ConnectionOptions{ClientCertificate: &corev1.LocalObjectReference{},
+				CABundle:       &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "nsx-ca"}},
+				RequestTimeout: &metav1.Duration{Duration: -time.Minute}}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.connection.clientCertificate.name"))
+			Expect(err.Error()).To(ContainSubstring("spec.connection.caBundle.key"))
+			Expect(err.Error()).To(ContainSubstring("spec.connection.requestTimeout"))
+		})
+
+		It("Should deny creation with an invalid disjoint hint", func() {
+			obj.Spec.SynthesisOptions.DisjointHints = []string{"frontend,backend", "frontend"}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.synthesisOptions.disjointHints[1]"))
+		})
+
+		It("Should deny creation with a non-positive resync interval", func() {
+			obj.Spec.ResyncInterval = &metav1.Duration{}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.resyncInterval"))
+		})
+
+		It("Should deny creation of a phased migration with an invalid policy name prefix", func() {
+			obj.Spec.SynthesisOptions.MigratedVMs = []string{"vm1"}
+			obj.Spec.SynthesisOptions.PolicyNamePrefix = "Phase_1"
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("invalid policy name prefix"))
+		})
+
+		It("Should deny creation of a phased migration filtered by vms", func() {
+			obj.Spec.SynthesisOptions.MigratedVMs = []string{"vm1"}
+			obj.Spec.SynthesisOptions.VMs = []string{"vm1"}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.synthesisOptions.vms"))
+		})
+
+		It("Should deny update with an invalid endpoints mapping", func() {
+			oldObj := obj.DeepCopy()
+			obj.Spec.SynthesisOptions.EndpointsMapping = "containers"
+			_, err := validator.ValidateUpdate(ctx, oldObj, obj)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.synthesisOptions"))
+		})
+	})
+})
@@ -1,6 +1,8 @@
 package analyzer
 
 import (
+	"fmt"
+
 	"github.com/np-guard/vmware-analyzer/internal/common"
 	"github.com/np-guard/vmware-analyzer/pkg/analyzer/connectivity"
 	"github.com/np-guard/vmware-analyzer/pkg/collector"
@@ -44,6 +46,14 @@ func NSXConnectivityFromResourcesContainer(resources *collector.ResourcesContain
 	}
 	connMap := computeConnectivity(config, params.VMs)
 	res, err := connMap.GenConnectivityOutput(params)
+	if err == nil && params.Format == common.TextFormat {
+		if userRules := config.FW.UserConditionalRulesReport(params.Color); userRules != "" {
+			res += fmt.Sprintf("\n\nUser-conditional (identity firewall) rules, not part of the connectivity between VMs:\n%s", userRules)
+		}
+		if partialGroups := config.PartiallyUnderstoodGroupsReport(params.Color); partialGroups != "" {
+			res += fmt.Sprintf("\n\n%s\n%s", configuration.PartiallyUnderstoodGroupsHeader, partialGroups)
+		}
+	}
 
 	rulesNotEvaluated := connMap.RulesNotEvaluated(config.FW.AllRulesIDs)
 	logging.Debugf("rules not evaluated:%v\n", rulesNotEvaluated)
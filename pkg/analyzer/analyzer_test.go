@@ -4,11 +4,15 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/np-guard/vmware-analyzer/internal/common"
+	"github.com/np-guard/vmware-analyzer/pkg/analyzer"
+	"github.com/np-guard/vmware-analyzer/pkg/collector"
+	nsx "github.com/np-guard/vmware-analyzer/pkg/configuration/generated"
 	"github.com/np-guard/vmware-analyzer/pkg/data"
 	"github.com/np-guard/vmware-analyzer/pkg/internal/projectpath"
 	"github.com/np-guard/vmware-analyzer/pkg/internal/test_utils"
@@ -63,6 +67,18 @@ var allTests = []analyzerTest{
 		name:   "ExampleAppWithGroupsAdditionalDropRule",
 		exData: data.ExampleAppWithGroupsAdditionalDropRule,
 	},
+	{
+		name:   "ExampleAppWithGroupsAdditionalRejectRule",
+		exData: data.ExampleAppWithGroupsAdditionalRejectRule,
+	},
+	{
+		name:   "ExampleStatelessPolicy",
+		exData: data.ExampleStatelessPolicy,
+	},
+	{
+		name:   "ExampleRedirection",
+		exData: data.ExampleRedirection,
+	},
 	{
 		name:   "ExampleAppWithGroupsAndSegments",
 		exData: data.ExampleAppWithGroupsAndSegments,
@@ -153,3 +169,55 @@ func getExpectedTestPath(name string) string {
 func getActualTestPath(name string) string {
 	return filepath.Join(projectpath.Root, "pkg", "analyzer", "tests_actual_output", name)
 }
+
+// validate that identity firewall rules are reported as user-conditional, and are not applied to VMs by their users
+func TestUserConditionalRules(t *testing.T) {
+	rc, err := data.ExamplesGeneration(data.ExampleStatelessPolicy, false)
+	require.Nil(t, err)
+	identityExpr := &collector.IdentityGroupExpression{
+		IdentityGroupExpression: nsx.IdentityGroupExpression{
+			IdentityGroups: []nsx.IdentityGroupInfo{{DistinguishedName: common.PointerTo("CN=frontend-users,DC=example,DC=com")}},
+			ResourceType:   common.PointerTo(nsx.IdentityGroupExpressionResourceTypeIdentityGroupExpression),
+		},
+	}
+	// the source of rule 1004 is a group of users only
+	usersGroup := collector.Group{Expression: collector.Expression{identityExpr}}
+	usersGroup.DisplayName = common.PointerTo("frontend-users")
+	usersGroup.Path = common.PointerTo("frontend-users")
+	resources := &rc.DomainList[0].Resources
+	resources.GroupList = append(resources.GroupList, usersGroup)
+	rule := &resources.SecurityPolicyList[0].Rules[0]
+	require.Equal(t, 1004, *rule.RuleId)
+	rule.SourceGroups = []string{"frontend-users"}
+
+	config, connMap, res, err := analyzer.NSXConnectivityFromResourcesContainer(rc, &common.OutputParameters{Format: common.TextFormat})
+	require.Nil(t, err)
+	userRules := config.FW.UserConditionalRules()
+	require.Len(t, userRules, 1)
+	require.Equal(t, 1004, userRules[0].RuleID)
+	require.Contains(t, res, "allowed for users in group frontend-users")
+	require.Contains(t, res, "CN=frontend-users,DC=example,DC=com")
+	// a rule with users only as sources is not ineffective
+	require.NotContains(t, config.FW.IneffectiveRulesReport(false), "1004")
+
+	// the SMB connection from A to B is allowed only for users in the identity group, not for VM A
+	isAllowed, ingress, egress := connMap.GetExplanationPerConnection("A", "B", newTCPWithPort(445))
+	require.False(t, isAllowed)
+	require.NotContains(t, slices.Concat(ingress, egress), 1004)
+
+	// the source of rule 1004 is a mixed group, of VM A and of users
+	rule.SourceGroups = []string{"frontend"}
+	i := slices.IndexFunc(resources.GroupList, func(g collector.Group) bool { return g.Name() == "frontend" })
+	require.NotEqual(t, -1, i)
+	resources.GroupList[i].Expression = append(resources.GroupList[i].Expression, identityExpr)
+
+	config, connMap, _, err = analyzer.NSXConnectivityFromResourcesContainer(rc, &common.OutputParameters{Format: common.TextFormat})
+	require.Nil(t, err)
+	require.Len(t, config.FW.UserConditionalRules(), 1)
+	// the VM members of the group are kept
+	isAllowed, _, _ = connMap.GetExplanationPerConnection("A", "B", newTCPWithPort(445))
+	require.True(t, isAllowed)
+	// as a destination, the group is still a group of VMs
+	isAllowed, _, _ = connMap.GetExplanationPerConnection("B", "A", newTCPWithPort(80))
+	require.True(t, isAllowed)
+}
@@ -34,7 +34,7 @@ func analyzeCategory(c *dfw.CategorySpec, src, dst topology.Endpoint, isIngress
 			case dfw.ActionAllow:
 				addedAllowedConns := rule.RuleObj.Conn.Subtract(deniedConns.accumulatedConns).Subtract(jumpToAppConns.accumulatedConns)
 				rulePartition := &connectivity.RuleAndConn{RuleID: rule.RuleObj.RuleID, Conn: addedAllowedConns.Subtract(allowedConns.accumulatedConns),
-					Action: dfw.ActionAllow}
+					Action: dfw.ActionAllow, Stateless: rule.RuleObj.IsStateless()}
 				allowedConns.accumulatedConns = allowedConns.accumulatedConns.Union(addedAllowedConns)
 				if !rulePartition.Conn.IsEmpty() {
 					allowedConns.partitionsByRules = append(allowedConns.partitionsByRules, rulePartition)
@@ -42,8 +42,9 @@ func analyzeCategory(c *dfw.CategorySpec, src, dst topology.Endpoint, isIngress
 
 			case dfw.ActionDeny:
 				addedDeniedConns := rule.RuleObj.Conn.Subtract(allowedConns.accumulatedConns).Subtract(jumpToAppConns.accumulatedConns)
+				// keep the original deny action (drop/reject) for explanations
 				rulePartition := &connectivity.RuleAndConn{RuleID: rule.RuleObj.RuleID, Conn: addedDeniedConns.Subtract(deniedConns.accumulatedConns),
-					Action: dfw.ActionDeny}
+					Action: rule.RuleObj.OrigAction}
 				deniedConns.accumulatedConns = deniedConns.accumulatedConns.Union(addedDeniedConns)
 				if !rulePartition.Conn.IsEmpty() {
 					deniedConns.partitionsByRules = append(deniedConns.partitionsByRules, rulePartition)
@@ -8,6 +8,7 @@ package connectivity
 
 import (
 	"fmt"
+	"slices"
 
 	"github.com/np-guard/models/pkg/netset"
 	"github.com/np-guard/vmware-analyzer/internal/common"
@@ -36,13 +37,18 @@ type Explanation struct {
 
 	NotDeterminedIngress *netset.TransportSet
 	NotDeterminedEgress  *netset.TransportSet
+
+	// RedirectExplanations holds the permitted connections which are redirected to a service chain, by redirection rules
+	RedirectExplanations []*RuleAndConn
 }
 
 // RuleAndConn contains a set of connections and a rule ID which is directly related to these connections
 type RuleAndConn struct {
 	Conn   *netset.TransportSet
 	RuleID int
-	Action dfw.RuleAction
+	Action dfw.RuleAction // for denied connections, the original action (drop or reject)
+	// Stateless is true for allowed connections by a rule of a stateless policy (responses are not allowed implicitly)
+	Stateless bool
 }
 
 //////////////////////////////////////////////////////////////////////
@@ -68,7 +74,11 @@ func (es *Explanation) String(connSet *netset.TransportSet) string {
 	ingress := common.JoinStringifiedSlice(ingressExplanationsFiltered, common.CommaSeparator)
 	egress := common.JoinStringifiedSlice(egressExplanationsFiltered, common.CommaSeparator)
 
-	return fmt.Sprintf("ingress: %s\negress: %s", ingress, egress)
+	res := fmt.Sprintf("ingress: %s\negress: %s", ingress, egress)
+	if redirectExplanationsFiltered := FilterExplanation(es.RedirectExplanations, connSet); len(redirectExplanationsFiltered) > 0 {
+		res += fmt.Sprintf("\nredirect: %s", common.JoinStringifiedSlice(redirectExplanationsFiltered, common.CommaSeparator))
+	}
+	return res
 }
 
 func (es *Explanation) RuleIDs() (ingress, egress []int) {
@@ -83,6 +93,96 @@ func (es *Explanation) RuleIDs() (ingress, egress []int) {
 	return ingress, egress
 }
 
+// RejectedConn returns the subset of the denied connections that are denied by reject rules
+// (on ingress or egress), for which the application is expected to get a fast TCP RST / ICMP unreachable
+// instead of a timeout
+func (d *DetailedConnection) RejectedConn() *netset.TransportSet {
+	res := netset.NoTransports()
+	deniedConn := netset.AllTransports().Subtract(d.Conn)
+	for _, r := range d.rejectExplanations(deniedConn) {
+		res = res.Union(r.Conn.Intersect(deniedConn))
+	}
+	return res
+}
+
+// RejectRuleIDs returns the IDs of reject rules related to the given connections
+func (d *DetailedConnection) RejectRuleIDs(connSet *netset.TransportSet) []int {
+	res := []int{}
+	for _, r := range d.rejectExplanations(connSet) {
+		res = append(res, r.RuleID)
+	}
+	slices.Sort(res)
+	return slices.Compact(res)
+}
+
+// rejectExplanations returns the reject rules related to the given connections, restricted to these connections.
+// Connections denied on egress never reach the ingress rules, thus an ingress reject rule is related only to
+// the connections that are not denied on egress.
+func (d *DetailedConnection) rejectExplanations(connSet *netset.TransportSet) []*RuleAndConn {
+	res := []*RuleAndConn{}
+	if d.ExplanationObj == nil {
+		return res
+	}
+	ingressConnSet := connSet
+	for _, r := range d.ExplanationObj.EgressExplanations {
+		if r.Action == dfw.ActionDeny || r.Action == dfw.ActionDrop || r.Action == dfw.ActionReject {
+			ingressConnSet = ingressConnSet.Subtract(r.Conn)
+		}
+		if conn := r.Conn.Intersect(connSet); r.Action == dfw.ActionReject && !conn.IsEmpty() {
+			res = append(res, &RuleAndConn{RuleID: r.RuleID, Conn: conn, Action: r.Action})
+		}
+	}
+	for _, r := range d.ExplanationObj.IngressExplanations {
+		if conn := r.Conn.Intersect(ingressConnSet); r.Action == dfw.ActionReject && !conn.IsEmpty() {
+			res = append(res, &RuleAndConn{RuleID: r.RuleID, Conn: conn, Action: r.Action})
+		}
+	}
+	return res
+}
+
+// StatelessConn returns the subset of the permitted connections that are allowed by rules of stateless policies
+// (on ingress or egress), for which the responses are not allowed implicitly
+func (d *DetailedConnection) StatelessConn() *netset.TransportSet {
+	res := netset.NoTransports()
+	if d.ExplanationObj == nil {
+		return res
+	}
+	for _, r := range slices.Concat(d.ExplanationObj.IngressExplanations, d.ExplanationObj.EgressExplanations) {
+		if r.Action == dfw.ActionAllow && r.Stateless {
+			res = res.Union(r.Conn.Intersect(d.Conn))
+		}
+	}
+	return res
+}
+
+// RedirectedConn returns the subset of the permitted connections that are redirected to a service chain
+// (partner service VMs such as IDS/IPS) by redirection rules
+func (d *DetailedConnection) RedirectedConn() *netset.TransportSet {
+	res := netset.NoTransports()
+	if d.ExplanationObj == nil {
+		return res
+	}
+	for _, r := range d.ExplanationObj.RedirectExplanations {
+		res = res.Union(r.Conn.Intersect(d.Conn))
+	}
+	return res
+}
+
+// RedirectRuleIDs returns the IDs of redirection rules related to the given connections
+func (d *DetailedConnection) RedirectRuleIDs(connSet *netset.TransportSet) []int {
+	res := []int{}
+	if d.ExplanationObj == nil {
+		return res
+	}
+	for _, r := range d.ExplanationObj.RedirectExplanations {
+		if !r.Conn.Intersect(connSet).IsEmpty() {
+			res = append(res, r.RuleID)
+		}
+	}
+	slices.Sort(res)
+	return slices.Compact(res)
+}
+
 func (rac *RuleAndConn) String() string {
 	return fmt.Sprintf("{conn: %s, ruleID: %d, action: %s}", rac.Conn.String(), rac.RuleID, rac.Action)
 }
@@ -94,7 +194,8 @@ func FilterExplanation(allExplanations []*RuleAndConn, connSet *netset.Transport
 			logging.FatalErrorf("unexpected nil entry in allExplanations []*RuleAndConn")
 		}
 		if !r.Conn.Intersect(connSet).IsEmpty() {
-			res = append(res, &RuleAndConn{RuleID: r.RuleID, Conn: r.Conn.Intersect(connSet), Action: r.Action})
+			res = append(res, &RuleAndConn{RuleID: r.RuleID, Conn: r.Conn.Intersect(connSet), Action: r.Action,
+				Stateless: r.Stateless})
 		}
 	}
 	return res
@@ -28,8 +28,80 @@ func (c ConnMap) GenConnectivityOutput(params *common.OutputParameters) (res str
 	if err != nil {
 		return res, err
 	}
+	if params.Format == common.TextFormat {
+		res += filteredConn.genRejectedConnectionsOutput(params.Color)
+		res += filteredConn.genBlockedResponsesOutput(params.Color)
+		res += filteredConn.genRedirectedConnectionsOutput(params.Color)
+	}
 	if params.Format == common.TextFormat && params.Explain {
 		res += c.genExplanationOutput()
 	}
 	return res, nil
 }
+
+// genRejectedConnectionsOutput returns a table of denied connections that are rejected rather than dropped
+// (empty if there are no such connections)
+func (c ConnMap) genRejectedConnectionsOutput(color bool) string {
+	header := []string{"Source", "Destination", "Rejected connections", "Reject rules IDs"}
+	lines := [][]string{}
+	for _, e := range c.toSlice() {
+		rejected := e.DetailedConn.RejectedConn()
+		if rejected.IsEmpty() {
+			continue
+		}
+		lines = append(lines, []string{e.Src.Name(), e.Dst.Name(), rejected.String(),
+			fmt.Sprintf("%v", e.DetailedConn.RejectRuleIDs(rejected))})
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("\n\nRejected connections (denied with TCP RST / ICMP unreachable instead of drop):\n%s",
+		common.GenerateTableString(header, lines, &common.TableOptions{SortLines: true, Colors: color}))
+}
+
+// genBlockedResponsesOutput returns a table of connections permitted by rules of stateless policies, for which
+// the response traffic is not permitted in the reverse direction (empty if there are no such connections)
+func (c ConnMap) genBlockedResponsesOutput(color bool) string {
+	header := []string{"Source", "Destination", "One-directional connections"}
+	lines := [][]string{}
+	for _, e := range c.toSlice() {
+		stateless := e.DetailedConn.StatelessConn().TCPUDPSet()
+		if stateless.IsEmpty() {
+			continue
+		}
+		responses := stateless.SwapPorts()
+		if reverse, ok := c[e.Dst][e.Src]; ok {
+			responses = responses.Subtract(reverse.Conn.TCPUDPSet())
+		}
+		if responses.IsEmpty() {
+			continue
+		}
+		// report the request connections whose responses are blocked
+		lines = append(lines, []string{e.Src.Name(), e.Dst.Name(), responses.SwapPorts().String()})
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("\n\nOne-directional connections (allowed by stateless policies, responses are blocked):\n%s",
+		common.GenerateTableString(header, lines, &common.TableOptions{SortLines: true, Colors: color}))
+}
+
+// genRedirectedConnectionsOutput returns a table of permitted connections that are redirected to a service chain
+// by redirection rules (empty if there are no such connections)
+func (c ConnMap) genRedirectedConnectionsOutput(color bool) string {
+	header := []string{"Source", "Destination", "Redirected connections", "Redirection rules IDs"}
+	lines := [][]string{}
+	for _, e := range c.toSlice() {
+		redirected := e.DetailedConn.RedirectedConn()
+		if redirected.IsEmpty() {
+			continue
+		}
+		lines = append(lines, []string{e.Src.Name(), e.Dst.Name(), redirected.String(),
+			fmt.Sprintf("%v", e.DetailedConn.RedirectRuleIDs(redirected))})
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("\n\nRedirected connections (permitted connections that go through a service chain):\n%s",
+		common.GenerateTableString(header, lines, &common.TableOptions{SortLines: true, Colors: color}))
+}
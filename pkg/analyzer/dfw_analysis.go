@@ -23,8 +23,32 @@ func dfwAllowedConnections(d *dfw.DFW, src, dst topology.Endpoint) *connectivity
 	logging.Debug2f("egressDenied: %s", egressDenied.String())
 	logging.Debug2f("egressDelegated: %s", egressDelegated.String())
 
-	return buildDetailedConnection(ingressAllowed, egressAllowed, ingressDenied,
+	res := buildDetailedConnection(ingressAllowed, egressAllowed, ingressDenied,
 		egressDenied, ingressDelegated, egressDelegated, ingressNotDeterminedConns, egressNotDeterminedConns)
+	res.ExplanationObj.RedirectExplanations = redirectedConnections(d, src, dst, res.Conn)
+	return res
+}
+
+// redirectedConnections returns the partition of the given permitted connections from src to dst, by the redirection
+// rules which redirect them to a service chain. The first redirection rule that captures a connection determines
+// whether it is redirected or not.
+func redirectedConnections(d *dfw.DFW, src, dst topology.Endpoint, permitted *netset.TransportSet) []*connectivity.RuleAndConn {
+	res := []*connectivity.RuleAndConn{}
+	remaining := permitted
+	for _, rule := range d.RedirectionRules {
+		if remaining.IsEmpty() {
+			break
+		}
+		if !rule.CapturesPair(src, dst) {
+			continue
+		}
+		captured := rule.Conn.Intersect(remaining)
+		remaining = remaining.Subtract(rule.Conn)
+		if rule.Redirect && !captured.IsEmpty() {
+			res = append(res, &connectivity.RuleAndConn{RuleID: rule.RuleID, Conn: captured, Action: dfw.ActionRedirect})
+		}
+	}
+	return res
 }
 
 func buildDetailedConnection(ingressAllowed, egressAllowed, ingressDenied, egressDenied,
@@ -24,6 +24,7 @@ const (
 	resourceDumpFileFlag          = "resource-dump-file"
 	topologyDumpFileFlag          = "topology-dump-file"
 	anonymizeFlag                 = "anonymize"
+	anonymizationKeyFlag          = "anonymization-key"
 	synthesisDirFlag              = "synthesis-dir"
 	synthesizeAdminPoliciesFlag   = "synthesize-admin-policies"
 	logFileFlag                   = "log-file"
@@ -43,7 +44,27 @@ const (
 	endpointsMappingFlag          = "endpoints-mapping"
 	segmentsMappingFlag           = "segments-mapping"
 	policyOptimizationLevelFlag   = "policy-optimization-level"
+	migratedVMsFlag               = "migrated-vms"
+	migratedSegmentsFlag          = "migrated-segments"
+	policyNamePrefixFlag          = "policy-name-prefix"
+	anpPriorityOffsetFlag         = "admin-policy-priority-offset"
+	serverAddressFlag             = "address"
+	maxConfigsFlag                = "max-configs"
+	pageSizeFlag                  = "page-size"
 	logLevelFlag                  = "log-level"
+	nsxRecordDirFlag              = "nsx-record-dir"
+	nsxReplayDirFlag              = "nsx-replay-dir"
+	nsxSessionAuthFlag            = "nsx-session-auth"
+	nsxClientCertFlag             = "nsx-client-cert"
+	nsxClientKeyFlag              = "nsx-client-key"
+	nsxCACertFlag                 = "nsx-ca-cert"
+	nsxRequestTimeoutFlag         = "nsx-request-timeout"
+	nsxMaxRetriesFlag             = "nsx-max-retries"
+	continueOnErrorFlag           = "continue-on-error"
+	vcenterFlag                   = "vcenter"
+	vmSpecsFlag                   = "vm-specs"
+	vcenterUserFlag               = "vcenter-username"
+	vcenterPasswordFlag           = "vcenter-password"
 
 	resourceInputFileHelp = "file path input JSON of NSX resources (instead of collecting from NSX host)"
 	hostHelp              = "NSX host URL. Alternatively, set the host via the NSX_HOST environment variable"
@@ -57,6 +78,8 @@ const (
 	outputFileHelp        = "file path to store analysis results"
 	explainHelp           = "flag to explain connectivity output with rules explanations per " +
 		"allowed/denied connections (default false)"
+	anonymizationKeyHelp = "key of the prefix-preserving anonymization of IP addresses, the same key anonymizes " +
+		"the addresses of different collections identically (default: a random key)"
 	synthesisDirHelp              = "run synthesis; specify directory path to store target synthesis resources"
 	synthesizeAdminPoliciesHelp   = "include admin network policies in policy synthesis (default false)"
 	outputFormatHelp              = "output format" + mustBeOneOf
@@ -66,8 +89,8 @@ const (
 	colorHelp                     = "flag to enable color output (default false)"
 	createDNSPolicyHelp           = "flag to create a policy allowing access to target env dns pod (default false)"
 	synthHelp                     = "flag to run synthesis, even if synthesis-dir is not specified"
-	disableInsecureSkipVerifyHelp = "flag to disable NSX connection retry with insecureSkipVerify (default false)." +
-		"Alternatively, set the NSX_DISABLE_SKIP_VERIFY environment variable to true"
+	disableInsecureSkipVerifyHelp = "flag to disable NSX connection retry with insecureSkipVerify, and to verify the vCenter certificate" +
+		" (default false).Alternatively, set the NSX_DISABLE_SKIP_VERIFY environment variable to true"
 	disjointHintsHelp = "comma separated list of NSX groups/tags that are always disjoint in their VM members," +
 		" needed for an effective and sound synthesis process, can specify more than one hint" +
 		" (example: \"--" + disjointHintsFlag + " frontend,backend --" + disjointHintsFlag + " app,web,db\")"
@@ -77,4 +100,29 @@ const (
 	segmentsMappingHelp         = "flag to set target mapping from segments; must be one of "
 	logLevelHelp                = "flag to set log level" + mustBeOneOf
 	policyOptimizationLevelHelp = "flag to set policy optimization level" + mustBeOneOf
+	migratedVMsHelp             = "phased migration: names of the vms migrated so far, other vms remain on NSX (example: \"vm1,vm2\")"
+	migratedSegmentsHelp        = "phased migration: names of the segments whose vms were migrated so far (example: \"seg1,seg2\")"
+	policyNamePrefixHelp        = "prefix for the names of generated policies, to avoid name conflicts between migration phases"
+	anpPriorityOffsetHelp       = "offset of the priorities of generated admin network policies, to avoid conflicts between migration phases"
+	serverAddressHelp           = "address for the server to listen on"
+	maxConfigsHelp              = "max number of uploaded NSX configs kept by the server, with their cached results"
+	pageSizeHelp                = "max number of results per page of the simulated NSX API list endpoints"
+	nsxRecordDirHelp            = "directory path to record the NSX REST API requests and responses to, for replaying them later"
+	nsxReplayDirHelp            = "directory path of recorded NSX REST API requests and responses, to collect from instead of NSX host"
+	nsxSessionAuthHelp          = "flag to authenticate to NSX host by a session created with the username and password, " +
+		"instead of basic auth per request (default false)"
+	nsxClientCertHelp     = "file path of a PEM client certificate to authenticate to NSX host, e.g. of a principal identity"
+	nsxClientKeyHelp      = "file path of the PEM private key of the NSX client certificate"
+	nsxCACertHelp         = "file path of PEM CA certificates to verify the NSX host certificate by, instead of the system CAs"
+	nsxRequestTimeoutHelp = "timeout of each request to NSX host (0 for no timeout)"
+	nsxMaxRetriesHelp     = "max number of retries of a request to NSX host responding with status 429 or 503, " +
+		"waiting by its Retry-After header"
+	continueOnErrorHelp = "flag to continue collecting from NSX host upon failures to collect individual groups and policies, " +
+		"recording them as collection warnings in the collected resources (default false)"
+	vcenterHelp = "vCenter host URL, to enrich the collected NSX VMs with their vCenter inventory data " +
+		"(guest OS, CPU/memory, power state, port groups, folder and custom attributes)"
+	vmSpecsHelp = "flag to generate VirtualMachine specs with CPU/memory, run strategy, interfaces with preserved MAC addresses " +
+		"and placeholder DataVolumes, instead of only the labels required by the generated policies (default false)"
+	vcenterUserHelp     = "vCenter username"
+	vcenterPasswordHelp = "vCenter password" // #nosec G101
 )
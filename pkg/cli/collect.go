@@ -20,5 +20,6 @@ func newCommandCollect() *cobra.Command {
 
 	// add flags
 	c.PersistentFlags().BoolVar(&args.Anonymize, anonymizeFlag, false, anonymizeHelp)
+	c.PersistentFlags().StringVar(&args.AnonymizationKey, anonymizationKeyFlag, "", anonymizationKeyHelp)
 	return c
 }
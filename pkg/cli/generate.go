@@ -28,6 +28,11 @@ func newCommandGenerate() *cobra.Command {
 	c.PersistentFlags().Var(&args.SegmentsMapping, segmentsMappingFlag, segmentsMappingHelp+common.AllSegmentOptionsStr)
 	c.PersistentFlags().Var(&args.PolicyOptimizationLevel, policyOptimizationLevelFlag,
 		policyOptimizationLevelHelp+common.AllPolicyOptimizationLevelsStr)
+	c.PersistentFlags().StringSliceVar(&args.MigratedVMs, migratedVMsFlag, nil, migratedVMsHelp)
+	c.PersistentFlags().StringSliceVar(&args.MigratedSegments, migratedSegmentsFlag, nil, migratedSegmentsHelp)
+	c.PersistentFlags().StringVar(&args.PolicyNamePrefix, policyNamePrefixFlag, "", policyNamePrefixHelp)
+	c.PersistentFlags().IntVar(&args.ANPPriorityOffset, anpPriorityOffsetFlag, 0, anpPriorityOffsetHelp)
+	c.PersistentFlags().BoolVar(&args.VMSpecs, vmSpecsFlag, false, vmSpecsHelp)
 
 	return c
 }
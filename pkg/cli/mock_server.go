@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/np-guard/vmware-analyzer/internal/common"
+	"github.com/np-guard/vmware-analyzer/pkg/collector"
+	"github.com/np-guard/vmware-analyzer/pkg/logging"
+	"github.com/np-guard/vmware-analyzer/pkg/nsxmock"
+)
+
+const (
+	defaultMockServerAddress = ":8443"
+	defaultPageSize          = 1000
+)
+
+func newCommandMockServer() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "mock-server",
+		Short: "Serve a simulated NSX manager API from input NSX config",
+		Long: `Serve a simulated NSX manager REST API from input NSX config, for testing without an NSX manager:
+the endpoints used for collecting NSX resources, and traceflows with results computed by the connectivity analysis`,
+		Example: `  # Simulate the NSX manager of an NSX config, and collect from it
+	nsxanalyzer mock-server -r config.json --address :8443
+	nsxanalyzer collect --host http://localhost:8443 --username user --password password --resource-dump-file collected.json`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runMockServer(args)
+		},
+	}
+
+	c.Flags().StringVar(&args.ServerAddress, serverAddressFlag, defaultMockServerAddress, serverAddressHelp)
+	c.Flags().IntVar(&args.PageSize, pageSizeFlag, defaultPageSize, pageSizeHelp)
+	return c
+}
+
+func runMockServer(args *inArgs) error {
+	if args.ResourceInputFile == "" {
+		return fmt.Errorf("%s --%s", common.ErrMissingRquiredArg, resourceInputFileFlag)
+	}
+	if err := logging.Init(args.LogLevel, args.LogFile); err != nil {
+		return err
+	}
+	b, err := os.ReadFile(args.ResourceInputFile)
+	if err != nil {
+		return err
+	}
+	resources, err := collector.FromJSONString(b)
+	if err != nil {
+		return err
+	}
+	h, err := nsxmock.NewHandler(resources, nsxmock.WithPageSize(args.PageSize))
+	if err != nil {
+		return err
+	}
+	return h.ListenAndServe(args.ServerAddress)
+}
@@ -39,12 +39,28 @@ and generation of k8s network policies. It uses REST API calls from NSX manager.
 	c.PersistentFlags().BoolVar(&args.DisableInsecureSkipVerify, disableInsecureSkipVerifyFlag, false, disableInsecureSkipVerifyHelp)
 	c.PersistentFlags().StringVar(&args.ResourceDumpFile, resourceDumpFileFlag, "", resourceDumpFileHelp)
 	c.PersistentFlags().Var(&args.LogLevel, logLevelFlag, logLevelHelp+common.AllLogLevelOptionsStr)
+	c.PersistentFlags().StringVar(&args.NSXRecordDir, nsxRecordDirFlag, "", nsxRecordDirHelp)
+	c.PersistentFlags().StringVar(&args.NSXReplayDir, nsxReplayDirFlag, "", nsxReplayDirHelp)
+	c.MarkFlagsMutuallyExclusive(nsxRecordDirFlag, nsxReplayDirFlag)
+	c.PersistentFlags().BoolVar(&args.NSXSessionAuth, nsxSessionAuthFlag, false, nsxSessionAuthHelp)
+	c.PersistentFlags().StringVar(&args.NSXClientCertFile, nsxClientCertFlag, "", nsxClientCertHelp)
+	c.PersistentFlags().StringVar(&args.NSXClientKeyFile, nsxClientKeyFlag, "", nsxClientKeyHelp)
+	c.MarkFlagsRequiredTogether(nsxClientCertFlag, nsxClientKeyFlag)
+	c.PersistentFlags().StringVar(&args.NSXCACertFile, nsxCACertFlag, "", nsxCACertHelp)
+	c.PersistentFlags().DurationVar(&args.NSXRequestTimeout, nsxRequestTimeoutFlag, defaultNSXRequestTimeout, nsxRequestTimeoutHelp)
+	c.PersistentFlags().IntVar(&args.NSXMaxRetries, nsxMaxRetriesFlag, common.DefaultNSXMaxRetries, nsxMaxRetriesHelp)
+	c.PersistentFlags().BoolVar(&args.ContinueOnError, continueOnErrorFlag, false, continueOnErrorHelp)
+	c.PersistentFlags().StringVar(&args.VCenterHost, vcenterFlag, "", vcenterHelp)
+	c.PersistentFlags().StringVar(&args.VCenterUser, vcenterUserFlag, "", vcenterUserHelp)
+	c.PersistentFlags().StringVar(&args.VCenterPassword, vcenterPasswordFlag, "", vcenterPasswordHelp)
 
 	// add sub-commands
 	c.AddCommand(newCommandCollect())
 	c.AddCommand(newCommandAnalyze())
 	c.AddCommand(newCommandGenerate())
 	c.AddCommand(newCommandLint())
+	c.AddCommand(newCommandServe())
+	c.AddCommand(newCommandMockServer())
 
 	return c
 }
@@ -1,9 +1,22 @@
 package cli
 
-import "github.com/np-guard/vmware-analyzer/pkg/runner"
+import (
+	"context"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/np-guard/vmware-analyzer/pkg/runner"
+)
+
+const defaultNSXRequestTimeout = 2 * time.Minute
 
 func runCommand(args *inArgs, cmd string) error {
+	// interrupting the command cancels the requests to NSX host
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
 	runnerObj, err := runner.NewRunnerWithOptionsList(
+		runner.WithContext(ctx),
 		runner.WithCmd(cmd),
 		runner.WithOutputFormat(args.OutputFormat.String()),
 		runner.WithOutputColor(args.Color),
@@ -16,7 +29,17 @@ func runCommand(args *inArgs, cmd string) error {
 		runner.WithNSXPassword(args.Password),
 		runner.WithResourcesDumpFile(args.ResourceDumpFile),
 		runner.WithResourcesAnonymization(args.Anonymize),
+		runner.WithAnonymizationKey(args.AnonymizationKey),
 		runner.WithResourcesInputFile(args.ResourceInputFile),
+		runner.WithNSXRecordDir(args.NSXRecordDir),
+		runner.WithNSXReplayDir(args.NSXReplayDir),
+		runner.WithNSXSessionAuth(args.NSXSessionAuth),
+		runner.WithNSXClientCertificate(args.NSXClientCertFile, args.NSXClientKeyFile),
+		runner.WithNSXCACertificate(args.NSXCACertFile),
+		runner.WithNSXRequestTimeout(args.NSXRequestTimeout),
+		runner.WithNSXMaxRetries(args.NSXMaxRetries),
+		runner.WithContinueOnError(args.ContinueOnError),
+		runner.WithVCenter(args.VCenterHost, args.VCenterUser, args.VCenterPassword),
 		runner.WithTopologyDumpFile(args.TopologyDumpFile),
 		runner.WithAnalysisOutputFile(args.OutputFile),
 		runner.WithAnalysisExplain(args.Explain),
@@ -30,6 +53,11 @@ func runCommand(args *inArgs, cmd string) error {
 		runner.WithEndpointsMapping(args.EndpointsMapping.String()),
 		runner.WithInferHints(args.InferDisjointHints),
 		runner.WithPolicyOptimizationLevel(args.PolicyOptimizationLevel.String()),
+		runner.WithMigratedVMs(args.MigratedVMs),
+		runner.WithMigratedSegments(args.MigratedSegments),
+		runner.WithPolicyNamePrefix(args.PolicyNamePrefix),
+		runner.WithANPPriorityOffset(args.ANPPriorityOffset),
+		runner.WithVMSpecs(args.VMSpecs),
 	)
 	if err != nil {
 		return err
@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/np-guard/vmware-analyzer/pkg/logging"
+	"github.com/np-guard/vmware-analyzer/pkg/runner"
+	"github.com/np-guard/vmware-analyzer/pkg/server"
+)
+
+const (
+	defaultServerAddress = ":8080"
+	defaultMaxConfigs    = 16
+)
+
+func newCommandServe() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve an HTTP JSON API for analysis, lint and generation over uploaded NSX configs",
+		Example: `  # Serve the API, upload an NSX config and generate OCP-Virt resources from it
+	nsxanalyzer serve --address :8080
+	curl -X POST --data-binary @config.json localhost:8080/api/v1/configs
+	curl "localhost:8080/api/v1/configs/<id>/generate?synthesize-admin-policies=true"`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runServer(args)
+		},
+	}
+
+	c.PersistentFlags().StringVar(&args.ServerAddress, serverAddressFlag, defaultServerAddress, serverAddressHelp)
+	c.PersistentFlags().IntVar(&args.MaxConfigs, maxConfigsFlag, defaultMaxConfigs, maxConfigsHelp)
+	return c
+}
+
+func runServer(args *inArgs) error {
+	if args.MaxConfigs < 1 {
+		return fmt.Errorf("invalid %s %d: should be positive", maxConfigsFlag, args.MaxConfigs)
+	}
+	if err := logging.Init(args.LogLevel, args.LogFile); err != nil {
+		return err
+	}
+	s := server.NewServer(
+		server.WithMaxConfigs(args.MaxConfigs),
+		server.WithRunnerOptions(
+			runner.WithHighVerbosity(args.Verbose),
+			runner.WithQuietVerbosity(args.Quiet),
+			runner.WithLogFile(args.LogFile),
+			runner.WithLogLevel(args.LogLevel.String()),
+		),
+	)
+	return s.ListenAndServe(args.ServerAddress)
+}
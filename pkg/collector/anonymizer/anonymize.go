@@ -13,8 +13,12 @@ import (
 // anonymize() is top function of the anonymization algorithm.
 // the anonymization is calling the iterate() function several time to do the anonymization.
 
-func anonymize(st structInstance, anonInstructions []*anonInstruction) error {
-	anonymizer := newAnonymizer()
+func anonymize(st structInstance, anonInstructions []*anonInstruction, ipKey string) error {
+	ipAnonymizer, err := newIPAnonymizer(ipKey)
+	if err != nil {
+		return err
+	}
+	anonymizer := newAnonymizer(ipAnonymizer)
 	for _, anonInstruction := range anonInstructions {
 		anonymizer.setAnonInstruction(anonInstruction)
 		if err := iterate(st, anonymizer, collectIDsToKeep, toAnonymizeFilter); err != nil {
@@ -41,6 +45,9 @@ func anonymize(st structInstance, anonInstructions []*anonInstruction) error {
 		if err := iterate(st, anonymizer, anonymizeFieldsByRef, toAnonymizeFilter); err != nil {
 			return err
 		}
+		if err := iterate(st, anonymizer, anonymizeValues, toAnonymizeFilter); err != nil {
+			return err
+		}
 	}
 	logging.Debugf("anonymization statistics:\n%s\n", anonymizer.statistics.string())
 	return nil
@@ -64,6 +71,9 @@ func anonymizeFields(user iteratorUser, structInstance structInstance) error {
 func anonymizeFieldsByRef(user iteratorUser, structInstance structInstance) error {
 	return user.(*anonymizer).anonymizeFieldsByRef(structInstance)
 }
+func anonymizeValues(user iteratorUser, structInstance structInstance) error {
+	return user.(*anonymizer).anonymizeValues(structInstance)
+}
 func collectPaths(user iteratorUser, structInstance structInstance) error {
 	return user.(*anonymizer).collectPaths(structInstance)
 }
@@ -7,22 +7,20 @@ SPDX-License-Identifier: Apache-2.0
 package anonymizer
 
 import (
-	"net"
+	nsx "github.com/np-guard/vmware-analyzer/pkg/configuration/generated"
 )
 
-const validIPPrefix = 192
-
-func AnonymizeNsx(st structInstance) error {
-	return anonymize(st, []*anonInstruction{&inst, &instForAddressMembers})
-}
+const (
+	conditionStructName     = "Condition"
+	conditionKeyField       = "Key"
+	conditionValueField     = "Value"
+	conditionValueSeparator = "|" // separates the scope and the tag in the value of a tag condition
+)
 
-func ipAddressFilter(ip string) bool {
-	ip4 := net.ParseIP(ip).To4()
-	return ip4 == nil || ip4[0] != validIPPrefix
-}
-func cidrFilter(cidr string) bool {
-	ip, _, err := net.ParseCIDR(cidr)
-	return err != nil || ip.To4() == nil || ip.To4()[0] != validIPPrefix
+// AnonymizeNsx anonymizes the NSX resources. The ip addresses are anonymized by a prefix-preserving permutation keyed by ipKey,
+// so the same key anonymizes the addresses of different collections identically; an empty ipKey stands for a random key
+func AnonymizeNsx(st structInstance, ipKey string) error {
+	return anonymize(st, []*anonInstruction{&inst, &instForAddressMembers}, ipKey)
 }
 
 //revive:disable // these are the fields names
@@ -32,7 +30,6 @@ var inst anonInstruction = anonInstruction{
 	},
 	structsToSkip: []string{
 		"ServiceEntry",
-		"Expression",
 	},
 	refStructs: map[string]string{
 		"RealizedVirtualMachine": "VirtualMachine",
@@ -83,21 +80,27 @@ var inst anonInstruction = anonInstruction{
 		"MacAddress",
 	},
 
-	fieldsByCondition: []conditionField{
-		{"DefaultGateway", ipAddressFilter},
-		{"GatewayAddress", cidrFilter},
-		{"Network", cidrFilter},
-		{"RdAdminField", ipAddressFilter},
+	ipFields: []string{
+		"DefaultGateway",
+		"GatewayAddress",
+		"Network",
+		"RdAdminField",
 	},
 
-	slicesByCondition: []conditionField{
-		{"IpAddresses", ipAddressFilter},
-		{"DnsServers", ipAddressFilter},
-		{"OptimizedIps", ipAddressFilter},
-		{"DhcpRanges", ipAddressFilter},
-		{"TransitSubnets", ipAddressFilter},
-		{"InternalTransitSubnets", ipAddressFilter},
-		{"VrfTransitSubnets", ipAddressFilter},
+	ipSliceFields: []string{
+		"IpAddresses",
+		"DnsServers",
+		"OptimizedIps",
+		"DhcpRanges",
+		"TransitSubnets",
+		"InternalTransitSubnets",
+		"VrfTransitSubnets",
+		"SourceGroups",
+		"DestinationGroups",
+	},
+	ipRangeSplitFields: []string{
+		"SourceGroups",
+		"DestinationGroups",
 	},
 	fieldsByRef: []byRefField{
 		{"TargetDisplayName", "TargetId", "DisplayName"},
@@ -119,6 +122,8 @@ var inst anonInstruction = anonInstruction{
 	pathSliceFields: []string{
 		"SourceGroups",
 		"DestinationGroups",
+		"Scope",
+		"Paths",
 	},
 	pathToCleanFields: []string{
 		"BridgeProfilePath",
@@ -141,13 +146,26 @@ var inst anonInstruction = anonInstruction{
 		"/infra/realized-state",
 		"ANY",
 	},
+	valueFields: []structField{
+		{"Tag", "Scope"},
+		{"Tag", "Tag"},
+	},
+	conditionValues: []conditionValue{
+		{string(nsx.ConditionKeyTag), []structField{{"Tag", "Scope"}, {"Tag", "Tag"}}},
+		// names refer to the anonymized names of VMs and computers; os names are not anonymized
+		{string(nsx.ConditionKeyName), []structField{{"VirtualMachine", "DisplayName"}}},
+		{string(nsx.ConditionKeyComputerName), []structField{{"GuestInfo", "ComputerName"}}},
+	},
 }
 
 var instForAddressMembers anonInstruction = anonInstruction{
 	structsNotToSkip: []string{
 		"Group",
 	},
-	slicesByCondition: []conditionField{
-		{"AddressMembers", ipAddressFilter},
+	ipSliceFields: []string{
+		"AddressMembers",
+	},
+	ipRangeSplitFields: []string{
+		"AddressMembers",
 	},
 }
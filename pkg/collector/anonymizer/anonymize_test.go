@@ -8,9 +8,16 @@ package anonymizer
 
 import (
 	"fmt"
+	"math/bits"
+	"net/netip"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/np-guard/vmware-analyzer/internal/common"
+	"github.com/np-guard/vmware-analyzer/pkg/collector"
+	nsx "github.com/np-guard/vmware-analyzer/pkg/configuration/generated"
 )
 
 type structA struct {
@@ -68,7 +75,7 @@ func Test_anonymize(t *testing.T) {
 		DisplayName: createUniqString(),
 		Path:        &path,
 	}
-	err := AnonymizeNsx(sa)
+	err := AnonymizeNsx(sa, "")
 	require.Equal(t, nil, err)
 	saID := "structA.Id:10000"
 	bID := "structB.Id:10003"
@@ -79,3 +86,166 @@ func Test_anonymize(t *testing.T) {
 	require.Equal(t, fmt.Sprintf("/infra/As/%s/Bs/%s", saID, bID), *sa.BAsSlices[0].Path)
 	require.Equal(t, (*string)(nil), sa.BAsSlices[1].TargetId)
 }
+
+type taggedResources struct {
+	VMs      []collector.VirtualMachine
+	Segments []collector.Segment
+	Groups   []collector.Group
+}
+
+func tagCondition(value string, operator nsx.ConditionOperator) *collector.Condition {
+	return &collector.Condition{Condition: nsx.Condition{
+		Key:        common.PointerTo(nsx.ConditionKeyTag),
+		MemberType: common.PointerTo(nsx.ConditionMemberTypeVirtualMachine),
+		Operator:   &operator,
+		Value:      &value,
+	}}
+}
+
+func Test_anonymizeTags(t *testing.T) {
+	vm := collector.VirtualMachine{}
+	vm.ExternalId = common.PointerTo("vm1-id")
+	vm.DisplayName = common.PointerTo("payroll-db")
+	vm.Tags = []nsx.Tag{{Scope: "app", Tag: "payroll"}, {Tag: "db"}}
+	segment := collector.Segment{}
+	segment.Id = common.PointerTo("seg1-id")
+	segment.Tags = []nsx.Tag{{Scope: "app", Tag: "payroll"}}
+	nameCondition := func(value string, operator nsx.ConditionOperator) *collector.Condition {
+		return &collector.Condition{Condition: nsx.Condition{
+			Key:        common.PointerTo(nsx.ConditionKeyName),
+			MemberType: common.PointerTo(nsx.ConditionMemberTypeVirtualMachine),
+			Operator:   &operator,
+			Value:      &value,
+		}}
+	}
+	group := collector.Group{Expression: collector.Expression{
+		tagCondition("app|payroll", nsx.ConditionOperatorEQUALS),
+		&collector.ConjunctionOperator{ConjunctionOperator: nsx.ConjunctionOperator{
+			ConjunctionOperator: common.PointerTo(nsx.ConjunctionOperatorConjunctionOperatorOR),
+		}},
+		&collector.NestedExpression{Expressions: collector.Expression{
+			tagCondition("db", nsx.ConditionOperatorNOTEQUALS),
+		}},
+		nameCondition("payroll-db", nsx.ConditionOperatorEQUALS),
+		nameCondition("payroll", nsx.ConditionOperatorSTARTSWITH),
+	}}
+	group.Id = common.PointerTo("group1-id")
+	group.Tags = []nsx.Tag{{Tag: "db"}}
+	rc := &taggedResources{
+		VMs:      []collector.VirtualMachine{vm},
+		Segments: []collector.Segment{segment},
+		Groups:   []collector.Group{group},
+	}
+	require.Nil(t, AnonymizeNsx(rc, ""))
+
+	vmTags := rc.VMs[0].Tags
+	require.NotContains(t, []string{vmTags[0].Scope, vmTags[0].Tag, vmTags[1].Tag}, "app")
+	require.NotEqual(t, vmTags[0].Tag, vmTags[1].Tag)
+	require.Empty(t, vmTags[1].Scope)
+	// the same tag is anonymized to the same value in all resources and conditions
+	require.Equal(t, vmTags[0], rc.Segments[0].Tags[0])
+	require.Equal(t, vmTags[1], rc.Groups[0].Tags[0])
+	expr := rc.Groups[0].Expression
+	require.Equal(t, vmTags[0].Scope+"|"+vmTags[0].Tag, *expr[0].(*collector.Condition).Value)
+	require.Equal(t, vmTags[1].Tag, *expr[2].(*collector.NestedExpression).Expressions[0].(*collector.Condition).Value)
+	require.Equal(t, nsx.ConditionOperatorNOTEQUALS, *expr[2].(*collector.NestedExpression).Expressions[0].(*collector.Condition).Operator)
+	// a name condition refers to the anonymized name of the VM, a name which is not of a VM is anonymized as well
+	require.NotEqual(t, "payroll-db", *rc.VMs[0].DisplayName)
+	require.Equal(t, *rc.VMs[0].DisplayName, *expr[3].(*collector.Condition).Value)
+	require.NotContains(t, *expr[4].(*collector.Condition).Value, "payroll")
+	require.NotEqual(t, *rc.VMs[0].DisplayName, *expr[4].(*collector.Condition).Value)
+}
+
+func commonPrefixLen(a1, a2 netip.Addr) int {
+	b1, b2 := a1.AsSlice(), a2.AsSlice()
+	for i := range b1 {
+		if diff := b1[i] ^ b2[i]; diff != 0 {
+			return i*bitsInByte + bits.LeadingZeros8(diff)
+		}
+	}
+	return len(b1) * bitsInByte
+}
+
+func Test_ipAnonymizerPrefixPreserving(t *testing.T) {
+	ia, err := newIPAnonymizer("key")
+	require.Nil(t, err)
+	addrs := []string{"10.0.0.1", "10.0.0.2", "10.0.1.1", "10.240.3.4", "192.168.1.1", "8.8.8.8", "fd00::1", "fd00::1:1"}
+	for _, s1 := range addrs {
+		for _, s2 := range addrs {
+			a1, a2 := netip.MustParseAddr(s1), netip.MustParseAddr(s2)
+			if a1.Is4() != a2.Is4() {
+				continue
+			}
+			require.Equal(t, commonPrefixLen(a1, a2), commonPrefixLen(ia.anonymizeAddr(a1), ia.anonymizeAddr(a2)), "%s, %s", s1, s2)
+		}
+	}
+	// the same key anonymizes the same addresses identically
+	ia2, err := newIPAnonymizer("key")
+	require.Nil(t, err)
+	anon1, _ := ia.anonymizeIPElement("10.0.0.1")
+	anon2, _ := ia2.anonymizeIPElement("10.0.0.1")
+	require.Equal(t, anon1, anon2)
+
+	anonCidrs, ok := ia.anonymizeIPElements("10.0.0.0-10.0.0.127")
+	require.True(t, ok)
+	require.Len(t, anonCidrs, 1)
+	anonCidrs, ok = ia.anonymizeIPElements("10.0.0.1-10.0.0.6")
+	require.True(t, ok)
+	require.Len(t, anonCidrs, 4) // 10.0.0.1/32, 10.0.0.2/31, 10.0.0.4/31, 10.0.0.6/32
+	_, ok = ia.anonymizeIPElements("/infra/domains/default/groups/g1")
+	require.False(t, ok)
+}
+
+type addressedResources struct {
+	VNIs     []collector.VirtualNetworkInterface
+	Segments []collector.Segment
+	Rules    []collector.Rule
+	Groups   []collector.Group
+}
+
+func Test_anonymizeIPs(t *testing.T) {
+	vni := collector.VirtualNetworkInterface{}
+	vni.IpAddressInfo = []nsx.IpAddressInfo{{IpAddresses: []nsx.IPAddress{"10.0.1.5", "fd00::5"}}}
+	segment := collector.Segment{}
+	segment.Subnets = []nsx.SegmentSubnet{{Network: common.PointerTo("10.0.1.0/24"), GatewayAddress: common.PointerTo("10.0.1.1/24"),
+		DhcpRanges: []nsx.IPElement{"10.0.1.100-10.0.1.200"}}}
+	rule := collector.Rule{}
+	rule.SourceGroups = []string{"ANY"}
+	rule.DestinationGroups = []string{"10.0.0.0/16", "10.0.1.4-10.0.1.7", "ANY"}
+	group := collector.Group{AddressMembers: []nsx.IPElement{"10.0.1.5", "10.0.1.4-10.0.1.7"}}
+	rc := &addressedResources{
+		VNIs:     []collector.VirtualNetworkInterface{vni},
+		Segments: []collector.Segment{segment},
+		Rules:    []collector.Rule{rule},
+		Groups:   []collector.Group{group},
+	}
+	require.Nil(t, AnonymizeNsx(rc, "key"))
+
+	vmAddress := string(rc.VNIs[0].IpAddressInfo[0].IpAddresses[0])
+	require.NotEqual(t, "10.0.1.5", vmAddress)
+	require.Equal(t, vmAddress, string(rc.Groups[0].AddressMembers[0]))
+	subnet := netip.MustParsePrefix(*rc.Segments[0].Subnets[0].Network)
+	require.Equal(t, 24, subnet.Bits())
+	require.True(t, subnet.Contains(netip.MustParseAddr(vmAddress)))
+	gateway := netip.MustParsePrefix(*rc.Segments[0].Subnets[0].GatewayAddress)
+	require.Equal(t, subnet, gateway.Masked())
+	require.NotEqual(t, subnet, gateway)
+
+	require.Equal(t, []string{"ANY"}, rc.Rules[0].SourceGroups)
+	dst := rc.Rules[0].DestinationGroups
+	// the range is replaced by the anonymized cidr it consists of
+	require.Len(t, dst, 3)
+	require.Equal(t, "ANY", dst[2])
+	require.True(t, netip.MustParsePrefix(dst[0]).Contains(subnet.Addr()))
+	require.True(t, netip.MustParsePrefix(dst[1]).Contains(netip.MustParseAddr(vmAddress)))
+	require.Equal(t, dst[1], string(rc.Groups[0].AddressMembers[1]))
+
+	// outside rules and ip groups, a range is kept as a range, in the anonymized subnet
+	dhcpRanges := rc.Segments[0].Subnets[0].DhcpRanges
+	require.Len(t, dhcpRanges, 1)
+	start, end, ok := strings.Cut(string(dhcpRanges[0]), "-")
+	require.True(t, ok)
+	require.True(t, subnet.Contains(netip.MustParseAddr(start)))
+	require.True(t, subnet.Contains(netip.MustParseAddr(end)))
+	require.True(t, netip.MustParseAddr(start).Less(netip.MustParseAddr(end)))
+}
@@ -26,13 +26,16 @@ import (
 // 6.  RealizedVirtualMachine.DisplayName        = "VirtualMachine.DisplayName:10784"
 // 7.  RealizedVirtualMachine.Id                 = "VirtualMachine.ExternalId:10784"
 // 8.  VirtualNetworkInterface.OwnerVmId         = "VirtualMachine.ExternalId:10784"
-// 9.  IpAddressInfo.IpAddresses                 = "IpAddressInfo.IpAddresses:10932.0"
-// 10. IpAddressInfo.IpAddresses                 = ""192.168.1.2""
+// 9.  IpAddressInfo.IpAddresses                 = "37.112.203.64"
+// 10. SegmentSubnet.Network                     = "37.112.200.0/22"
 // 11. SegmentPort.Path                          = "/infra/segments/Segment.Id:10833/ports/SegmentPort.Id:10834"
 // 12. SegmentPort.RemotePath                    = nil
 // 13. FirewallRule.Sources[0].TargetId          = "Group.UniqueId:10884"
 // 14. FirewallRule.Sources[0].TargetDisplayName = "Group.DisplayName:10884"
 // 15. Service.DisplayName                       = "AD Server"
+// 16. VirtualMachine.Tags[0].Tag                = "Tag.Tag:10950"
+// 17. Group.Expression[0].Value                 = "Tag.Scope:10951|Tag.Tag:10950"
+// 18. Group.Expression[2].Value                 = "VirtualMachine.DisplayName:10784"
 
 // all these examples are different cases of anonymization, the anonymizer follow the anonInstruction struct.
 // the field in anonInstruction:
@@ -44,8 +47,11 @@ import (
 // refStructs             - some structs are reference to another structs,
 //                          so we take the instance number from the referred structs (see example 7)
 // fields                 - fields that are not ids, we anonymize using the instance number (see example 5)
-// fieldsByCondition      - fields that are not ids, we anonymize only if it satisfy a condition function (see examples 9,10)
-// slicesByCondition      - same, but for slices
+// ipFields               - fields of ip addresses, cidrs or ranges, that we anonymize by a prefix-preserving permutation,
+//                          thus the anonymized addresses are in the anonymized cidrs of the original cidrs (see examples 9,10)
+// ipSliceFields          - same, but for slices
+// ipRangeSplitFields     - ip slice fields in which ipv4 ranges are replaced by the cidrs they consist of (rules and ip groups),
+//                          since the anonymized range of a range is not a range. in other fields ranges are kept as ranges
 // fieldsByRef            - fields that are not ids, we anonymize using the instance number of another instance, according to a given Id.
 //                          (see example 14, according to example 13)
 // structsToNotAnonFields - struct that we do not anonymize their fields(which are not Ids) ( see example 15)
@@ -53,20 +59,31 @@ import (
 // pathFields             - paths to fix, according to the Ids ( see example 11)
 // pathToCleanFields      - paths to delete the content (see example 12)
 // rootPaths              - acceptable path prefixes
+// valueFields            - fields that we anonymize by their value, so equal values have equal anon values (see example 16)
+// conditionValues        - values of conditions that we anonymize by the condition key, as the value fields or fields
+//                          the condition refers to (see examples 17,18, according to examples 16,5)
 
 const firstAnonNumber = 10000
 
 type byRefField struct {
 	fieldName, refIDName, refName string
 }
-type conditionField struct {
-	fieldName string
-	filter    func(string) bool
-}
 type idToKeep struct {
 	structName string
 	fieldName  string
 }
+type structField struct {
+	structName string
+	fieldName  string
+}
+
+// conditionValue is the anonymization of the values of conditions with a given key:
+// a value is a conjunction of parts, separated by conditionValueSeparator, which refer to the given value fields.
+// a value with fewer parts refers to the last fields (e.g. a tag condition value without a scope)
+type conditionValue struct {
+	key         string
+	valueFields []structField
+}
 
 type anonInstruction struct {
 	pkgsToSkip             []string
@@ -78,8 +95,9 @@ type anonInstruction struct {
 	idsToKeep              []idToKeep
 	idRefFields            []string
 	fields                 []string
-	fieldsByCondition      []conditionField
-	slicesByCondition      []conditionField
+	ipFields               []string
+	ipSliceFields          []string
+	ipRangeSplitFields     []string
 	fieldsByRef            []byRefField
 	fieldsToClear          []string
 	idToCreateIfNotFound   []string
@@ -87,6 +105,8 @@ type anonInstruction struct {
 	pathSliceFields        []string
 	pathToCleanFields      []string
 	rootPaths              []string
+	valueFields            []structField
+	conditionValues        []conditionValue
 }
 
 // anonInfo holds the info of one ID anonymization
@@ -121,25 +141,29 @@ func (s statistics) string() string {
 }
 
 type anonymizer struct {
-	instancesNumber       map[pointer]int        // the uniq anon number of each instance
-	numberToInstance      map[int]structInstance // uniq anon number the instance
-	instanceNumberCounter int                    // the counter, to create a new anonymization
-	oldToAnonsInfo        map[string]*anonInfo   // map from old value to anon info
-	newToAnonsInfo        map[string]*anonInfo   // map from new value to anon info
-	paths                 []string               // all the orig paths
-	anonymizedPaths       map[string]string      // map from orig to anon path
-	anonInstruction       *anonInstruction       // the instruction to anon with
+	instancesNumber       map[pointer]int                   // the uniq anon number of each instance
+	numberToInstance      map[int]structInstance            // uniq anon number the instance
+	instanceNumberCounter int                               // the counter, to create a new anonymization
+	oldToAnonsInfo        map[string]*anonInfo              // map from old value to anon info
+	newToAnonsInfo        map[string]*anonInfo              // map from new value to anon info
+	paths                 []string                          // all the orig paths
+	anonymizedPaths       map[string]string                 // map from orig to anon path
+	anonInstruction       *anonInstruction                  // the instruction to anon with
+	anonymizedValues      map[structField]map[string]string // map from a value field to a map from orig to anon value
+	ipAnonymizer          *ipAnonymizer
 	statistics            statistics
 }
 
-func newAnonymizer() *anonymizer {
+func newAnonymizer(ipAnonymizer *ipAnonymizer) *anonymizer {
 	return &anonymizer{
+		ipAnonymizer:          ipAnonymizer,
 		instanceNumberCounter: firstAnonNumber,
 		instancesNumber:       map[pointer]int{},
 		numberToInstance:      map[int]structInstance{},
 		oldToAnonsInfo:        map[string]*anonInfo{},
 		newToAnonsInfo:        map[string]*anonInfo{},
 		anonymizedPaths:       map[string]string{},
+		anonymizedValues:      map[structField]map[string]string{},
 		statistics:            statistics{},
 	}
 }
@@ -237,13 +261,11 @@ func (a *anonymizer) anonymizeFields(structInstance structInstance) error {
 	for _, f := range a.anonInstruction.fields {
 		a.anonymizeField(structInstance, f)
 	}
-	for _, f := range a.anonInstruction.slicesByCondition {
-		for i := 0; i < getSliceLen(structInstance, f.fieldName); i++ {
-			a.anonymizeSliceFieldFunc(structInstance, f.fieldName, i, f.filter)
-		}
+	for _, f := range a.anonInstruction.ipFields {
+		a.anonymizeIPField(structInstance, f)
 	}
-	for _, f := range a.anonInstruction.fieldsByCondition {
-		a.anonymizeFieldFunc(structInstance, f.fieldName, f.filter)
+	for _, f := range a.anonInstruction.ipSliceFields {
+		a.anonymizeIPSliceField(structInstance, f)
 	}
 	return nil
 }
@@ -257,6 +279,19 @@ func (a *anonymizer) anonymizeFieldsByRef(structInstance structInstance) error {
 	return nil
 }
 
+func (a *anonymizer) anonymizeValues(structInstance structInstance) error {
+	structName := structName(structInstance)
+	for _, f := range a.anonInstruction.valueFields {
+		if f.structName == structName {
+			a.anonymizeValueField(structInstance, f)
+		}
+	}
+	for _, c := range a.anonInstruction.conditionValues {
+		a.anonymizeConditionValue(structInstance, c)
+	}
+	return nil
+}
+
 func (a *anonymizer) collectPaths(structInstance structInstance) error {
 	for _, fieldName := range a.anonInstruction.pathFields {
 		oldVal, ok := getField(structInstance, fieldName)
@@ -267,7 +302,7 @@ func (a *anonymizer) collectPaths(structInstance structInstance) error {
 	for _, fieldName := range a.anonInstruction.pathSliceFields {
 		for i := 0; i < getSliceLen(structInstance, fieldName); i++ {
 			oldVal, ok := getSliceField(structInstance, fieldName, i)
-			if ok {
+			if ok && !isIPElement(oldVal) {
 				a.paths = append(a.paths, oldVal)
 			}
 		}
@@ -291,8 +326,8 @@ func (a *anonymizer) anonymizePaths(structInstance structInstance) error {
 	for _, fieldName := range a.anonInstruction.pathSliceFields {
 		for i := 0; i < getSliceLen(structInstance, fieldName); i++ {
 			oldVal, ok := getSliceField(structInstance, fieldName, i)
-			if !ok {
-				continue
+			if !ok || isIPElement(oldVal) {
+				continue // ip elements are anonymized as ip slice fields
 			}
 			anonVal, ok := a.anonymizedPaths[oldVal]
 			if !ok {
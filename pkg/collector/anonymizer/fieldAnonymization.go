@@ -61,25 +61,122 @@ func (a *anonymizer) anonymizeIDRef(structInstance structInstance, fieldName str
 }
 
 func (a *anonymizer) anonymizeField(structInstance structInstance, fieldName string) {
-	a.anonymizeFieldFunc(structInstance, fieldName, nil)
+	oldVal, ok := getField(structInstance, fieldName)
+	if !ok || oldVal == "" {
+		return
+	}
+	f := structField{structName(structInstance), fieldName}
+	v := a.anonVal(f.structName, fieldName, a.instanceNumber(structInstance))
+	a.setField(structInstance, fieldName, oldVal, v)
+	// kept for the condition values which refer to the field
+	if _, ok := a.anonymizedValues[f]; !ok {
+		a.anonymizedValues[f] = map[string]string{}
+	}
+	a.anonymizedValues[f][oldVal] = v
 }
 
-func (a *anonymizer) anonymizeFieldFunc(structInstance structInstance, fieldName string, filterFunc func(string) bool) {
+func (a *anonymizer) anonymizeIPField(structInstance structInstance, fieldName string) {
 	oldVal, ok := getField(structInstance, fieldName)
-	if !ok || oldVal == "" || (filterFunc != nil && !filterFunc(oldVal)) {
+	if !ok || oldVal == "" {
 		return
 	}
-	v := a.anonVal(structName(structInstance), fieldName, a.instanceNumber(structInstance))
-	a.setField(structInstance, fieldName, oldVal, v)
+	if newVal, ok := a.ipAnonymizer.anonymizeIPElement(oldVal); ok {
+		a.setField(structInstance, fieldName, oldVal, newVal)
+	}
 }
 
-func (a *anonymizer) anonymizeSliceFieldFunc(structInstance structInstance, fieldName string, index int, filterFunc func(string) bool) {
-	oldVal, ok := getSliceField(structInstance, fieldName, index)
-	if !ok || oldVal == "" || (filterFunc != nil && !filterFunc(oldVal)) {
+// anonymizeIPSliceField anonymizes the ip elements of a slice. in ipRangeSplitFields, ipv4 ranges are replaced by the
+// cidrs they consist of, otherwise they are kept as ranges. other entries of the slice (e.g. paths of groups in rules) are kept
+func (a *anonymizer) anonymizeIPSliceField(structInstance structInstance, fieldName string) {
+	sliceLen := getSliceLen(structInstance, fieldName)
+	if sliceLen == 0 {
 		return
 	}
-	v := fmt.Sprintf("%s.%s:%d.%d", structName(structInstance), fieldName, a.instanceNumber(structInstance), index)
-	setSliceField(structInstance, fieldName, v, index)
+	newVals := make([]string, 0, sliceLen)
+	anonymized := false
+	splitRanges := slices.Contains(a.anonInstruction.ipRangeSplitFields, fieldName)
+	for i := 0; i < sliceLen; i++ {
+		oldVal, _ := getSliceField(structInstance, fieldName, i)
+		anonVals, ok := a.anonymizeIPSliceElement(oldVal, splitRanges)
+		if !ok {
+			newVals = append(newVals, oldVal)
+			continue
+		}
+		for _, newVal := range anonVals {
+			a.statistics.addStatistic(oldVal, newVal)
+		}
+		newVals = append(newVals, anonVals...)
+		anonymized = true
+	}
+	if anonymized {
+		setSliceFieldValues(structInstance, fieldName, newVals)
+	}
+}
+
+func (a *anonymizer) anonymizeIPSliceElement(ip string, splitRanges bool) ([]string, bool) {
+	if splitRanges {
+		return a.ipAnonymizer.anonymizeIPElements(ip)
+	}
+	anon, ok := a.ipAnonymizer.anonymizeIPElement(ip)
+	return []string{anon}, ok
+}
+
+func (a *anonymizer) anonymizeValueField(structInstance structInstance, f structField) {
+	oldVal, ok := getStringField(structInstance, f.fieldName)
+	if !ok || oldVal == "" {
+		return
+	}
+	newVal := a.anonValue(f, oldVal)
+	a.statistics.addStatistic(oldVal, newVal)
+	setStringField(structInstance, f.fieldName, newVal)
+}
+
+// anonymizeConditionValue anonymizes the value of a condition if its key is of the given condition value,
+// each part of the value is anonymized as the field it refers to
+func (a *anonymizer) anonymizeConditionValue(structInstance structInstance, c conditionValue) {
+	if structName(structInstance) != conditionStructName {
+		return
+	}
+	if key, ok := getField(structInstance, conditionKeyField); !ok || key != c.key {
+		return
+	}
+	oldVal, ok := getField(structInstance, conditionValueField)
+	if !ok || oldVal == "" {
+		return
+	}
+	parts := strings.SplitN(oldVal, conditionValueSeparator, len(c.valueFields))
+	fields := c.valueFields[len(c.valueFields)-len(parts):]
+	for i := range parts {
+		if parts[i] != "" {
+			parts[i] = a.conditionPartAnonValue(fields[i], parts[i])
+		}
+	}
+	a.setField(structInstance, conditionValueField, oldVal, strings.Join(parts, conditionValueSeparator))
+}
+
+// conditionPartAnonValue returns the anon value of a part of a condition value, referring to the given field.
+// A part referring to a field anonymized per instance (e.g. a VM name) is anonymized as the field of the instance with
+// this value. Other parts, e.g. a prefix of VM names, are anonymized as values of the field; thus conditions with
+// prefix, suffix or contains operators do not match the anonymized values of the instances they matched
+func (a *anonymizer) conditionPartAnonValue(f structField, oldVal string) string {
+	if newVal, ok := a.anonymizedValues[f][oldVal]; ok {
+		return newVal
+	}
+	return a.anonValue(f, oldVal)
+}
+
+// anonValue returns the anon value of a value field, equal values of the field have the same anon value
+func (a *anonymizer) anonValue(f structField, oldVal string) string {
+	if _, ok := a.anonymizedValues[f]; !ok {
+		a.anonymizedValues[f] = map[string]string{}
+	}
+	if newVal, ok := a.anonymizedValues[f][oldVal]; ok {
+		return newVal
+	}
+	newVal := a.anonVal(f.structName, f.fieldName, a.instanceNumberCounter)
+	a.instanceNumberCounter++
+	a.anonymizedValues[f][oldVal] = newVal
+	return newVal
 }
 
 func (a *anonymizer) anonymizeFieldByRef(structInstance structInstance, fs byRefField) error {
@@ -0,0 +1,151 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package anonymizer
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"net/netip"
+	"strings"
+
+	"github.com/np-guard/models/pkg/netset"
+)
+
+// the ip addresses are anonymized by a prefix-preserving permutation, as in Crypto-PAn:
+// two addresses sharing a prefix of n bits are anonymized to two addresses sharing a prefix of n bits.
+// thus a cidr is anonymized to a cidr of the same length, which contains exactly the anonymized addresses of the original cidr,
+// and the relations between the addresses of VMs, segments subnets, rules ip blocks and ip groups are kept.
+// the i-th bit of an address is flipped by the first bit of the AES encryption of its first i bits, padded by a secret pad.
+
+const (
+	ipKeyLen         = 32 // the first half is the AES key, the second half is the pad
+	ipRangeSeparator = "-"
+	bitsInByte       = 8
+	msbMask          = 0x80
+)
+
+type ipAnonymizer struct {
+	cipher     cipher.Block
+	pad        []byte
+	anonymized map[netip.Addr]netip.Addr
+}
+
+// newIPAnonymizer creates an ip anonymizer keyed by the given key; by a random key if the given key is empty
+func newIPAnonymizer(key string) (*ipAnonymizer, error) {
+	secret := make([]byte, ipKeyLen)
+	if key == "" {
+		if _, err := rand.Read(secret); err != nil {
+			return nil, err
+		}
+	} else {
+		sum := sha256.Sum256([]byte(key))
+		secret = sum[:]
+	}
+	block, err := aes.NewCipher(secret[:aes.BlockSize])
+	if err != nil {
+		return nil, err
+	}
+	pad := make([]byte, aes.BlockSize)
+	block.Encrypt(pad, secret[aes.BlockSize:])
+	return &ipAnonymizer{cipher: block, pad: pad, anonymized: map[netip.Addr]netip.Addr{}}, nil
+}
+
+func (ia *ipAnonymizer) anonymizeAddr(addr netip.Addr) netip.Addr {
+	if anon, ok := ia.anonymized[addr]; ok {
+		return anon
+	}
+	orig := addr.AsSlice()
+	res := make([]byte, len(orig))
+	input := make([]byte, aes.BlockSize)
+	output := make([]byte, aes.BlockSize)
+	for i := 0; i < len(orig)*bitsInByte; i++ {
+		// the input is the first i bits of the address, followed by the rest of the pad
+		copy(input, ia.pad)
+		byteIndex, bitMask := i/bitsInByte, byte(msbMask>>(i%bitsInByte))
+		copy(input, orig[:byteIndex])
+		prefixMask := ^(bitMask<<1 - 1)
+		input[byteIndex] = orig[byteIndex]&prefixMask | ia.pad[byteIndex]&^prefixMask
+		ia.cipher.Encrypt(output, input)
+		flip := byte(0)
+		if output[0]&msbMask != 0 {
+			flip = bitMask
+		}
+		res[byteIndex] |= (orig[byteIndex] & bitMask) ^ flip
+	}
+	anon, _ := netip.AddrFromSlice(res)
+	ia.anonymized[addr] = anon
+	return anon
+}
+
+// anonymizeIPElement anonymizes an ip address, a cidr or an ip range.
+// a range is anonymized by its end addresses, thus its anonymization keeps only the relations of its end addresses
+// (the anonymized end addresses are ordered, so the anonymized range is a valid range).
+// returns false if the given string is not an ip element
+func (ia *ipAnonymizer) anonymizeIPElement(ip string) (string, bool) {
+	if addr, err := netip.ParseAddr(ip); err == nil {
+		return ia.anonymizeAddr(addr).String(), true
+	}
+	if prefix, err := netip.ParsePrefix(ip); err == nil {
+		anon := netip.PrefixFrom(ia.anonymizeAddr(prefix.Addr()), prefix.Bits())
+		if prefix.Addr() == prefix.Masked().Addr() {
+			// a subnet, rather than an address with its subnet length
+			anon = anon.Masked()
+		}
+		return anon.String(), true
+	}
+	if start, end, ok := parseIPRange(ip); ok {
+		anonStart, anonEnd := ia.anonymizeAddr(start), ia.anonymizeAddr(end)
+		if anonEnd.Less(anonStart) {
+			anonStart, anonEnd = anonEnd, anonStart
+		}
+		return anonStart.String() + ipRangeSeparator + anonEnd.String(), true
+	}
+	return "", false
+}
+
+// anonymizeIPElements anonymizes an ip element as anonymizeIPElement(), but an ipv4 range is split to cidrs,
+// each anonymized to a cidr, so the anonymization of a range keeps its relations to other ip elements
+func (ia *ipAnonymizer) anonymizeIPElements(ip string) ([]string, bool) {
+	if start, _, ok := parseIPRange(ip); ok && start.Is4() {
+		if block, err := netset.IPBlockFromIPRangeStr(ip); err == nil {
+			cidrs := block.ToCidrList()
+			res := make([]string, len(cidrs))
+			for i, cidr := range cidrs {
+				res[i], _ = ia.anonymizeIPElement(cidr)
+			}
+			return res, true
+		}
+	}
+	anon, ok := ia.anonymizeIPElement(ip)
+	if !ok {
+		return nil, false
+	}
+	return []string{anon}, true
+}
+
+func parseIPRange(ipRange string) (start, end netip.Addr, ok bool) {
+	startStr, endStr, found := strings.Cut(ipRange, ipRangeSeparator)
+	if !found {
+		return start, end, false
+	}
+	start, err1 := netip.ParseAddr(strings.TrimSpace(startStr))
+	end, err2 := netip.ParseAddr(strings.TrimSpace(endStr))
+	return start, end, err1 == nil && err2 == nil
+}
+
+func isIPElement(s string) bool {
+	if _, err := netip.ParseAddr(s); err == nil {
+		return true
+	}
+	if _, err := netip.ParsePrefix(s); err == nil {
+		return true
+	}
+	_, _, ok := parseIPRange(s)
+	return ok
+}
@@ -50,6 +50,16 @@ func iterateValue(val reflect.Value, user iteratorUser, atStruct atStructFunc, f
 				return err
 			}
 		}
+	case reflect.Map:
+		// map values are not addressable, thus structs in maps can not be anonymized
+		if val.Type().Elem().Kind() == reflect.Struct {
+			return fmt.Errorf("parsing map of %v is not supported", val.Type().Elem().String())
+		}
+		for _, k := range val.MapKeys() {
+			if err := iterateValue(val.MapIndex(k), user, atStruct, filter); err != nil {
+				return err
+			}
+		}
 	case reflect.String, reflect.Bool, reflect.Int:
 	default:
 		return fmt.Errorf("parsing %v is not supported", val.Kind().String())
@@ -35,6 +35,16 @@ func getField(structInstance structInstance, fieldName string) (string, bool) {
 func setField(structInstance structInstance, fieldName, value string) {
 	reflect.ValueOf(structInstance).Elem().FieldByName(fieldName).Elem().SetString(value)
 }
+func getStringField(structInstance structInstance, fieldName string) (string, bool) {
+	f := reflect.ValueOf(structInstance).Elem().FieldByName(fieldName)
+	if f.IsValid() && f.Kind() == reflect.String {
+		return f.String(), true
+	}
+	return "", false
+}
+func setStringField(structInstance structInstance, fieldName, value string) {
+	reflect.ValueOf(structInstance).Elem().FieldByName(fieldName).SetString(value)
+}
 func clearField(structInstance structInstance, fieldName string) {
 	f := reflect.ValueOf(structInstance).Elem().FieldByName(fieldName)
 	if f.IsValid() && !f.IsNil() {
@@ -43,14 +53,14 @@ func clearField(structInstance structInstance, fieldName string) {
 }
 func getSliceLen(structInstance structInstance, fieldName string) int {
 	f := reflect.ValueOf(structInstance).Elem().FieldByName(fieldName)
-	if f.IsValid() && !f.IsNil() {
+	if f.IsValid() && f.Kind() == reflect.Slice && !f.IsNil() {
 		return f.Len()
 	}
 	return 0
 }
 func getSliceField(structInstance structInstance, fieldName string, index int) (string, bool) {
 	f := reflect.ValueOf(structInstance).Elem().FieldByName(fieldName)
-	if f.IsValid() && !f.IsNil() {
+	if f.IsValid() && f.Kind() == reflect.Slice && !f.IsNil() {
 		return f.Index(index).String(), true
 	}
 	return "", false
@@ -58,3 +68,11 @@ func getSliceField(structInstance structInstance, fieldName string, index int) (
 func setSliceField(structInstance structInstance, fieldName, value string, index int) {
 	reflect.ValueOf(structInstance).Elem().FieldByName(fieldName).Index(index).SetString(value)
 }
+func setSliceFieldValues(structInstance structInstance, fieldName string, values []string) {
+	f := reflect.ValueOf(structInstance).Elem().FieldByName(fieldName)
+	newSlice := reflect.MakeSlice(f.Type(), len(values), len(values))
+	for i, v := range values {
+		newSlice.Index(i).SetString(v)
+	}
+	f.Set(newSlice)
+}
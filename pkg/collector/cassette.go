@@ -0,0 +1,170 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+)
+
+// a cassette is a directory holding the interactions with an NSX manager, one JSON file per interaction,
+// named by the order of the interactions. Credentials are not recorded.
+const (
+	cassetteFileExtension = ".json"
+	cassetteFilePattern   = "*" + cassetteFileExtension
+)
+
+// interaction is a recorded request to the NSX manager and its response
+type interaction struct {
+	Method      string `json:"method"`
+	Query       string `json:"query"`
+	RequestBody string `json:"request_body,omitempty"`
+	StatusCode  int    `json:"status_code,omitempty"` // set for responses with an error status
+	Response    string `json:"response,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+func (i *interaction) key() string {
+	return i.Method + " " + i.Query + " " + i.RequestBody
+}
+
+func (i *interaction) result() ([]byte, error) {
+	if i.StatusCode != 0 {
+		return nil, newAPIError(i.Method, i.Query, i.StatusCode, []byte(i.Response))
+	}
+	if i.Error != "" {
+		return nil, errors.New(i.Error)
+	}
+	return []byte(i.Response), nil
+}
+
+// recordingClient sends the requests by another client, recording every request and its response to a cassette
+type recordingClient struct {
+	client Client
+	dir    string
+
+	lock  sync.Mutex
+	count int
+}
+
+// NewRecordingClient returns a client recording the interactions of the given client to the cassette directory dir,
+// which is created if needed and should not hold previous recordings
+func NewRecordingClient(client Client, dir string) (Client, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	files, err := filepath.Glob(filepath.Join(dir, cassetteFilePattern))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) > 0 {
+		return nil, fmt.Errorf("cassette directory %s already holds recorded interactions", dir)
+	}
+	return &recordingClient{client: client, dir: dir}, nil
+}
+
+func (c *recordingClient) Get(ctx context.Context, query string) ([]byte, error) {
+	res, err := c.client.Get(ctx, query)
+	return res, c.record(&interaction{Method: http.MethodGet, Query: query}, res, err)
+}
+
+func (c *recordingClient) Post(ctx context.Context, query string, body []byte) ([]byte, error) {
+	res, err := c.client.Post(ctx, query, body)
+	return res, c.record(&interaction{Method: http.MethodPost, Query: query, RequestBody: string(body)}, res, err)
+}
+
+func (c *recordingClient) Delete(ctx context.Context, query string) ([]byte, error) {
+	res, err := c.client.Delete(ctx, query)
+	return res, c.record(&interaction{Method: http.MethodDelete, Query: query}, res, err)
+}
+
+// record writes an interaction to the cassette, and returns the error of the request, or of the recording
+func (c *recordingClient) record(i *interaction, res []byte, reqErr error) error {
+	i.Response = string(res)
+	var apiErr *APIError
+	switch {
+	case errors.As(reqErr, &apiErr):
+		i.StatusCode = apiErr.StatusCode
+		i.Response = string(apiErr.Body)
+	case reqErr != nil:
+		i.Error = reqErr.Error()
+	}
+	b, err := json.MarshalIndent(i, "", "  ")
+	if err != nil {
+		return errors.Join(reqErr, err)
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.count++
+	fileName := filepath.Join(c.dir, fmt.Sprintf("%05d-%s%s", c.count, i.Method, cassetteFileExtension))
+	return errors.Join(reqErr, os.WriteFile(fileName, b, 0o600))
+}
+
+// replayClient returns the responses recorded in a cassette. Repeated requests are replied by their recorded
+// responses in order, and the last recorded response is repeated, e.g. when polling for a traceflow.
+type replayClient struct {
+	lock         sync.Mutex
+	interactions map[string][]*interaction
+	replayed     map[string]int
+}
+
+// NewReplayClient returns a client replaying the interactions recorded to the cassette directory dir
+func NewReplayClient(dir string) (Client, error) {
+	files, err := filepath.Glob(filepath.Join(dir, cassetteFilePattern))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("cassette directory %s holds no recorded interactions", dir)
+	}
+	slices.Sort(files)
+	c := &replayClient{interactions: map[string][]*interaction{}, replayed: map[string]int{}}
+	for _, file := range files {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		i := &interaction{}
+		if err := json.Unmarshal(b, i); err != nil {
+			return nil, fmt.Errorf("invalid recorded interaction %s: %w", file, err)
+		}
+		c.interactions[i.key()] = append(c.interactions[i.key()], i)
+	}
+	return c, nil
+}
+
+func (c *replayClient) Get(_ context.Context, query string) ([]byte, error) {
+	return c.replay(&interaction{Method: http.MethodGet, Query: query})
+}
+
+func (c *replayClient) Post(_ context.Context, query string, body []byte) ([]byte, error) {
+	return c.replay(&interaction{Method: http.MethodPost, Query: query, RequestBody: string(body)})
+}
+
+func (c *replayClient) Delete(_ context.Context, query string) ([]byte, error) {
+	return c.replay(&interaction{Method: http.MethodDelete, Query: query})
+}
+
+func (c *replayClient) replay(request *interaction) ([]byte, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	key := request.key()
+	recorded := c.interactions[key]
+	if len(recorded) == 0 {
+		return nil, fmt.Errorf("no recorded response for %s %s", request.Method, request.Query)
+	}
+	index := min(c.replayed[key], len(recorded)-1)
+	c.replayed[key]++
+	return recorded[index].result()
+}
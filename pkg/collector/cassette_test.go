@@ -0,0 +1,75 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package collector
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	// a vms list of two pages, and a resource which changes between requests
+	polls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/"+virtualMachineQuery && r.URL.Query().Get("cursor") == "":
+			fmt.Fprint(w, `{"results": [{"display_name": "vm1"}], "result_count": 2, "cursor": "next"}`)
+		case r.URL.Path == "/"+virtualMachineQuery:
+			fmt.Fprint(w, `{"results": [{"display_name": "vm2"}], "result_count": 2}`)
+		default:
+			polls++
+			fmt.Fprintf(w, `{"polls": %d}`, polls)
+		}
+	}))
+	dir := filepath.Join(t.TempDir(), "cassette")
+	recorder, err := NewRecordingClient(NewServerData(ts.URL, "user", "password", false).Client(), dir)
+	require.Nil(t, err)
+	recorded := NewServerDataWithClient(recorder)
+	var vms []VirtualMachine
+	require.Nil(t, collectResultList(recorded, virtualMachineQuery, &vms))
+	require.Len(t, vms, 2)
+	for range 2 {
+		_, err = curlGetRequest(recorded, "poll")
+		require.Nil(t, err)
+	}
+	_, err = curlPostRequest(recorded, "poll", map[string]string{"name": "x"})
+	require.Nil(t, err)
+	ts.Close()
+	_, err = curlGetRequest(recorded, "unavailable")
+	require.NotNil(t, err)
+
+	_, err = NewRecordingClient(NewServerData(ts.URL, "", "", false).Client(), dir)
+	require.NotNil(t, err, "recording to a non empty cassette should fail")
+
+	player, err := NewReplayClient(dir)
+	require.Nil(t, err)
+	replayed := NewServerDataWithClient(player)
+	var replayedVMs []VirtualMachine
+	require.Nil(t, collectResultList(replayed, virtualMachineQuery, &replayedVMs))
+	require.Equal(t, vms, replayedVMs)
+	// repeated requests are replayed in order, then the last response is repeated
+	for _, expected := range []string{`{"polls": 1}`, `{"polls": 2}`, `{"polls": 2}`} {
+		b, err := curlGetRequest(replayed, "poll")
+		require.Nil(t, err)
+		require.Equal(t, expected, string(b))
+	}
+	b, err := curlPostRequest(replayed, "poll", map[string]string{"name": "x"})
+	require.Nil(t, err)
+	require.Equal(t, `{"polls": 3}`, string(b))
+	_, err = curlGetRequest(replayed, "unavailable")
+	require.NotNil(t, err, "a recorded error should be replayed")
+	_, err = curlDeleteRequest(replayed, "poll")
+	require.ErrorContains(t, err, "no recorded response for DELETE poll")
+
+	_, err = NewReplayClient(t.TempDir())
+	require.NotNil(t, err, "replaying an empty cassette should fail")
+}
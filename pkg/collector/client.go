@@ -0,0 +1,315 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package collector
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/np-guard/vmware-analyzer/internal/common"
+	"github.com/np-guard/vmware-analyzer/pkg/logging"
+)
+
+const (
+	sessionCreateQuery = "api/session/create"
+	xsrfTokenHeader    = "X-XSRF-TOKEN"
+
+	defaultMaxRetries   = common.DefaultNSXMaxRetries
+	defaultRetryBackoff = time.Second
+	maxRetryWait        = time.Minute
+)
+
+// Client sends REST API requests to the NSX manager; queries are relative to the NSX manager URL
+type Client interface {
+	Get(ctx context.Context, query string) ([]byte, error)
+	Post(ctx context.Context, query string, body []byte) ([]byte, error)
+	Delete(ctx context.Context, query string) ([]byte, error)
+}
+
+// httpClient is the Client of a live NSX manager, authenticating by basic auth, a session or a client certificate
+type httpClient struct {
+	host, user, password string
+	tlsConfig            *tls.Config
+	timeout              time.Duration
+	sessionAuth          bool
+	maxRetries           int
+	retryBackoff         time.Duration // the wait before the first retry, doubled per retry
+	client               *http.Client
+
+	sessionLock sync.Mutex
+	xsrfToken   string // the token of the current session, sent with the session cookie
+}
+
+// HTTPClientOption is the type for specifying options for the Client of a live NSX manager
+type HTTPClientOption func(*httpClient) error
+
+// WithSessionAuth authenticates by a session created with the user credentials, instead of basic auth per request.
+// The session is created again if it expires.
+func WithSessionAuth(sessionAuth bool) HTTPClientOption {
+	return func(c *httpClient) error {
+		c.sessionAuth = sessionAuth
+		return nil
+	}
+}
+
+// WithClientCertificate authenticates by a client certificate, e.g. of a principal identity, given in PEM format
+func WithClientCertificate(certPEM, keyPEM []byte) HTTPClientOption {
+	return func(c *httpClient) error {
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return fmt.Errorf("invalid NSX client certificate: %w", err)
+		}
+		c.tlsConfig.Certificates = []tls.Certificate{cert}
+		return nil
+	}
+}
+
+// WithCACertificates verifies the NSX manager certificate by the given CA certificates in PEM format,
+// instead of the system CAs (or not verifying it at all, if insecure skip verify is not disabled)
+func WithCACertificates(caPEM []byte) HTTPClientOption {
+	return func(c *httpClient) error {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return errors.New("invalid NSX CA certificates: no PEM certificates found")
+		}
+		c.tlsConfig.RootCAs = pool
+		c.tlsConfig.InsecureSkipVerify = false
+		return nil
+	}
+}
+
+// WithRequestTimeout sets the timeout of each request to the NSX manager (0 for no timeout)
+func WithRequestTimeout(timeout time.Duration) HTTPClientOption {
+	return func(c *httpClient) error {
+		c.timeout = timeout
+		return nil
+	}
+}
+
+// WithMaxRetries sets the max number of retries of an idempotent request, while the NSX manager responds with status
+// 429 (too many requests) or 503 (service unavailable). Retries wait by the Retry-After header, or back-off exponentially.
+func WithMaxRetries(maxRetries int) HTTPClientOption {
+	return func(c *httpClient) error {
+		if maxRetries < 0 {
+			return fmt.Errorf("invalid max retries %d: should not be negative", maxRetries)
+		}
+		c.maxRetries = maxRetries
+		return nil
+	}
+}
+
+// NewHTTPClient returns a Client sending requests to the NSX manager at the given host
+func NewHTTPClient(host, user, password string, disableInsecureSkipVerify bool, opts ...HTTPClientOption) (Client, error) {
+	return newHTTPClient(host, user, password, disableInsecureSkipVerify, opts...)
+}
+
+func newHTTPClient(host, user, password string, disableInsecureSkipVerify bool, opts ...HTTPClientOption) (*httpClient, error) {
+	c := &httpClient{host: host, user: user, password: password,
+		//nolint:gosec // need insecure TLS option for testing and development
+		tlsConfig:  &tls.Config{InsecureSkipVerify: !disableInsecureSkipVerify},
+		maxRetries: defaultMaxRetries, retryBackoff: defaultRetryBackoff}
+	for _, o := range opts {
+		if err := o(c); err != nil {
+			return nil, err
+		}
+	}
+	c.client = &http.Client{Transport: &http.Transport{TLSClientConfig: c.tlsConfig}, Timeout: c.timeout}
+	if c.sessionAuth {
+		jar, err := cookiejar.New(nil)
+		if err != nil {
+			return nil, err
+		}
+		c.client.Jar = jar
+	}
+	return c, nil
+}
+
+func (c *httpClient) hasClientCertificate() bool {
+	return len(c.tlsConfig.Certificates) > 0
+}
+
+func (c *httpClient) Get(ctx context.Context, query string) ([]byte, error) {
+	return c.do(ctx, http.MethodGet, query, nil)
+}
+
+func (c *httpClient) Post(ctx context.Context, query string, body []byte) ([]byte, error) {
+	return c.do(ctx, http.MethodPost, query, body)
+}
+
+func (c *httpClient) Delete(ctx context.Context, query string) ([]byte, error) {
+	return c.do(ctx, http.MethodDelete, query, nil)
+}
+
+// do sends a request, retrying an idempotent request while the NSX manager is rate limiting or unavailable;
+// a POST request is not retried, since it may have been done (e.g. creating a traceflow) despite its response.
+// A response with an error status is returned as an *APIError.
+func (c *httpClient) do(ctx context.Context, method, query string, body []byte) ([]byte, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := c.authenticatedSend(ctx, method, query, body)
+		if err != nil {
+			return nil, err
+		}
+		if isRetryable(method, resp.statusCode) && attempt < c.maxRetries {
+			wait := retryWait(resp.header, c.retryBackoff, attempt)
+			logging.Infof("%s %s returned %d, retrying in %s", method, query, resp.statusCode, wait)
+			if err := sleep(ctx, wait); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if resp.statusCode < http.StatusOK || resp.statusCode >= http.StatusMultipleChoices {
+			return nil, newAPIError(method, query, resp.statusCode, resp.body)
+		}
+		return resp.body, nil
+	}
+}
+
+// authenticatedSend sends a request authenticated by the current session, if session auth is used
+func (c *httpClient) authenticatedSend(ctx context.Context, method, query string, body []byte) (*response, error) {
+	if !c.sessionAuth {
+		return c.send(ctx, method, query, body)
+	}
+	xsrfToken, err := c.session(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.send(ctx, method, query, body, withXSRFToken(xsrfToken))
+	if err != nil || resp.statusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	// the session expired
+	if xsrfToken, err = c.session(ctx, xsrfToken); err != nil {
+		return nil, err
+	}
+	return c.send(ctx, method, query, body, withXSRFToken(xsrfToken))
+}
+
+// session returns the token of the current session, creating a session if there is none, or if the current session
+// is the expired session
+func (c *httpClient) session(ctx context.Context, expiredToken string) (string, error) {
+	c.sessionLock.Lock()
+	defer c.sessionLock.Unlock()
+	if c.xsrfToken != "" && c.xsrfToken != expiredToken {
+		return c.xsrfToken, nil
+	}
+	logging.Infof("creating NSX session for user %s", c.user)
+	form := url.Values{"j_username": {c.user}, "j_password": {c.password}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.host+"/"+sessionCreateQuery, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to create NSX session for user %s: %s", c.user, resp.Status)
+	}
+	c.xsrfToken = resp.Header.Get(xsrfTokenHeader)
+	if c.xsrfToken == "" {
+		return "", fmt.Errorf("failed to create NSX session for user %s: no %s header in response", c.user, xsrfTokenHeader)
+	}
+	return c.xsrfToken, nil
+}
+
+func withXSRFToken(token string) func(*http.Request) {
+	return func(req *http.Request) {
+		req.Header.Set(xsrfTokenHeader, token)
+	}
+}
+
+type response struct {
+	statusCode int
+	header     http.Header
+	body       []byte
+}
+
+// send sends a request, and returns its response, whatever its status is
+func (c *httpClient) send(ctx context.Context, method, query string, body []byte, auth ...func(*http.Request)) (*response, error) {
+	var bodyReader io.Reader = http.NoBody
+	if body != nil {
+		bodyReader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.host+"/"+query, bodyReader)
+	logging.Infof("%s %s\n", method, query)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case len(auth) > 0:
+		for _, a := range auth {
+			a(req)
+		}
+	case c.user != "":
+		req.SetBasicAuth(c.user, c.password)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if err := sleep(ctx, rateTimeLimit); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	resp, err := c.client.Do(req)
+	if err != nil {
+		observeRequest(method, 0, time.Since(start), err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	observeRequest(method, resp.StatusCode, time.Since(start), err)
+	if err != nil {
+		return nil, err
+	}
+	return &response{statusCode: resp.StatusCode, header: resp.Header, body: b}, nil
+}
+
+// isRetryable returns whether a request failed with the given status code is retried: the request is idempotent,
+// and the NSX manager is rate limiting the requests, or is temporarily unavailable
+func isRetryable(method string, statusCode int) bool {
+	if method == http.MethodPost {
+		return false
+	}
+	return statusCode == http.StatusTooManyRequests || statusCode == http.StatusServiceUnavailable
+}
+
+// retryWait returns the time to wait before retrying a request: the Retry-After of its response if given,
+// and otherwise an exponential back-off
+func retryWait(header http.Header, backoff time.Duration, attempt int) time.Duration {
+	wait := backoff << attempt
+	if retryAfter := header.Get("Retry-After"); retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds >= 0 {
+			wait = time.Duration(seconds) * time.Second
+		} else if date, err := http.ParseTime(retryAfter); err == nil {
+			wait = max(time.Until(date), 0)
+		}
+	}
+	return min(wait, maxRetryWait)
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
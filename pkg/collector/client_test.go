@@ -0,0 +1,301 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package collector
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testUser     = "admin"
+	testPassword = "secret"
+	testToken    = "token"
+)
+
+func TestSessionAuth(t *testing.T) {
+	sessions := 0
+	validSession := ""
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/"+sessionCreateQuery {
+			if r.FormValue("j_username") != testUser || r.FormValue("j_password") != testPassword {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			sessions++
+			validSession = fmt.Sprintf("session-%d", sessions)
+			http.SetCookie(w, &http.Cookie{Name: "JSESSIONID", Value: validSession, Path: "/"})
+			w.Header().Set(xsrfTokenHeader, testToken+validSession)
+			return
+		}
+		cookie, err := r.Cookie("JSESSIONID")
+		if _, _, basicAuth := r.BasicAuth(); basicAuth || err != nil || cookie.Value != validSession ||
+			r.Header.Get(xsrfTokenHeader) != testToken+validSession {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"results": [{"display_name": "vm1"}], "result_count": 1}`)
+	}))
+	defer ts.Close()
+
+	client, err := NewHTTPClient(ts.URL, testUser, testPassword, false, WithSessionAuth(true))
+	require.Nil(t, err)
+	server := NewServerDataWithClient(client)
+	var vms []VirtualMachine
+	require.Nil(t, collectResultList(server, virtualMachineQuery, &vms))
+	require.Len(t, vms, 1)
+	require.Nil(t, collectResultList(server, virtualMachineQuery, &vms))
+	require.Equal(t, 1, sessions, "the session should be reused")
+
+	// an expired session is created again
+	validSession = "expired"
+	require.Nil(t, collectResultList(server, virtualMachineQuery, &vms))
+	require.Equal(t, 2, sessions)
+
+	client, err = NewHTTPClient(ts.URL, testUser, "wrong", false, WithSessionAuth(true))
+	require.Nil(t, err)
+	_, err = client.Get(context.Background(), virtualMachineQuery)
+	require.ErrorContains(t, err, "failed to create NSX session")
+}
+
+func TestCertificates(t *testing.T) {
+	caCert, caKey, caPEM := newTestCertificate(t, nil, nil, x509.ExtKeyUsageAny)
+	clientCert, _, clientPEM := newTestCertificate(t, caCert, caKey, x509.ExtKeyUsageClientAuth)
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) == 0 || !r.TLS.PeerCertificates[0].Equal(clientCert) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{}`)
+	}))
+	pool := x509.NewCertPool()
+	pool.AddCert(caCert)
+	ts.TLS = &tls.Config{ClientCAs: pool, ClientAuth: tls.VerifyClientCertIfGiven, MinVersion: tls.VersionTLS12}
+	ts.StartTLS()
+	defer ts.Close()
+	serverCAPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw})
+
+	// the server certificate is verified by the given CA
+	client, err := NewHTTPClient(ts.URL, "", "", true, WithCACertificates(serverCAPEM), WithClientCertificate(clientPEM.cert, clientPEM.key))
+	require.Nil(t, err)
+	b, err := client.Get(context.Background(), virtualMachineQuery)
+	require.Nil(t, err)
+	require.Equal(t, `{}`, string(b))
+
+	client, err = NewHTTPClient(ts.URL, "", "", true, WithCACertificates(caPEM.cert), WithClientCertificate(clientPEM.cert, clientPEM.key))
+	require.Nil(t, err)
+	_, err = client.Get(context.Background(), virtualMachineQuery)
+	require.ErrorContains(t, err, "certificate", "the server certificate should not be verified by another CA")
+
+	_, err = NewHTTPClient(ts.URL, "", "", true, WithCACertificates([]byte("not a certificate")))
+	require.NotNil(t, err)
+	_, err = NewHTTPClient(ts.URL, "", "", true, WithClientCertificate(clientPEM.cert, caPEM.key))
+	require.NotNil(t, err)
+
+	// with a client certificate, the NSX credentials are optional
+	t.Setenv("NSX_USER", "")
+	t.Setenv("NSX_PASSWORD", "")
+	_, err = GetNSXServerDate(ts.URL, "", "", true)
+	require.NotNil(t, err)
+	server, err := GetNSXServerDate(ts.URL, "", "", true, WithCACertificates(serverCAPEM),
+		WithClientCertificate(clientPEM.cert, clientPEM.key))
+	require.Nil(t, err)
+	_, err = curlGetRequest(server, virtualMachineQuery)
+	require.Nil(t, err)
+}
+
+func TestTimeoutAndCancel(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Minute):
+		}
+		fmt.Fprint(w, `{}`)
+	}))
+	defer ts.Close()
+
+	client, err := NewHTTPClient(ts.URL, "", "", false, WithRequestTimeout(10*time.Millisecond))
+	require.Nil(t, err)
+	_, err = client.Get(context.Background(), virtualMachineQuery)
+	require.ErrorContains(t, err, "Client.Timeout exceeded")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = CollectResources(NewServerData(ts.URL, "", "", false).WithContext(ctx))
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+type testPEM struct {
+	cert, key []byte
+}
+
+// newTestCertificate returns a certificate signed by the given CA, or a self signed CA if none is given
+func newTestCertificate(t *testing.T, ca *x509.Certificate, caKey *ecdsa.PrivateKey, usage x509.ExtKeyUsage) (
+	*x509.Certificate, *ecdsa.PrivateKey, testPEM) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "nsx-test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+	}
+	if ca == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		ca, caKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	require.Nil(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.Nil(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.Nil(t, err)
+	return cert, key, testPEM{
+		cert: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		key:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+func TestRetries(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch {
+		case r.URL.Path == "/unavailable":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case r.URL.Path == "/missing":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error_code": 600, "error_message": "not found"}`)
+		case requests == 1:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			fmt.Fprint(w, `{}`)
+		}
+	}))
+	defer ts.Close()
+
+	client, err := newHTTPClient(ts.URL, "", "", false)
+	require.Nil(t, err)
+	client.retryBackoff = time.Millisecond
+	_, err = client.Get(context.Background(), "limited")
+	require.Nil(t, err)
+	require.Equal(t, 2, requests, "a rate limited request should be retried after Retry-After")
+
+	requests = 0
+	_, err = client.Get(context.Background(), "unavailable")
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusServiceUnavailable, apiErr.StatusCode)
+	require.Equal(t, defaultMaxRetries+1, requests)
+
+	requests = 0
+	_, err = client.Get(context.Background(), "missing")
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, 1, requests, "a request with a non transient error should not be retried")
+	require.Equal(t, []string{"api error 600: not found"}, apiErr.Errors)
+	require.ErrorContains(t, err, "GET missing failed with status 404 Not Found: api error 600: not found")
+
+	requests = 0
+	_, err = client.Post(context.Background(), "unavailable", nil)
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, 1, requests, "a non idempotent request should not be retried")
+
+	require.Equal(t, 3*time.Second, retryWait(http.Header{"Retry-After": {"3"}}, time.Second, 0))
+	require.Equal(t, 4*time.Second, retryWait(http.Header{}, time.Second, 2))
+	require.Equal(t, maxRetryWait, retryWait(http.Header{"Retry-After": {"3600"}}, time.Second, 0))
+	_, err = NewHTTPClient(ts.URL, "", "", false, WithMaxRetries(-1))
+	require.NotNil(t, err)
+}
+
+// queryClient replies to GET requests by a function of their query
+type queryClient func(query string) ([]byte, error)
+
+func (c queryClient) Get(_ context.Context, query string) ([]byte, error) {
+	return c(query)
+}
+
+func (c queryClient) Post(_ context.Context, query string, _ []byte) ([]byte, error) {
+	return c(query)
+}
+
+func (c queryClient) Delete(_ context.Context, query string) ([]byte, error) {
+	return c(query)
+}
+
+func TestContinueOnError(t *testing.T) {
+	responses := map[string]string{
+		domainsQuery:                                  `{"results": [{"id": "default"}], "result_count": 1}`,
+		fmt.Sprintf(groupsQuery, "default"):           `{"results": [{"id": "g1"}, {"id": "g2"}], "result_count": 2}`,
+		fmt.Sprintf(groupQuery, "default", "g2"):      `{"id": "g2", "display_name": "g2"}`,
+		fmt.Sprintf(securityPoliciesQuery, "default"): `{"results": [{"id": "p1"}], "result_count": 1}`,
+		fmt.Sprintf(gatewayPoliciesQuery, "default"):  `{"results": [{"id": "gp1"}], "result_count": 1}`,
+	}
+	failed := map[string]bool{
+		fmt.Sprintf(groupQuery, "default", "g1"):               true,
+		fmt.Sprintf(securityPolicyRulesQuery, "default", "p1"): true,
+		fmt.Sprintf(gatewayPolicyRulesQuery, "default", "gp1"): true,
+	}
+	server := NewServerDataWithClient(queryClient(func(query string) ([]byte, error) {
+		if failed[query] {
+			return nil, newAPIError(http.MethodGet, query, http.StatusInternalServerError, nil)
+		}
+		if res, ok := responses[query]; ok {
+			return []byte(res), nil
+		}
+		return []byte(`{"results": [], "result_count": 0}`), nil
+	}))
+
+	_, err := CollectResources(server)
+	require.ErrorContains(t, err, "failed with status 500")
+
+	res, err := CollectResources(server.WithContinueOnError(true))
+	require.Nil(t, err)
+	domainResources := res.DomainList[0].Resources
+	require.Len(t, domainResources.GroupList, 2)
+	require.Len(t, domainResources.SecurityPolicyList, 1)
+	require.Len(t, domainResources.GatewayPolicyList, 1)
+	// a single warning per failed item
+	require.Equal(t, []CollectionWarning{
+		{Kind: kindGroup, ID: "g1", StatusCode: http.StatusInternalServerError,
+			Error: "GET " + fmt.Sprintf(groupQuery, "default", "g1") + " failed with status 500 Internal Server Error"},
+		{Kind: kindSecurityPolicy, ID: "p1", StatusCode: http.StatusInternalServerError,
+			Error: "GET " + fmt.Sprintf(securityPolicyRulesQuery, "default", "p1") + " failed with status 500 Internal Server Error"},
+		{Kind: kindGatewayPolicy, ID: "gp1", StatusCode: http.StatusInternalServerError,
+			Error: "GET " + fmt.Sprintf(gatewayPolicyRulesQuery, "default", "gp1") + " failed with status 500 Internal Server Error"},
+	}, res.CollectionWarnings)
+
+	// the warnings are kept in the dump
+	s, err := res.ToJSONString()
+	require.Nil(t, err)
+	parsed, err := FromJSONString([]byte(s))
+	require.Nil(t, err)
+	require.Equal(t, res.CollectionWarnings, parsed.CollectionWarnings)
+
+	// a canceled collection fails even when continuing on errors
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = CollectResources(NewServerData("http://localhost:1", "", "", false).WithContext(ctx).WithContinueOnError(true))
+	require.ErrorIs(t, err, context.Canceled)
+}
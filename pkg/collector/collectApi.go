@@ -8,17 +8,14 @@ package collector
 
 import (
 	"bytes"
-	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"net/http"
 	"strings"
 	"time"
 
 	nsx "github.com/np-guard/vmware-analyzer/pkg/configuration/generated"
-	"github.com/np-guard/vmware-analyzer/pkg/logging"
 )
 
 const rateTimeLimit = 200 * time.Millisecond
@@ -101,10 +98,10 @@ func DeleteResource(server ServerData, query string) error {
 }
 
 func curlGetRequest(server ServerData, query string) ([]byte, error) {
-	return curlRequest(server, query, http.MethodGet, "", http.NoBody)
+	return server.client.Get(server.context(), query)
 }
 func curlDeleteRequest(server ServerData, query string) ([]byte, error) {
-	return curlRequest(server, query, http.MethodDelete, "", http.NoBody)
+	return server.client.Delete(server.context(), query)
 }
 
 func curlPostRequest(server ServerData, query string, data any) ([]byte, error) {
@@ -112,42 +109,7 @@ func curlPostRequest(server ServerData, query string, data any) ([]byte, error)
 	if err != nil {
 		return nil, err
 	}
-	body := bytes.NewReader(bs)
-	return curlRequest(server, query, http.MethodPost, "application/json", body)
-}
-
-func curlRequest(server ServerData, query, method, contentType string, body io.Reader) ([]byte, error) {
-	// Generated by curl-to-Go: https://mholt.github.io/curl-to-go
-
-	//nolint:gosec // need insecure TLS option for testing and development
-	tr := &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: !server.disableInsecureSkipVerify},
-	}
-	client := &http.Client{Transport: tr}
-
-	//nolint:noctx // no context for testing and development
-	req, err := http.NewRequest(method, server.host+"/"+query, body)
-	logging.Infof("%s %s\n", method, query)
-	if err != nil {
-		return nil, err
-	}
-	if server.user != "" {
-		req.SetBasicAuth(server.user, server.password)
-	}
-	if contentType != "" {
-		req.Header.Set("Content-Type", contentType)
-	}
-	time.Sleep(rateTimeLimit)
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-	b, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-	return b, nil
+	return server.client.Post(server.context(), query, bs)
 }
 
 func unmarshalResultsToList[A any](b []byte) (res []A, totalCount int, cursor string, err error) {
@@ -166,6 +128,28 @@ func unmarshalResultsToList[A any](b []byte) (res []A, totalCount int, cursor st
 	return data.Results, *data.ResultCount, data.Cursor, nil
 }
 
+// APIError is a response of the NSX manager with an error status
+type APIError struct {
+	Method     string
+	Query      string
+	StatusCode int
+	Body       []byte   // the body of the response
+	Errors     []string // the NSX api errors in the body of the response, if any
+}
+
+func newAPIError(method, query string, statusCode int, body []byte) *APIError {
+	apiErrors, _ := TryUnmarshalError(body)
+	return &APIError{Method: method, Query: query, StatusCode: statusCode, Body: body, Errors: apiErrors}
+}
+
+func (e *APIError) Error() string {
+	res := fmt.Sprintf("%s %s failed with status %d %s", e.Method, e.Query, e.StatusCode, http.StatusText(e.StatusCode))
+	if len(e.Errors) > 0 {
+		res += ": " + strings.Join(e.Errors, ", ")
+	}
+	return res
+}
+
 type nestedError struct {
 	ErrorMessage  string        `json:"error_message"`
 	ErrorCode     int           `json:"error_code"`
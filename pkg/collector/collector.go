@@ -7,6 +7,9 @@ SPDX-License-Identifier: Apache-2.0
 package collector
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"slices"
 	"strings"
@@ -42,26 +45,85 @@ const (
 	firewallRuleQuery           = "api/v1/firewall/rules/%d"
 
 	defaultForwardingUpTimer = 5
+
+	// kinds of the items whose collection may fail without failing the whole collection
+	kindGroup             = "group"
+	kindSecurityPolicy    = "security policy"
+	kindGatewayPolicy     = "gateway policy"
+	kindRedirectionPolicy = "redirection policy"
 )
 
 var supportedMembersTypes = []string{
 	"Segment", "SegmentPort",
 	"VirtualMachine", "VirtualNetworkInterface",
-	"IPAddress", "TransportNode", "Group"}
+	"IPAddress", "TransportNode", "Group",
+	// identity (AD user) groups are taken from the identity group expressions of their group; their definitions
+	// and users are not collected
+	"IdentityGroup"}
+
+// UnsupportedMembersQueries maps member types which are not supported to the group members queries listing them,
+// to count them. Other unsupported member types, e.g. Kubernetes pods, cannot be listed.
+var UnsupportedMembersQueries = map[string]string{
+	"PhysicalServer":             "physical-servers",
+	"MACAddress":                 "mac-addresses",
+	"LogicalPort":                "logical-ports",
+	"LogicalSwitch":              "logical-switches",
+	"CloudNativeServiceInstance": "cloud-native-service-instances",
+}
 
 type ServerData struct {
-	host, user, password      string
-	disableInsecureSkipVerify bool
+	client          Client
+	ctx             context.Context
+	continueOnError bool
 }
 
 func NewServerData(host, user, password string, disableInsecureSkipVerify bool) ServerData {
-	return ServerData{host, user, password, disableInsecureSkipVerify}
+	// without options, creating the client cannot fail
+	client, _ := newHTTPClient(host, user, password, disableInsecureSkipVerify)
+	return ServerData{client: client}
+}
+
+// NewServerDataWithClient returns the server data of an NSX manager accessed by the given client,
+// e.g. a recording or replay client
+func NewServerDataWithClient(client Client) ServerData {
+	return ServerData{client: client}
+}
+
+// WithContext returns a copy of the server data whose requests are sent with the given context,
+// thus canceling the context cancels the collection
+func (server ServerData) WithContext(ctx context.Context) ServerData {
+	server.ctx = ctx
+	return server
+}
+
+// WithContinueOnError returns a copy of the server data whose collection continues upon failures to collect
+// individual groups and policies, recording them as collection warnings in the collected resources
+func (server ServerData) WithContinueOnError(continueOnError bool) ServerData {
+	server.continueOnError = continueOnError
+	return server
 }
 
-func ValidateNSXConnection(host, user, password string, disableInsecureSkipVerify bool) (string, error) {
+func (server ServerData) context() context.Context {
+	if server.ctx == nil {
+		return context.Background()
+	}
+	return server.ctx
+}
+
+// Client returns the client accessing the NSX manager
+func (server ServerData) Client() Client {
+	return server.client
+}
+
+func ValidateNSXConnection(ctx context.Context, host, user, password string, disableInsecureSkipVerify bool,
+	opts ...HTTPClientOption) (string, error) {
+	client, err := NewHTTPClient(host, user, password, disableInsecureSkipVerify, opts...)
+	if err != nil {
+		return "", err
+	}
 	res := NewResourcesContainerModel()
 	// vms:
-	err := collectResultList(NewServerData(host, user, password, disableInsecureSkipVerify), virtualMachineQuery, &res.VirtualMachineList)
+	err = collectResultList(NewServerDataWithClient(client).WithContext(ctx), virtualMachineQuery, &res.VirtualMachineList)
 	if err != nil {
 		return "", err
 	}
@@ -137,45 +199,7 @@ func CollectResources(server ServerData) (*ResourcesContainerModel, error) {
 		}
 		for i := range domainResources.GroupList {
 			group := &domainResources.GroupList[i]
-			err = collectResource(server, fmt.Sprintf(groupQuery, domainID, *domainResources.GroupList[i].Id), group)
-			if err != nil {
-				return nil, err
-			}
-			var memberTypes []string
-			if err := collectResultList(server, fmt.Sprintf(groupMemberTypesQuery, domainID, *domainResources.GroupList[i].Id),
-				&memberTypes); err != nil {
-				return nil, err
-			}
-			nonSuppoertedTypes := slices.DeleteFunc(memberTypes, func(t string) bool { return slices.Contains(supportedMembersTypes, t) })
-			if len(nonSuppoertedTypes) > 0 {
-				logging.Warnf("collecting [%s] for group %s are not supported", strings.Join(nonSuppoertedTypes, ","), *group.DisplayName)
-			}
-			if err := collectResultList(server, fmt.Sprintf(groupMembersQuery, domainID, *domainResources.GroupList[i].Id,
-				"virtual-machines"), &group.VMMembers); err != nil {
-				return nil, err
-			}
-			if err := collectResultList(server, fmt.Sprintf(groupMembersQuery, domainID, *domainResources.GroupList[i].Id,
-				"vifs"), &group.VIFMembers); err != nil {
-				return nil, err
-			}
-			if err := collectResultList(server, fmt.Sprintf(groupMembersQuery, domainID, *domainResources.GroupList[i].Id,
-				"ip-addresses"), &group.AddressMembers); err != nil {
-				return nil, err
-			}
-			if err := collectResultList(server, fmt.Sprintf(groupMembersQuery, domainID, *domainResources.GroupList[i].Id,
-				"segments"), &group.Segments); err != nil {
-				return nil, err
-			}
-			if err := collectResultList(server, fmt.Sprintf(groupMembersQuery, domainID, *domainResources.GroupList[i].Id,
-				"segment-ports"), &group.SegmentPorts); err != nil {
-				return nil, err
-			}
-			if err := collectResultList(server, fmt.Sprintf(groupMembersQuery, domainID, *domainResources.GroupList[i].Id,
-				"ip-groups"), &group.IPGroups); err != nil {
-				return nil, err
-			}
-			if err := collectResultList(server, fmt.Sprintf(groupMembersQuery, domainID, *domainResources.GroupList[i].Id,
-				"transport-nodes"), &group.TransportNodes); err != nil {
+			if err := res.itemErr(server, kindGroup, *group.Id, collectGroup(server, domainID, group)); err != nil {
 				return nil, err
 			}
 		}
@@ -187,63 +211,15 @@ func CollectResources(server ServerData) (*ResourcesContainerModel, error) {
 			return nil, err
 		}
 		for si := range domainResources.SecurityPolicyList {
-			err = collectResource(server,
-				fmt.Sprintf(securityPolicyRulesQuery, domainID, *domainResources.SecurityPolicyList[si].Id),
-				&domainResources.SecurityPolicyList[si])
-			if err != nil {
+			policy := &domainResources.SecurityPolicyList[si]
+			if err := res.itemErr(server, kindSecurityPolicy, *policy.Id, collectSecurityPolicy(server, domainID, policy)); err != nil {
 				return nil, err
 			}
-			if domainResources.SecurityPolicyList[si].DefaultRuleId != nil {
-				domainResources.SecurityPolicyList[si].DefaultRule = &FirewallRule{}
-				err = collectResource(server,
-					fmt.Sprintf(firewallRuleQuery, *domainResources.SecurityPolicyList[si].DefaultRuleId),
-					domainResources.SecurityPolicyList[si].DefaultRule)
-				if err != nil {
-					return nil, err
-				}
-			}
-			for ri := range domainResources.SecurityPolicyList[si].Rules {
-				err = collectResource(server,
-					fmt.Sprintf(securityPolicyRuleQuery, domainID,
-						*domainResources.SecurityPolicyList[si].Id, *domainResources.SecurityPolicyList[si].Rules[ri].Id),
-					&domainResources.SecurityPolicyList[si].Rules[ri])
-				if err != nil {
-					return nil, err
-				}
-				domainResources.SecurityPolicyList[si].Rules[ri].FirewallRule = &FirewallRule{}
-				err = collectResource(server,
-					fmt.Sprintf(firewallRuleQuery,
-						*domainResources.SecurityPolicyList[si].Rules[ri].RuleId),
-					domainResources.SecurityPolicyList[si].Rules[ri].FirewallRule)
-				if err != nil {
-					return nil, err
-				}
-			}
 		}
 		// gateway policies:
-		err = collectResultList(server,
-			fmt.Sprintf(gatewayPoliciesQuery, domainID),
-			&domainResources.GatewayPolicyList)
-		if err != nil {
+		if err := collectGatewayPolicies(server, res, domainID, domainResources); err != nil {
 			return nil, err
 		}
-		for gi := range domainResources.GatewayPolicyList {
-			err = collectResource(server,
-				fmt.Sprintf(gatewayPolicyRulesQuery, domainID, *domainResources.GatewayPolicyList[gi].Id),
-				&domainResources.GatewayPolicyList[gi])
-			if err != nil {
-				return nil, err
-			}
-			for ri := range domainResources.GatewayPolicyList[gi].Rules {
-				err = collectResource(server,
-					fmt.Sprintf(gatewayPolicyRuleQuery, domainID,
-						*domainResources.GatewayPolicyList[gi].Id, *domainResources.GatewayPolicyList[gi].Rules[ri].Id),
-					&domainResources.GatewayPolicyList[gi].Rules[ri])
-				if err != nil {
-					return nil, err
-				}
-			}
-		}
 		// redirection policies:
 		err = collectResultList(server,
 			fmt.Sprintf(redirectionPoliciesQuery, domainID),
@@ -252,48 +228,178 @@ func CollectResources(server ServerData) (*ResourcesContainerModel, error) {
 			return nil, err
 		}
 		for gi := range domainResources.RedirectionPolicyList {
-			err = collectResource(server,
-				fmt.Sprintf(redirectionPolicyRulesQuery, domainID, *domainResources.RedirectionPolicyList[gi].Id),
-				&domainResources.RedirectionPolicyList[gi])
-			if err != nil {
+			policy := &domainResources.RedirectionPolicyList[gi]
+			if err := res.itemErr(server, kindRedirectionPolicy, *policy.Id, collectRedirectionPolicy(server, domainID, policy)); err != nil {
 				return nil, err
 			}
-			for ri := range domainResources.RedirectionPolicyList[gi].RedirectionRules {
-				err = collectResource(server,
-					fmt.Sprintf(redirectionPolicyRuleQuery, domainID,
-						*domainResources.RedirectionPolicyList[gi].Id, *domainResources.RedirectionPolicyList[gi].RedirectionRules[ri].Id),
-					&domainResources.RedirectionPolicyList[gi].RedirectionRules[ri])
-				if err != nil {
-					return nil, err
-				}
-			}
 		}
-		err = collectResultList(server,
-			fmt.Sprintf(gatewayPoliciesQuery, domainID),
-			&domainResources.GatewayPolicyList)
+	}
+	FixResourcesForJSON(res)
+	return res, nil
+}
+
+// itemErr returns the error of collecting an item, e.g. a group or a policy. If the collection continues on errors,
+// the error is recorded as a collection warning instead, and the item is kept as collected so far.
+// Errors of a canceled collection are always returned.
+func (resources *ResourcesContainerModel) itemErr(server ServerData, kind, id string, err error) error {
+	if err == nil || !server.continueOnError || server.context().Err() != nil {
+		return err
+	}
+	logging.Warnf("failed collecting %s %s, continuing: %s", kind, id, err.Error())
+	warning := CollectionWarning{Kind: kind, ID: id, Error: err.Error()}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		warning.StatusCode = apiErr.StatusCode
+	}
+	resources.CollectionWarnings = append(resources.CollectionWarnings, warning)
+	return nil
+}
+
+// collectGroup collects the definition and the members of a group
+func collectGroup(server ServerData, domainID string, group *Group) error {
+	err := collectResource(server, fmt.Sprintf(groupQuery, domainID, *group.Id), group)
+	if err != nil {
+		return err
+	}
+	var memberTypes []string
+	if err := collectResultList(server, fmt.Sprintf(groupMemberTypesQuery, domainID, *group.Id), &memberTypes); err != nil {
+		return err
+	}
+	nonSuppoertedTypes := slices.DeleteFunc(memberTypes, func(t string) bool { return slices.Contains(supportedMembersTypes, t) })
+	if len(nonSuppoertedTypes) > 0 {
+		logging.Warnf("collecting [%s] for group %s are not supported", strings.Join(nonSuppoertedTypes, ","), group.Name())
+	}
+	group.UnsupportedMembers = nil
+	for _, memberType := range nonSuppoertedTypes {
+		count, err := countUnsupportedMembers(server, domainID, *group.Id, memberType)
 		if err != nil {
-			return nil, err
+			return err
 		}
-		for gi := range domainResources.GatewayPolicyList {
-			err = collectResource(server,
-				fmt.Sprintf(gatewayPolicyRulesQuery, domainID, *domainResources.GatewayPolicyList[gi].Id),
-				&domainResources.GatewayPolicyList[gi])
-			if err != nil {
-				return nil, err
-			}
-			for ri := range domainResources.GatewayPolicyList[gi].Rules {
-				err = collectResource(server,
-					fmt.Sprintf(gatewayPolicyRuleQuery, domainID,
-						*domainResources.GatewayPolicyList[gi].Id, *domainResources.GatewayPolicyList[gi].Rules[ri].Id),
-					&domainResources.GatewayPolicyList[gi].Rules[ri])
-				if err != nil {
-					return nil, err
-				}
-			}
+		group.UnsupportedMembers = append(group.UnsupportedMembers, UnsupportedMember{Type: memberType, Count: count})
+	}
+	if err := collectResultList(server, fmt.Sprintf(groupMembersQuery, domainID, *group.Id,
+		"virtual-machines"), &group.VMMembers); err != nil {
+		return err
+	}
+	if err := collectResultList(server, fmt.Sprintf(groupMembersQuery, domainID, *group.Id,
+		"vifs"), &group.VIFMembers); err != nil {
+		return err
+	}
+	if err := collectResultList(server, fmt.Sprintf(groupMembersQuery, domainID, *group.Id,
+		"ip-addresses"), &group.AddressMembers); err != nil {
+		return err
+	}
+	if err := collectResultList(server, fmt.Sprintf(groupMembersQuery, domainID, *group.Id,
+		"segments"), &group.Segments); err != nil {
+		return err
+	}
+	if err := collectResultList(server, fmt.Sprintf(groupMembersQuery, domainID, *group.Id,
+		"segment-ports"), &group.SegmentPorts); err != nil {
+		return err
+	}
+	if err := collectResultList(server, fmt.Sprintf(groupMembersQuery, domainID, *group.Id,
+		"ip-groups"), &group.IPGroups); err != nil {
+		return err
+	}
+	return collectResultList(server, fmt.Sprintf(groupMembersQuery, domainID, *group.Id,
+		"transport-nodes"), &group.TransportNodes)
+}
+
+// countUnsupportedMembers returns the number of members of an unsupported type in a group, or nil if it is unknown:
+// the members of the type cannot be listed, or the NSX manager failed to list them
+func countUnsupportedMembers(server ServerData, domainID, groupID, memberType string) (*int, error) {
+	membersQuery, ok := UnsupportedMembersQueries[memberType]
+	if !ok {
+		return nil, nil
+	}
+	var members []json.RawMessage
+	err := collectResultList(server, fmt.Sprintf(groupMembersQuery, domainID, groupID, membersQuery), &members)
+	var apiErr *APIError
+	switch {
+	case errors.As(err, &apiErr):
+		logging.Debugf("failed counting %s members of group %s: %s", memberType, groupID, err.Error())
+		return nil, nil
+	case err != nil:
+		return nil, err
+	}
+	count := len(members)
+	return &count, nil
+}
+
+// collectSecurityPolicy collects the rules of a security policy, with their firewall rules
+func collectSecurityPolicy(server ServerData, domainID string, policy *SecurityPolicy) error {
+	err := collectResource(server, fmt.Sprintf(securityPolicyRulesQuery, domainID, *policy.Id), policy)
+	if err != nil {
+		return err
+	}
+	if policy.DefaultRuleId != nil {
+		policy.DefaultRule = &FirewallRule{}
+		err = collectResource(server, fmt.Sprintf(firewallRuleQuery, *policy.DefaultRuleId), policy.DefaultRule)
+		if err != nil {
+			return err
 		}
 	}
-	FixResourcesForJSON(res)
-	return res, nil
+	for ri := range policy.Rules {
+		err = collectResource(server, fmt.Sprintf(securityPolicyRuleQuery, domainID, *policy.Id, *policy.Rules[ri].Id),
+			&policy.Rules[ri])
+		if err != nil {
+			return err
+		}
+		policy.Rules[ri].FirewallRule = &FirewallRule{}
+		err = collectResource(server, fmt.Sprintf(firewallRuleQuery, *policy.Rules[ri].RuleId), policy.Rules[ri].FirewallRule)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func collectGatewayPolicies(server ServerData, res *ResourcesContainerModel, domainID string, domainResources *DomainResources) error {
+	err := collectResultList(server,
+		fmt.Sprintf(gatewayPoliciesQuery, domainID),
+		&domainResources.GatewayPolicyList)
+	if err != nil {
+		return err
+	}
+	for gi := range domainResources.GatewayPolicyList {
+		policy := &domainResources.GatewayPolicyList[gi]
+		if err := res.itemErr(server, kindGatewayPolicy, *policy.Id, collectGatewayPolicy(server, domainID, policy)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// collectGatewayPolicy collects the rules of a gateway policy
+func collectGatewayPolicy(server ServerData, domainID string, policy *GatewayPolicy) error {
+	err := collectResource(server, fmt.Sprintf(gatewayPolicyRulesQuery, domainID, *policy.Id), policy)
+	if err != nil {
+		return err
+	}
+	for ri := range policy.Rules {
+		err = collectResource(server, fmt.Sprintf(gatewayPolicyRuleQuery, domainID, *policy.Id, *policy.Rules[ri].Id),
+			&policy.Rules[ri])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// collectRedirectionPolicy collects the rules of a redirection policy
+func collectRedirectionPolicy(server ServerData, domainID string, policy *RedirectionPolicy) error {
+	err := collectResource(server, fmt.Sprintf(redirectionPolicyRulesQuery, domainID, *policy.Id), policy)
+	if err != nil {
+		return err
+	}
+	for ri := range policy.RedirectionRules {
+		err = collectResource(server, fmt.Sprintf(redirectionPolicyRuleQuery, domainID, *policy.Id, *policy.RedirectionRules[ri].Id),
+			&policy.RedirectionRules[ri])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func collcetPolicyNats(server ServerData, tierQuery, tID string, policyNats *[]PolicyNat) error {
@@ -27,6 +27,7 @@ const (
 	segmentPortMembersJSONEntry    = "segment_port_members"
 	transpoertNodeMembersJSONEntry = "transport_node_members"
 	ipGroupMembersJSONEntry        = "ip_group_members"
+	unsupportedMembersJSONEntry    = "unsupported_members"
 	expressionJSONEntry            = "expression"
 	expressionsJSONEntry           = "expressions"
 	resourcesJSONEntry             = "resources"
@@ -36,6 +37,7 @@ const (
 	firewallRuleJSONEntry          = "firewall_rule"
 	segmentPortsJSONEntry          = "segment_ports"
 	policyNatsJSONEntry            = "policy_nats"
+	vcenterInfoJSONEntry           = "vcenter_info"
 )
 
 type Rule struct {
@@ -333,6 +335,32 @@ func (service *Service) UnmarshalJSON(b []byte) error {
 
 type VirtualMachine struct {
 	nsx.VirtualMachine
+	VCenterInfo *VCenterVMInfo `json:"vcenter_info,omitempty"`
+}
+
+func (vm *VirtualMachine) UnmarshalJSON(b []byte) error {
+	return UnmarshalBaseStructAnd1Field(b, &vm.VirtualMachine, vcenterInfoJSONEntry, &vm.VCenterInfo)
+}
+
+// VCenterVMInfo is the vCenter inventory data of a VM, which is not available from NSX.
+// It is joined to the NSX VM by the VM BIOS or instance UUID
+type VCenterVMInfo struct {
+	ID               string            `json:"id"` // the vCenter identifier of the VM, e.g. "vm-42"
+	GuestOS          string            `json:"guest_os,omitempty"`
+	CPUCount         int               `json:"cpu_count,omitempty"`
+	MemoryMiB        int               `json:"memory_mib,omitempty"`
+	PowerState       string            `json:"power_state,omitempty"` // POWERED_ON, POWERED_OFF or SUSPENDED
+	Folder           string            `json:"folder,omitempty"`
+	CustomAttributes map[string]string `json:"custom_attributes,omitempty"`
+	NICs             []VCenterNIC      `json:"nics,omitempty"`
+}
+
+// VCenterNIC is a network adapter of a vCenter VM, with the port group (or other network) backing it
+type VCenterNIC struct {
+	Label       string `json:"label,omitempty"`
+	MACAddress  string `json:"mac_address,omitempty"`
+	Network     string `json:"network,omitempty"`      // the name of the backing network, e.g. the port group name
+	NetworkType string `json:"network_type,omitempty"` // e.g. STANDARD_PORTGROUP, DISTRIBUTED_PORTGROUP or OPAQUE_NETWORK
 }
 type VirtualNetworkInterface struct {
 	nsx.VirtualNetworkInterface
@@ -511,8 +539,20 @@ type IdentityGroupExpression struct {
 }
 
 func (e *IdentityGroupExpression) String() string {
-	return toImplementFunc(string(nsx.IdentityGroupExpressionResourceTypeIdentityGroupExpression))
-} // todo
+	return "identity groups: " + common.JoinCustomStrFuncSlice(e.IdentityGroups, identityGroupName, common.CommaSpaceSeparator)
+}
+
+// identityGroupName returns the distinguished name of an AD group, or its SID if the name is not set
+func identityGroupName(g nsx.IdentityGroupInfo) string {
+	switch {
+	case g.DistinguishedName != nil:
+		return *g.DistinguishedName
+	case g.Sid != nil:
+		return *g.Sid
+	default:
+		return ""
+	}
+}
 
 type Expression []ExpressionElement
 
@@ -599,11 +639,27 @@ type Group struct {
 	IPGroups       []nsx.PolicyGroupMemberDetails `json:"ip_group_members,omitempty"`
 	TransportNodes []nsx.PolicyGroupMemberDetails `json:"transport_node_members,omitempty"`
 
+	// members of types which are not supported, e.g. physical servers or Kubernetes pods, are not collected but counted
+	UnsupportedMembers []UnsupportedMember `json:"unsupported_members,omitempty"`
+
 	Expression Expression `json:"expression,omitempty"`
 }
 
+// UnsupportedMember is a type of group members which are not supported, with their number if it is known
+type UnsupportedMember struct {
+	Type  string `json:"type"`
+	Count *int   `json:"count,omitempty"`
+}
+
+func (m UnsupportedMember) String() string {
+	if m.Count == nil {
+		return m.Type
+	}
+	return fmt.Sprintf("%s (%d)", m.Type, *m.Count)
+}
+
 func (group *Group) UnmarshalJSON(b []byte) error {
-	return UnmarshalBaseStructAnd8Fields(b, &group.Group,
+	err := UnmarshalBaseStructAnd8Fields(b, &group.Group,
 		membersJSONEntry, &group.VMMembers,
 		vifMembersJSONEntry, &group.VIFMembers,
 		addressMembersJSONEntry, &group.AddressMembers,
@@ -613,6 +669,44 @@ func (group *Group) UnmarshalJSON(b []byte) error {
 		transpoertNodeMembersJSONEntry, &group.TransportNodes,
 		expressionJSONEntry, &group.Expression,
 	)
+	if err != nil {
+		return err
+	}
+	return Unmarshal2Fields(b, unsupportedMembersJSONEntry, &group.UnsupportedMembers, "", nilWithType)
+}
+
+// IdentityGroups returns the AD groups of the identity group expressions of the group, including nested expressions.
+// The members of an identity group are the users in these AD groups, rather than VMs.
+func (group *Group) IdentityGroups() []nsx.IdentityGroupInfo {
+	return group.Expression.identityGroups()
+}
+
+// IsIdentityGroup returns whether the group is an identity group, whose members are users in AD groups
+func (group *Group) IsIdentityGroup() bool {
+	return len(group.IdentityGroups()) > 0
+}
+
+// IdentityGroupsNames returns the names of the AD groups of the group
+func (group *Group) IdentityGroupsNames() []string {
+	return common.CustomStrSliceToStrings(group.IdentityGroups(), identityGroupName)
+}
+
+func (e *Expression) identityGroups() []nsx.IdentityGroupInfo {
+	var res []nsx.IdentityGroupInfo
+	for _, el := range *e {
+		switch expr := el.(type) {
+		case *IdentityGroupExpression:
+			res = append(res, expr.IdentityGroupExpression.IdentityGroups...)
+		case *NestedExpression:
+			res = append(res, expr.Expressions.identityGroups()...)
+		}
+	}
+	return res
+}
+
+// IsPartiallyUnderstood returns whether the group has members of unsupported types, which are ignored by the analysis
+func (group *Group) IsPartiallyUnderstood() bool {
+	return len(group.UnsupportedMembers) > 0
 }
 
 func (group *Group) IsGroupTypeIPAddress() bool {
@@ -1,6 +1,7 @@
 package collector
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
@@ -19,20 +20,27 @@ func getNSXArg(arg *string, envVar string) error {
 	return nil
 }
 
-func GetNSXServerDate(host, user, password string, disableInsecureSkipVerify bool) (ServerData, error) {
+// GetNSXServerDate returns the server data of the NSX manager given by args or env vars.
+// With a client certificate (e.g. of a principal identity) the user and password are optional.
+func GetNSXServerDate(host, user, password string, disableInsecureSkipVerify bool, opts ...HTTPClientOption) (ServerData, error) {
 	// extract NSX credentials from cli args / env vars
 	if err := getNSXArg(&host, "NSX_HOST"); err != nil {
 		return ServerData{}, err
 	}
-	if err := getNSXArg(&user, "NSX_USER"); err != nil {
-		return ServerData{}, err
+	userErr := getNSXArg(&user, "NSX_USER")
+	passwordErr := getNSXArg(&password, "NSX_PASSWORD")
+	if os.Getenv("NSX_DISABLE_SKIP_VERIFY") == "true" {
+		disableInsecureSkipVerify = true
 	}
-	if err := getNSXArg(&password, "NSX_PASSWORD"); err != nil {
+	client, err := newHTTPClient(host, user, password, disableInsecureSkipVerify, opts...)
+	if err != nil {
 		return ServerData{}, err
 	}
-	if os.Getenv("NSX_DISABLE_SKIP_VERIFY") == "true" {
-		disableInsecureSkipVerify = true
+	if !client.hasClientCertificate() {
+		if err := errors.Join(userErr, passwordErr); err != nil {
+			return ServerData{}, err
+		}
 	}
 	logging.Infof("collecting NSX resources from given host %s", host)
-	return NewServerData(host, user, password, disableInsecureSkipVerify), nil
+	return NewServerDataWithClient(client), nil
 }
@@ -0,0 +1,36 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package collector
+
+import (
+	"sync"
+	"time"
+)
+
+// RequestObserver is notified upon completion of each REST API request to the NSX manager, e.g. for exporting metrics.
+// statusCode is 0 if no response was received, and err is the error of the request, if any.
+type RequestObserver func(method string, statusCode int, duration time.Duration, err error)
+
+var (
+	requestObserverLock sync.RWMutex
+	requestObserver     RequestObserver
+)
+
+// SetRequestObserver sets the observer of the REST API requests to the NSX manager (nil to unset it)
+func SetRequestObserver(observer RequestObserver) {
+	requestObserverLock.Lock()
+	defer requestObserverLock.Unlock()
+	requestObserver = observer
+}
+
+func observeRequest(method string, statusCode int, duration time.Duration, err error) {
+	requestObserverLock.RLock()
+	defer requestObserverLock.RUnlock()
+	if requestObserver != nil {
+		requestObserver(method, statusCode, duration, err)
+	}
+}
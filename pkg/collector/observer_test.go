@@ -0,0 +1,50 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package collector
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestObserver(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer ts.Close()
+
+	var methods []string
+	var codes []int
+	SetRequestObserver(func(method string, statusCode int, duration time.Duration, err error) {
+		methods = append(methods, method)
+		codes = append(codes, statusCode)
+		require.Nil(t, err, "a response with an error status is observed without a request error")
+		require.Positive(t, duration)
+	})
+	defer SetRequestObserver(nil)
+
+	_, err := curlGetRequest(NewServerData(ts.URL, "", "", false), virtualMachineQuery)
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	require.Equal(t, []string{http.MethodGet}, methods)
+	require.Equal(t, []int{http.StatusNotFound}, codes)
+
+	// no response is observed with status code 0 and the request error
+	SetRequestObserver(func(_ string, statusCode int, _ time.Duration, err error) {
+		codes = append(codes, statusCode)
+		require.NotNil(t, err)
+	})
+	ts.Close()
+	_, err = curlGetRequest(NewServerData(ts.URL, "", "", false), virtualMachineQuery)
+	require.NotNil(t, err)
+	require.Equal(t, []int{http.StatusNotFound, 0}, codes)
+}
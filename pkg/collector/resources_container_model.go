@@ -23,6 +23,16 @@ type ResourcesContainerModel struct {
 	Tier0List                   []Tier0                   `json:"tier0"`
 	Tier1List                   []Tier1                   `json:"tier1"`
 	DomainList                  []Domain                  `json:"domains"`
+	CollectionWarnings          []CollectionWarning       `json:"collection_warnings,omitempty"`
+}
+
+// CollectionWarning is a failure to collect an item (a group or a policy), which did not fail the whole collection.
+// The item is kept as collected until the failure, thus it may be partial.
+type CollectionWarning struct {
+	Kind       string `json:"kind"`
+	ID         string `json:"id,omitempty"`
+	StatusCode int    `json:"status_code,omitempty"` // the status of the failed NSX API request, if it had a response
+	Error      string `json:"error,omitempty"`
 }
 type DomainResources struct {
 	SecurityPolicyList    []SecurityPolicy    `json:"security_policies"`
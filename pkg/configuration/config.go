@@ -23,6 +23,7 @@ func ConfigFromResourcesContainer(resources *collector.ResourcesContainerModel,
 		return nil, err
 	}
 	config := parser.getConfig()
+	config.warnOnPartiallyUnderstoodGroups()
 
 	// in debug/verbose mode -- print the parsed config
 	logging.Infof("the parsed config details: %s", config.getConfigInfoStr(params.Color))
@@ -88,6 +89,7 @@ func (c *Config) getConfigInfoStr(color bool) string {
 	c.getVMGroupsStr(sections, color)
 	c.getGroupDefinitions(sections, color)
 	c.getDFWInfoStr(sections, color)
+	c.getRedirectionInfoStr(sections, color)
 
 	return sections.GenerateSectionsString()
 }
@@ -163,6 +165,15 @@ func (c *Config) getDFWInfoStr(sections *common.SectionsOutput, color bool) {
 	sections.AddSection(section, content)
 }
 
+func (c *Config) getRedirectionInfoStr(sections *common.SectionsOutput, color bool) {
+	if len(c.FW.RedirectionRules) == 0 {
+		return
+	}
+	section := "Redirection (service insertion):"
+	content := c.FW.RedirectionRulesStrFormatted(color)
+	sections.AddSection(section, content)
+}
+
 func (c *Config) getIPRangeInfoStr(sections *common.SectionsOutput, color bool) {
 	section := "IP Ranges info:"
 	header := []string{"Total", "Internal", "External"}
@@ -44,10 +44,11 @@ func (c *CategorySpec) GetOutboundEffectiveRules() []*EvaluatedFWRule {
 // addRule adds a FWRule from input fields to list of category's original rules + adds relevant inbound/outbound evaluated rules
 // for the list of evaluated rules (and effective rules if the input rule is considered effective)
 func (c *CategorySpec) addRule(src, dst, scope *RuleEndpoints, conn *netset.TransportSet, action, direction string, ruleID int,
-	origRule *collector.Rule, secPolicyName string, origDefaultRule *collector.FirewallRule) {
+	origRule *collector.Rule, secPolicy *SecPolicyAttributes, origDefaultRule *collector.FirewallRule) {
 	// create FWRule object from input field values
-	newRule := NewFwRule(src, dst, scope, conn, actionFromString(action), direction, origRule, origDefaultRule, ruleID,
-		secPolicyName, c.Category.String(), c, c.dfwRef, len(c.rules))
+	ruleAction, origAction := actionFromString(action)
+	newRule := NewFwRule(src, dst, scope, conn, ruleAction, origAction, direction, origRule, origDefaultRule, ruleID,
+		secPolicy, c.Category.String(), c, c.dfwRef, len(c.rules))
 
 	// add FWRule object to list of original rules
 	c.rules = append(c.rules, newRule)
@@ -82,6 +83,15 @@ type EvaluatedFWRule struct {
 	IsEffective bool
 }
 
+// EvaluatedSrcAndDstVMs returns the src and dst VMs of the evaluated rule, considering scope;
+// for an inbound rule the dst VMs are restricted by scope, and for an outbound rule the src VMs are restricted by scope.
+func (e *EvaluatedFWRule) EvaluatedSrcAndDstVMs() (src, dst []topology.Endpoint) {
+	if e.Direction == string(nsx.RuleDirectionIN) {
+		return e.RuleObj.Src.VMs, e.OperatesOn
+	}
+	return e.OperatesOn, e.RuleObj.Dst.VMs
+}
+
 func (e *EvaluatedFWRule) CapturesPair(src, dst topology.Endpoint) bool {
 	if e.Direction == string(nsx.RuleDirectionIN) {
 		return e.RuleObj.Src.ContainsEndpoint(src) && slices.Contains(e.OperatesOn, dst)
@@ -151,9 +161,8 @@ func (c *CategorySpec) potentialRedundantRules(rulesPerDirection []*EvaluatedFWR
 
 	// iterate inbound and outbound effective rules separately
 	for i, ruleI := range rulesPerDirection {
-		// todo: should consider src/dst with scope from evaluated rule instead
-		srcObj := ruleI.RuleObj.Src
-		dstObj := ruleI.RuleObj.Dst
+		// src/dst VMs are considered with scope from the evaluated rule
+		srcVMs, dstVMs := ruleI.EvaluatedSrcAndDstVMs()
 		conn := ruleI.RuleObj.Conn
 		coveringRules := []int{}
 
@@ -167,7 +176,7 @@ func (c *CategorySpec) potentialRedundantRules(rulesPerDirection []*EvaluatedFWR
 		}
 
 		// hc of ruleI
-		ruleIHC := netset.NewDiscreteEndpointsTrafficSet(vmsToIntervalSet(srcObj.VMs), vmsToIntervalSet(dstObj.VMs), conn)
+		ruleIHC := netset.NewDiscreteEndpointsTrafficSet(vmsToIntervalSet(srcVMs), vmsToIntervalSet(dstVMs), conn)
 
 		priorRulesHC := netset.EmptyDiscreteEndpointsTrafficSet()
 
@@ -186,10 +195,8 @@ func (c *CategorySpec) potentialRedundantRules(rulesPerDirection []*EvaluatedFWR
 		for j := range i { // iterate higher-prio rules
 			// look for tuples of (src, dst, conn) already covering rule[i] - action does not matter [match is based on this tupple]
 			ruleJ := rulesPerDirection[j]
-			ruleJHC := netset.NewDiscreteEndpointsTrafficSet(
-				vmsToIntervalSet(ruleJ.RuleObj.Src.VMs),
-				vmsToIntervalSet(ruleJ.RuleObj.Dst.VMs),
-				ruleJ.RuleObj.Conn)
+			srcJVMs, dstJVMs := ruleJ.EvaluatedSrcAndDstVMs()
+			ruleJHC := netset.NewDiscreteEndpointsTrafficSet(vmsToIntervalSet(srcJVMs), vmsToIntervalSet(dstJVMs), ruleJ.RuleObj.Conn)
 			if !ruleIHC.Intersect(ruleJHC).IsEmpty() {
 				coveringRules = append(coveringRules, ruleJ.RuleObj.RuleID)
 			}
@@ -199,7 +206,8 @@ func (c *CategorySpec) potentialRedundantRules(rulesPerDirection []*EvaluatedFWR
 		// after iterating all prior rules - check if they already cover all ruleI tuples
 		delta := ruleIHC.Subtract(priorRulesHC)
 		if delta.IsEmpty() {
-			logging.Debug2f("rule %d (inbound )is potentially redundant, covered by rules: %v", ruleI.RuleObj.RuleID, coveringRules)
+			logging.Debug2f("rule %d (%s) is potentially redundant, covered by rules: %v", ruleI.RuleObj.RuleID, ruleI.Direction,
+				coveringRules)
 			res[ruleI.RuleObj.RuleID] = coveringRules
 		}
 	}
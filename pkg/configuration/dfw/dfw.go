@@ -21,6 +21,8 @@ type DFW struct {
 
 	pathsToDisplayNames map[string]string // map from printing paths references as display names instead
 	AllRulesIDs         []int
+
+	RedirectionRules []*RedirectionRule // ordered list of redirection (service insertion) rules
 }
 
 func (d *DFW) OriginalRulesStrFormatted(color bool) string {
@@ -51,12 +53,12 @@ func (d *DFW) AllEvaluatedRulesDetails() string {
 }
 
 func (d *DFW) AddRule(src, dst, scope *RuleEndpoints, conn *netset.TransportSet, categoryStr, actionStr, direction string,
-	ruleID int, origRule *collector.Rule, secPolicyName string,
+	ruleID int, origRule *collector.Rule, secPolicy *SecPolicyAttributes,
 	origDefaultRule *collector.FirewallRule) {
 	added := false
 	for _, fwCategory := range d.CategoriesSpecs {
 		if fwCategory.Category.String() == categoryStr {
-			fwCategory.addRule(src, dst, scope, conn, actionStr, direction, ruleID, origRule, secPolicyName, origDefaultRule)
+			fwCategory.addRule(src, dst, scope, conn, actionStr, direction, ruleID, origRule, secPolicy, origDefaultRule)
 			added = true
 			d.AllRulesIDs = append(d.AllRulesIDs, ruleID)
 		}
@@ -82,8 +84,13 @@ func (d *DFW) SetPathsToDisplayNames(m map[string]string) {
 // look for rules shadowed by higher-prio rules (single rule or combination of some rules)
 func (d *DFW) redundantRulesAnalysisPerCategory(allVMs []topology.Endpoint, categoryIndex int) (reportLines [][]string) {
 	category := d.CategoriesSpecs[categoryIndex]
-	inboundRedundant := category.potentialRedundantRules(category.GetInboundEffectiveRules(), allVMs)
-	outboundRedundant := category.potentialRedundantRules(category.GetOutboundEffectiveRules(), allVMs)
+	inboundRules := category.GetInboundEffectiveRules()
+	outboundRules := category.GetOutboundEffectiveRules()
+	inboundRedundant := category.potentialRedundantRules(inboundRules, allVMs)
+	outboundRedundant := category.potentialRedundantRules(outboundRules, allVMs)
+	// rules IDs with effective inbound/outbound components (a component may be made empty by the rule's scope)
+	effectiveInbound := rulesIDsSet(inboundRules)
+	effectiveOutbound := rulesIDsSet(outboundRules)
 
 	for rID, ruleObj := range category.rulesMap {
 		inCovering, okIn := inboundRedundant[rID]
@@ -107,7 +114,8 @@ func (d *DFW) redundantRulesAnalysisPerCategory(allVMs []topology.Endpoint, cate
 			}
 
 		case string(nsx.RuleDirectionINOUT):
-			if okIn && okOut {
+			// a direction with no effective component (e.g. empty with scope) does not prevent redundancy of the other one
+			if (okIn || !effectiveInbound[rID]) && (okOut || !effectiveOutbound[rID]) {
 				unionRules := slices.Concat(inCovering, outCovering)
 				sort.IntSlice(unionRules).Sort()
 				unionRules = slices.Compact(unionRules)
@@ -121,9 +129,17 @@ func (d *DFW) redundantRulesAnalysisPerCategory(allVMs []topology.Endpoint, cate
 	return reportLines
 }
 
+func rulesIDsSet(rules []*EvaluatedFWRule) map[int]bool {
+	res := map[int]bool{}
+	for _, r := range rules {
+		res[r.RuleObj.RuleID] = true
+	}
+	return res
+}
+
 // RedundantRulesAnalysis returns as string a report of possible DFW redundant rules (category-scoped), VMs-based analysis;
 // if all src,dst VMs of a rule R, with R's services, are covered (determined) in higher-priority rules, then we consider R
-// as potentially redundant.
+// as potentially redundant. src,dst VMs are considered within the rule's scope (applied-to), per evaluated inbound/outbound rule.
 // also returns report lines (for testing purposes)
 func (d *DFW) RedundantRulesAnalysis(allVMs []topology.Endpoint, color bool) (report string, reportLines [][]string) {
 	// this report includes shadowed rules
@@ -141,6 +157,61 @@ func (d *DFW) RedundantRulesAnalysis(allVMs []topology.Endpoint, color bool) (re
 	return common.GenerateTableString(reportHeader, reportLines, &common.TableOptions{SortLines: true, Colors: color}), reportLines
 }
 
+// StatefulnessReport lists allow rules of stateless policies, for which responses should be allowed explicitly,
+// and rules of policies with tcp_strict enabled, which drop TCP connections whose 3-way handshake was not observed
+func (d *DFW) StatefulnessReport(color bool) string {
+	var reportHeader = []string{"DFW rule ID", "Security policy", "Description"}
+	var reportLines = [][]string{}
+	for _, category := range d.CategoriesSpecs {
+		for _, rule := range category.rules {
+			switch {
+			case rule.IsStateless() && rule.Action == ActionAllow:
+				reportLines = append(reportLines, []string{rule.RuleIDStr(), rule.SecPolicyName(),
+					"stateless policy: responses are allowed only if explicitly allowed by rules"})
+			case rule.IsTCPStrict() && !rule.Conn.TCPUDPSet().IsEmpty():
+				reportLines = append(reportLines, []string{rule.RuleIDStr(), rule.SecPolicyName(),
+					"tcp strict: TCP connections without observed 3-way handshake are dropped"})
+			}
+		}
+	}
+	if len(reportLines) == 0 {
+		return ""
+	}
+	return common.GenerateTableString(reportHeader, reportLines, &common.TableOptions{SortLines: true, Colors: color})
+}
+
+// UserConditionalRules returns the identity firewall rules, whose sources are users in AD groups
+func (d *DFW) UserConditionalRules() []*FwRule {
+	var res []*FwRule
+	for _, category := range d.CategoriesSpecs {
+		for _, rule := range category.rules {
+			if rule.IsUserConditional() {
+				res = append(res, rule)
+			}
+		}
+	}
+	return res
+}
+
+// UserConditionalRulesReport lists the identity firewall rules, whose parts applying to connections initiated by users
+// in AD groups are not part of the connectivity between VMs
+func (d *DFW) UserConditionalRulesReport(color bool) string {
+	var reportHeader = []string{"DFW rule ID", "Rule", "AD groups", "Destinations", "Services"}
+	var reportLines = [][]string{}
+	for _, rule := range d.UserConditionalRules() {
+		var adGroups []string
+		for _, group := range rule.Src.IdentityGroups {
+			adGroups = append(adGroups, group.IdentityGroupsNames()...)
+		}
+		reportLines = append(reportLines, []string{rule.RuleIDStr(), rule.usersStr(),
+			strings.Join(adGroups, common.CommaSpaceSeparator), rule.getDstString(), rule.servicesString()})
+	}
+	if len(reportLines) == 0 {
+		return ""
+	}
+	return common.GenerateTableString(reportHeader, reportLines, &common.TableOptions{SortLines: true, Colors: color})
+}
+
 func (d *DFW) IneffectiveRulesReport(color bool) string {
 	// this report includes ineffective rules due to empty src/dst/scope...
 	var reportHeader = []string{"Ineffective DFW rule ID", "Description"}
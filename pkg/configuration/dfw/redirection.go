@@ -0,0 +1,85 @@
+package dfw
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/np-guard/models/pkg/netset"
+	"github.com/np-guard/vmware-analyzer/internal/common"
+	"github.com/np-guard/vmware-analyzer/pkg/collector"
+	nsx "github.com/np-guard/vmware-analyzer/pkg/configuration/generated"
+	"github.com/np-guard/vmware-analyzer/pkg/configuration/topology"
+)
+
+// https://dp-downloads.broadcom.com/api-content/apis/API_NTDCRA_001/4.2/html/api_includes/types_RedirectionRule.html
+
+// RedirectionRule captures an NSX redirection (service insertion) rule, by which traffic is redirected to
+// partner service VMs (e.g. IDS/IPS) via a service chain. Redirection rules do not change the permitted connectivity.
+type RedirectionRule struct {
+	RuleID     int
+	Name       string
+	Src        *RuleEndpoints
+	Dst        *RuleEndpoints
+	Scope      *RuleEndpoints
+	Conn       *netset.TransportSet
+	Redirect   bool // REDIRECT action if true, DO_NOT_REDIRECT otherwise
+	Direction  string
+	PolicyName string
+	RedirectTo []string // paths of the service chain/partner service of the rule's policy
+}
+
+// CapturesPair returns true if the rule applies to connections from src to dst
+func (r *RedirectionRule) CapturesPair(src, dst topology.Endpoint) bool {
+	if !r.Src.ContainsEndpoint(src) || !r.Dst.ContainsEndpoint(dst) {
+		return false
+	}
+	if r.Scope.IsAllGroups {
+		return true
+	}
+	// the scope (applied-to) should contain the endpoint on which the rule is enforced
+	switch r.Direction {
+	case string(nsx.RuleDirectionIN):
+		return slices.Contains(r.Scope.VMs, dst)
+	case string(nsx.RuleDirectionOUT):
+		return slices.Contains(r.Scope.VMs, src)
+	default:
+		return slices.Contains(r.Scope.VMs, src) || slices.Contains(r.Scope.VMs, dst)
+	}
+}
+
+func (r *RedirectionRule) actionStr() string {
+	if r.Redirect {
+		return string(nsx.RedirectionRuleActionREDIRECT)
+	}
+	return string(nsx.RedirectionRuleActionDONOTREDIRECT)
+}
+
+func groupsStr(e *RuleEndpoints) string {
+	if e.IsAllGroups {
+		return common.AnyStr
+	}
+	return common.SortedJoinCustomStrFuncSlice(e.Groups,
+		func(g *collector.Group) string { return *g.DisplayName }, common.CommaSeparator)
+}
+
+// AddRedirectionRule adds a redirection rule as last in the ordered list of redirection rules
+func (d *DFW) AddRedirectionRule(r *RedirectionRule) {
+	d.RedirectionRules = append(d.RedirectionRules, r)
+}
+
+// RedirectionRulesStrFormatted returns a table of the redirection rules (empty if there are no such rules)
+func (d *DFW) RedirectionRulesStrFormatted(color bool) string {
+	if len(d.RedirectionRules) == 0 {
+		return ""
+	}
+	header := []string{"ruleID", "ruleName", "src", "dst", "services", "action", "direction", "scope",
+		"redirection-policy", "redirect-to"}
+	lines := make([][]string, len(d.RedirectionRules))
+	for i, r := range d.RedirectionRules {
+		lines[i] = []string{fmt.Sprintf("%d", r.RuleID), r.Name, groupsStr(r.Src), groupsStr(r.Dst), r.Conn.String(),
+			r.actionStr(), r.Direction, groupsStr(r.Scope), r.PolicyName,
+			strings.Join(r.RedirectTo, common.CommaSeparator)}
+	}
+	return "redirection rules:\n" + common.GenerateTableString(header, lines, &common.TableOptions{Colors: color})
+}
@@ -16,28 +16,46 @@ type RuleAction string
 
 const (
 	ActionAllow     RuleAction = "allow"
-	ActionDeny      RuleAction = "deny" // currently not differentiating between "reject" and "drop"
+	ActionDeny      RuleAction = "deny" // the analysis action of both "reject" and "drop" (see FwRule.OrigAction)
 	ActionDrop      RuleAction = "drop"
+	ActionReject    RuleAction = "reject"
 	ActionJumpToApp RuleAction = "jump_to_application"
+	ActionRedirect  RuleAction = "redirect" // the action of redirection rules (see RedirectionRule)
 )
 
 func (r RuleAction) String() string {
 	return string(r)
 }
 
-func actionFromString(s string) RuleAction {
+// actionFromString returns the rule action used for analysis, and the original action as configured in NSX;
+// "drop" and "reject" have the same analysis action (deny), and differ only in their original action.
+func actionFromString(s string) (action, origAction RuleAction) {
 	switch strings.ToLower(s) {
 	case string(ActionAllow):
-		return ActionAllow
-	case string(ActionDeny), "reject", "drop": // TODO: change
-		return ActionDeny
+		return ActionAllow, ActionAllow
+	case string(ActionDeny), string(ActionDrop):
+		return ActionDeny, ActionDrop
+	case string(ActionReject):
+		return ActionDeny, ActionReject
 	case string(ActionJumpToApp):
-		return ActionJumpToApp
+		return ActionJumpToApp, ActionJumpToApp
 	default:
 		panic("invalid input action")
 	}
 }
 
+// SecPolicyAttributes captures attributes of an NSX security policy, which are enforced on all the policy's rules
+type SecPolicyAttributes struct {
+	Name           string
+	SequenceNumber *int // the policy's order within its category (nil if not configured)
+	// Stateless is true if the policy's rules do not track connections state,
+	// thus responses are allowed only if explicitly allowed by the rules
+	Stateless bool
+	// TCPStrict is true if the policy has tcp_strict enabled explicitly, thus TCP packets of a connection
+	// are dropped if its 3-way handshake was not observed (e.g. connections established before a rule change or migration)
+	TCPStrict bool
+}
+
 // FwRule captures original NSX dfw rule object with more relevant info for analysis/synthesis
 type FwRule struct {
 	Src                *RuleEndpoints
@@ -45,11 +63,12 @@ type FwRule struct {
 	Scope              *RuleEndpoints // Scope implies additional condition on any Src and any Dst
 	Conn               *netset.TransportSet
 	Action             RuleAction
-	direction          string //	"IN","OUT",	"IN_OUT"
+	OrigAction         RuleAction // the action as configured in NSX: unlike Action, distinguishes between "drop" and "reject"
+	direction          string     //	"IN","OUT",	"IN_OUT"
 	OrigRuleObj        *collector.Rule
 	origDefaultRuleObj *collector.FirewallRule
 	RuleID             int
-	secPolicyName      string
+	secPolicy          *SecPolicyAttributes
 	secPolicyCategory  string
 	categoryRef        *CategorySpec
 	dfwRef             *DFW
@@ -64,11 +83,12 @@ func NewFwRule(
 	scope *RuleEndpoints,
 	conn *netset.TransportSet,
 	action RuleAction,
+	origAction RuleAction,
 	direction string,
 	origRuleObj *collector.Rule,
 	origDefaultRuleObj *collector.FirewallRule,
 	ruleID int,
-	secPolicyName string,
+	secPolicy *SecPolicyAttributes,
 	secPolicyCategory string,
 	categoryRef *CategorySpec,
 	dfwRef *DFW,
@@ -80,11 +100,12 @@ func NewFwRule(
 		Scope:              scope,
 		Conn:               conn,
 		Action:             action,
+		OrigAction:         origAction,
 		direction:          direction,
 		OrigRuleObj:        origRuleObj,
 		origDefaultRuleObj: origDefaultRuleObj,
 		RuleID:             ruleID,
-		secPolicyName:      secPolicyName,
+		secPolicy:          secPolicy,
 		secPolicyCategory:  secPolicyCategory,
 		categoryRef:        categoryRef,
 		dfwRef:             dfwRef,
@@ -96,6 +117,48 @@ func (f *FwRule) RuleIDStr() string {
 	return common.IntStr(f.RuleID)
 }
 
+// IsStateless returns true if the rule is in a stateless security policy: connections allowed by the rule
+// require their responses to be allowed explicitly as well
+func (f *FwRule) IsStateless() bool {
+	return f.secPolicy.Stateless
+}
+
+// IsTCPStrict returns true if the rule is in a security policy with tcp_strict enabled
+func (f *FwRule) IsTCPStrict() bool {
+	return f.secPolicy.TCPStrict
+}
+
+func (f *FwRule) SecPolicyName() string {
+	return f.secPolicy.Name
+}
+
+// IsReject returns true if the rule denies traffic by rejecting it (TCP RST / ICMP unreachable) rather than dropping it
+func (f *FwRule) IsReject() bool {
+	return f.OrigAction == ActionReject
+}
+
+// IsUserConditional returns true if the rule is an identity firewall rule, whose sources include users in AD groups
+func (f *FwRule) IsUserConditional() bool {
+	return f.OrigRuleObj != nil && len(f.Src.IdentityGroups) > 0
+}
+
+// usersStr returns a description of the users to which an identity firewall rule applies
+func (f *FwRule) usersStr() string {
+	return fmt.Sprintf("%s for users in group %s", actionStrPastTense(f.Action),
+		common.JoinCustomStrFuncSlice(f.Src.IdentityGroups, (*collector.Group).Name, common.CommaSpaceSeparator))
+}
+
+func actionStrPastTense(action RuleAction) string {
+	switch action {
+	case ActionAllow:
+		return "allowed"
+	case ActionDeny:
+		return "denied"
+	default:
+		return action.String()
+	}
+}
+
 func (f *FwRule) IsDenyAll() bool {
 	return f.Action == ActionDeny &&
 		f.Src.IsAllGroups &&
@@ -196,25 +259,29 @@ func (f *FwRule) isRuleEffective(c *CategorySpec) (inbound, outbound bool) {
 		f.ruleWarning("has no effective inbound/outbound component, since its dest-vms component is empty")
 		return false, false
 	}
-	if len(f.Src.VMs) == 0 && len(f.Src.Blocks) == 0 {
+	// the sources of a user-conditional rule may be users only, rather than VMs
+	userConditional := f.IsUserConditional()
+	if len(f.Src.VMs) == 0 && len(f.Src.Blocks) == 0 && !userConditional {
 		c.ineffectiveRules[f.RuleID] = append(c.ineffectiveRules[f.RuleID], emptySrc)
 		f.ruleWarning("has no effective inbound/outbound component, since its src-vms component is empty")
 		return false, false
 	}
-	inbound = true
-	outbound = true
+	inbound = f.hasInboundComponent()
+	outbound = f.hasOutboundComponent()
 	// check inbound with scope
 	newDest := topology.Intersection(f.Dst.VMs, f.Scope.VMs)
-	if len(newDest) == 0 {
+	if inbound && len(newDest) == 0 {
 		c.ineffectiveRules[f.RuleID] = append(c.ineffectiveRules[f.RuleID], "empty dest with scope")
 		f.ruleWarning("has no effective inbound component, since its intersection for dest & scope is empty")
 		inbound = false
 	}
 	// check outbound with scope
 	newSrc := topology.Intersection(f.Src.VMs, f.Scope.VMs)
-	if len(newSrc) == 0 {
-		c.ineffectiveRules[f.RuleID] = append(c.ineffectiveRules[f.RuleID], "empty src with scope")
-		f.ruleWarning("has no effective outbound component, since its intersection for src & scope is empty")
+	if outbound && len(newSrc) == 0 {
+		if !userConditional || len(f.Src.VMs) > 0 {
+			c.ineffectiveRules[f.RuleID] = append(c.ineffectiveRules[f.RuleID], "empty src with scope")
+			f.ruleWarning("has no effective outbound component, since its intersection for src & scope is empty")
+		}
 		outbound = false
 	}
 	return inbound, outbound
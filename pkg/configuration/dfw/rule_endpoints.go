@@ -18,6 +18,9 @@ type RuleEndpoints struct {
 	IsAllGroups bool
 	Blocks      []*topology.RuleIPBlock
 	IsExclude   bool
+	// IdentityGroups are groups with users in AD groups as members (identity firewall); the VMs of their other members,
+	// if any, are part of VMs
+	IdentityGroups []*collector.Group
 }
 
 // topology.Endpoint
@@ -15,6 +15,7 @@ import (
 func (f *EvaluatedFWRule) evaluatedRuleStr() string {
 	lines := []string{ // lines for rule str
 		fmt.Sprintf("rule ID: %d", f.RuleObj.RuleID),
+		fmt.Sprintf("priority: %d (sequence numbers: %s)", f.RuleObj.Priority, f.RuleObj.sequenceNumbersStr()),
 		fmt.Sprintf("Is effective: %t", f.IsEffective),
 		fmt.Sprintf("operates on: %s", common.JoinStringifiedSlice(f.OperatesOn, common.CommaSpaceSeparator)),
 		fmt.Sprintf("direction: %s", f.Direction),
@@ -23,7 +24,10 @@ func (f *EvaluatedFWRule) evaluatedRuleStr() string {
 		fmt.Sprintf("scope interpreted endpoints object: \n%s", f.RuleObj.Scope.String()),
 		fmt.Sprintf("connection: %s", f.RuleObj.Conn.String()),
 		fmt.Sprintf("action: %s", f.RuleObj.Action),
-		fmt.Sprintf("secPolicyName: %s", f.RuleObj.secPolicyName),
+		fmt.Sprintf("original action: %s", f.RuleObj.OrigAction),
+		fmt.Sprintf("secPolicyName: %s", f.RuleObj.SecPolicyName()),
+		fmt.Sprintf("stateless: %t", f.RuleObj.IsStateless()),
+		fmt.Sprintf("tcp strict: %t", f.RuleObj.IsTCPStrict()),
 		fmt.Sprintf("secPolicyCategory: %s", f.RuleObj.secPolicyCategory),
 	}
 
@@ -42,9 +46,27 @@ func getRulesHeader() []string {
 		"scope",
 		"sec-policy",
 		"Category",
+		"priority",
+		"policy/rule seq",
 	}
 }
 
+// sequenceNumbersStr returns the NSX sequence numbers of the rule's policy and of the rule, which determine its priority
+func (f *FwRule) sequenceNumbersStr() string {
+	const missing = "-"
+	seqStr := func(seq *int) string {
+		if seq == nil {
+			return missing
+		}
+		return fmt.Sprintf("%d", *seq)
+	}
+	ruleSeq := missing
+	if f.OrigRuleObj != nil {
+		ruleSeq = seqStr(f.OrigRuleObj.SequenceNumber)
+	}
+	return seqStr(f.secPolicy.SequenceNumber) + "/" + ruleSeq
+}
+
 func (f *FwRule) scopeStr() string {
 	if f.Scope.IsAllGroups {
 		return common.AnyStr
@@ -78,8 +100,10 @@ func (f *FwRule) originalRuleComponentsStr() []string {
 			string(*f.origDefaultRuleObj.Action),
 			string(f.origDefaultRuleObj.Direction),
 			getDefaultRuleScopeStr(f.origDefaultRuleObj),
-			f.secPolicyName,
+			f.SecPolicyName(),
 			f.secPolicyCategory,
+			fmt.Sprintf("%d", f.Priority),
+			f.sequenceNumbersStr(),
 		}
 	}
 
@@ -93,10 +117,12 @@ func (f *FwRule) originalRuleComponentsStr() []string {
 		f.getSrcString(),
 		f.getDstString(),
 		f.servicesString(),
-		string(f.Action), f.direction,
+		string(f.OrigAction), f.direction,
 		f.scopeStr(),
-		f.secPolicyName,
+		f.SecPolicyName(),
 		f.secPolicyCategory,
+		fmt.Sprintf("%d", f.Priority),
+		f.sequenceNumbersStr(),
 	}
 }
 
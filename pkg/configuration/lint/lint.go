@@ -10,8 +10,17 @@ func LintReport(c *configuration.Config, color bool) string {
 	logging.Infof("Lint NSX config - produce redundant DFW rules report:")
 	shadowedRules, _ := c.FW.RedundantRulesAnalysis(c.VMs, color)
 	emptyRules := c.FW.IneffectiveRulesReport(color)
+	res := shadowedRules + "\n" + emptyRules
 	if shadowedRules == "" && emptyRules == "" {
-		return "No redundant DFW rules found."
+		res = "No redundant DFW rules found."
 	}
-	return shadowedRules + "\n" + emptyRules
+	// rules with stateless/tcp-strict semantics
+	if statefulness := c.FW.StatefulnessReport(color); statefulness != "" {
+		res += "\n\nDFW rules with stateless or TCP strict semantics:\n" + statefulness
+	}
+	// groups with members of unsupported types
+	if partialGroups := c.PartiallyUnderstoodGroupsReport(color); partialGroups != "" {
+		res += "\n\n" + configuration.PartiallyUnderstoodGroupsHeader + "\n" + partialGroups
+	}
+	return res
 }
@@ -0,0 +1,22 @@
+package lint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/np-guard/vmware-analyzer/internal/common"
+	"github.com/np-guard/vmware-analyzer/pkg/configuration"
+	"github.com/np-guard/vmware-analyzer/pkg/data"
+)
+
+func TestLintReportSections(t *testing.T) {
+	rc, err := data.ExamplesGeneration(data.ExampleStatelessPolicy, false)
+	require.Nil(t, err)
+	config, err := configuration.ConfigFromResourcesContainer(rc, common.DefaultOutputParameters())
+	require.Nil(t, err)
+	res := LintReport(config, false)
+	// the statefulness section is separated from the redundant rules section, with its own header
+	require.Contains(t, res, "\n\nDFW rules with stateless or TCP strict semantics:\nDFW rule ID")
+	require.Contains(t, res, "stateless policy: responses are allowed only if explicitly allowed by rules")
+}
@@ -1,8 +1,10 @@
 package configuration
 
 import (
+	"maps"
 	"os"
 	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/np-guard/models/pkg/netset"
@@ -78,6 +80,7 @@ func (p *nsxConfigParser) runParser() error {
 	p.storeParsedSegments() // get NSX segments config
 
 	p.storeParsedDFW() // get distributed firewall config
+	p.storeParsedRedirectionRules()
 
 	// additional mappings for more details on log and config fields
 	p.addPathsToDisplayNames()
@@ -96,6 +99,10 @@ func (p *nsxConfigParser) storeParsedVMs() {
 		}
 		vmObj := topology.NewVM(*vm.DisplayName, *vm.ExternalId)
 		vmObj.SetIPAddresses(p.rc.GetVirtualMachineAddresses(*vm.ExternalId))
+		vmObj.SetVCenterInfo(vm.VCenterInfo)
+		if vm.PowerState != nil {
+			vmObj.SetPowerState(string(*vm.PowerState))
+		}
 		for _, tag := range vm.Tags {
 			vmObj.AddTag(tag.Tag)
 			// currently ignoring tag scope
@@ -183,59 +190,199 @@ func (p *nsxConfigParser) storeParsedGroups() {
 
 func (p *nsxConfigParser) storeParsedDFW() {
 	p.configRes.FW = dfw.NewEmptyDFW()
+	// policies and rules are added by their effective order (NSX sequence numbers)
+	for _, secPolicy := range p.sortedSecurityPolicies() {
+		p.storeParsedSecurityPolicy(secPolicy)
+	}
+}
+
+func (p *nsxConfigParser) storeParsedSecurityPolicy(secPolicy *collector.SecurityPolicy) {
+	category := *secPolicy.Category
+	// more fields to consider: unique_id
+	policyAttributes := getSecPolicyAttributes(secPolicy)
+
+	// This policy scope will take precedence over rule level scope (if it specifies an actual scope and not "ANY")
+	policyScope := dfw.RuleEndpoints{}
+	policyScope.VMs, policyScope.Groups = p.getEndpointsFromScopePaths(secPolicy.Scope)
+	policyScope.IsAllGroups = slices.Equal(secPolicy.Scope, []string{anyStr})
+	policyHasScope := !policyScope.IsAllGroups
+
+	for _, rule := range sortedPolicyRules(secPolicy) {
+		r := p.getDFWRule(rule)
+		r.scope = policyScope
+		if !policyHasScope {
+			// if policy scope is not configured, rule's scope takes effect
+			r.scope.IsAllGroups = slices.Equal(rule.Scope, []string{anyStr})
+			r.scope.VMs, r.scope.Groups = p.getEndpointsFromScopePaths(rule.Scope)
+		}
+		r.secPolicy = policyAttributes
+		p.addFWRule(r, category, rule)
+	}
+
+	// add default rule if such is configured
+	if secPolicy.DefaultRule != nil {
+		if secPolicy.ConnectivityPreference == nil || *secPolicy.ConnectivityPreference ==
+			nsx.SecurityPolicyConnectivityPreferenceNONE {
+			logging.Debugf("unexpected default rule with no ConnectivityPreference")
+		}
+
+		defaultRule := p.getDefaultRule(secPolicy)
+		if defaultRule == nil {
+			logging.Debugf("skipping default rule for policy %s\n", *secPolicy.DisplayName)
+		} else {
+			defaultRule.scope = policyScope // default-rule is relevant to a scoped policy only
+			defaultRule.secPolicy = policyAttributes
+			p.addFWRule(defaultRule, category, nil)
+		}
+	}
+}
+
+// sortedSecurityPolicies returns the security policies from all domains, ordered by their sequence numbers
+// (policies without a sequence number are kept last, by their input order)
+func (p *nsxConfigParser) sortedSecurityPolicies() []*collector.SecurityPolicy {
+	res := []*collector.SecurityPolicy{}
 	for i := range p.rc.DomainList {
-		domainRsc := p.rc.DomainList[i].Resources
+		domainRsc := &p.rc.DomainList[i].Resources
 		for j := range domainRsc.SecurityPolicyList {
 			secPolicy := &domainRsc.SecurityPolicyList[j]
 			if secPolicy.Category == nil {
 				continue // skip secPolicy with nil category (add warning)
 			}
-			category := *secPolicy.Category
-			// more fields to consider: sequence_number , stateful,tcp_strict, unique_id
-
-			// This policy scope will take precedence over rule level scope (if it specifies an actual scope and not "ANY")
-			policyScope := dfw.RuleEndpoints{}
-			policyScope.VMs, policyScope.Groups = p.getEndpointsFromScopePaths(secPolicy.Scope)
-			policyScope.IsAllGroups = slices.Equal(secPolicy.Scope, []string{anyStr})
-			policyHasScope := !policyScope.IsAllGroups
-
-			rules := secPolicy.Rules
-			for i := range rules {
-				rule := &rules[i]
-				r := p.getDFWRule(rule)
-				r.scope = policyScope
-				if !policyHasScope {
-					// if policy scope is not configured, rule's scope takes effect
-					r.scope.IsAllGroups = slices.Equal(rule.Scope, []string{anyStr})
-					r.scope.VMs, r.scope.Groups = p.getEndpointsFromScopePaths(rule.Scope)
-				}
-				r.secPolicyName = *secPolicy.DisplayName
-				p.addFWRule(r, category, rule)
-			}
+			res = append(res, secPolicy)
+		}
+	}
+	// ties are checked per category, since the order is relevant only within a category
+	perCategory := map[string][]*collector.SecurityPolicy{}
+	for _, secPolicy := range res {
+		perCategory[*secPolicy.Category] = append(perCategory[*secPolicy.Category], secPolicy)
+	}
+	for category, policies := range perCategory {
+		warnOnSequenceNumberTies(policies, func(sp *collector.SecurityPolicy) *int { return sp.SequenceNumber },
+			func(sp *collector.SecurityPolicy) string { return *sp.DisplayName }, "security policies in category "+category)
+	}
+	return sortedBySequenceNumber(res, func(sp *collector.SecurityPolicy) *int { return sp.SequenceNumber })
+}
 
-			// add default rule if such is configured
-			if secPolicy.DefaultRule != nil {
-				if secPolicy.ConnectivityPreference == nil || *secPolicy.ConnectivityPreference ==
-					nsx.SecurityPolicyConnectivityPreferenceNONE {
-					logging.Debugf("unexpected default rule with no ConnectivityPreference")
-				}
+// sortedPolicyRules returns the rules of the given security policy, ordered by their sequence numbers
+func sortedPolicyRules(secPolicy *collector.SecurityPolicy) []*collector.Rule {
+	res := make([]*collector.Rule, len(secPolicy.Rules))
+	for i := range secPolicy.Rules {
+		res[i] = &secPolicy.Rules[i]
+	}
+	ruleName := func(r *collector.Rule) string {
+		if r.RuleId != nil {
+			return strconv.Itoa(*r.RuleId)
+		}
+		return common.SafePointerDeref(r.DisplayName)
+	}
+	warnOnSequenceNumberTies(res, func(r *collector.Rule) *int { return r.SequenceNumber }, ruleName,
+		"rules in security policy "+*secPolicy.DisplayName)
+	return sortedBySequenceNumber(res, func(r *collector.Rule) *int { return r.SequenceNumber })
+}
 
-				defaultRule := p.getDefaultRule(secPolicy)
-				if defaultRule == nil {
-					logging.Debugf("skipping default rule for policy %s\n", *secPolicy.DisplayName)
-				} else {
-					defaultRule.scope = policyScope // default-rule is relevant to a scoped policy only
-					defaultRule.secPolicyName = *secPolicy.DisplayName
-					p.addFWRule(defaultRule, category, nil)
-				}
+// sortedBySequenceNumber returns a stable sort of the given items by their sequence numbers,
+// where items without a sequence number are placed last
+func sortedBySequenceNumber[T any](items []T, seqNum func(T) *int) []T {
+	res := slices.Clone(items)
+	slices.SortStableFunc(res, func(a, b T) int {
+		seqA, seqB := seqNum(a), seqNum(b)
+		switch {
+		case seqA == nil && seqB == nil:
+			return 0
+		case seqA == nil:
+			return 1
+		case seqB == nil:
+			return -1
+		}
+		return *seqA - *seqB
+	})
+	return res
+}
+
+// warnOnSequenceNumberTies warns on items with the same sequence number, whose relative order in NSX is not deterministic
+func warnOnSequenceNumberTies[T any](items []T, seqNum func(T) *int, name func(T) string, itemsDescription string) {
+	namesPerSeqNum := map[int][]string{}
+	for _, item := range items {
+		if seq := seqNum(item); seq != nil {
+			namesPerSeqNum[*seq] = append(namesPerSeqNum[*seq], name(item))
+		}
+	}
+	for _, seq := range slices.Sorted(maps.Keys(namesPerSeqNum)) {
+		if names := namesPerSeqNum[seq]; len(names) > 1 {
+			logging.Warnf("%s have the same sequence number %d, their order is not deterministic: %s",
+				itemsDescription, seq, strings.Join(names, common.CommaSpaceSeparator))
+		}
+	}
+}
+
+// storeParsedRedirectionRules adds the redirection (service insertion) rules to the DFW, ordered by sequence numbers
+func (p *nsxConfigParser) storeParsedRedirectionRules() {
+	policies := []*collector.RedirectionPolicy{}
+	for i := range p.rc.DomainList {
+		domainRsc := &p.rc.DomainList[i].Resources
+		for j := range domainRsc.RedirectionPolicyList {
+			policies = append(policies, &domainRsc.RedirectionPolicyList[j])
+		}
+	}
+	policies = sortedBySequenceNumber(policies, func(rp *collector.RedirectionPolicy) *int { return rp.SequenceNumber })
+	for _, policy := range policies {
+		policyName := common.SafePointerDeref(policy.DisplayName)
+		policyScope := dfw.RuleEndpoints{}
+		policyScope.VMs, policyScope.Groups = p.getEndpointsFromScopePaths(policy.Scope)
+		policyScope.IsAllGroups = len(policy.Scope) == 0 || slices.Equal(policy.Scope, []string{anyStr})
+		rules := make([]*collector.RedirectionRule, len(policy.RedirectionRules))
+		for i := range policy.RedirectionRules {
+			rules[i] = &policy.RedirectionRules[i]
+		}
+		for _, rule := range sortedBySequenceNumber(rules, func(r *collector.RedirectionRule) *int { return r.SequenceNumber }) {
+			if rule.Action == nil || rule.RuleId == nil || rule.Disabled {
+				logging.Debugf("skipping redirection rule %s of policy %s", common.SafePointerDeref(rule.DisplayName), policyName)
+				continue
 			}
+			scope := policyScope
+			if scope.IsAllGroups && !(len(rule.Scope) == 0 || slices.Equal(rule.Scope, []string{anyStr})) {
+				// if policy scope is not configured, rule's scope takes effect
+				scope.IsAllGroups = false
+				scope.VMs, scope.Groups = p.getEndpointsFromScopePaths(rule.Scope)
+			}
+			// the connections are computed as for a dfw rule with the same services
+			connRule := &collector.Rule{ServiceEntries: rule.ServiceEntries}
+			connRule.Services = rule.Services
+			connRule.RuleId = rule.RuleId
+			p.configRes.FW.AddRedirectionRule(&dfw.RedirectionRule{
+				RuleID:     *rule.RuleId,
+				Name:       common.SafePointerDeref(rule.DisplayName),
+				Src:        p.getEndpointsFromGroupsPaths(rule.SourceGroups, rule.SourcesExcluded),
+				Dst:        p.getEndpointsFromGroupsPaths(rule.DestinationGroups, rule.DestinationsExcluded),
+				Scope:      &scope,
+				Conn:       p.getRuleConnections(connRule),
+				Redirect:   *rule.Action == nsx.RedirectionRuleActionREDIRECT,
+				Direction:  string(rule.Direction),
+				PolicyName: policyName,
+				RedirectTo: policy.RedirectTo,
+			})
 		}
 	}
 }
 
+// getSecPolicyAttributes returns the attributes of the given security policy which apply to all its rules;
+// a policy is stateful by default, and tcp_strict is considered only if explicitly enabled on a stateful policy
+func getSecPolicyAttributes(secPolicy *collector.SecurityPolicy) *dfw.SecPolicyAttributes {
+	res := &dfw.SecPolicyAttributes{Name: *secPolicy.DisplayName, SequenceNumber: secPolicy.SequenceNumber}
+	res.Stateless = secPolicy.Stateful != nil && !*secPolicy.Stateful
+	if secPolicy.TcpStrict != nil && *secPolicy.TcpStrict {
+		if res.Stateless {
+			logging.Debugf("ignoring tcp_strict of stateless policy %s", res.Name)
+		} else {
+			res.TCPStrict = true
+		}
+	}
+	return res
+}
+
 func (p *nsxConfigParser) addFWRule(r *parsedRule, category string, origRule *collector.Rule) {
 	p.configRes.FW.AddRule(&r.src, &r.dst, &r.scope,
-		r.conn, category, r.action, r.direction, r.ruleID, origRule, r.secPolicyName, r.defaultRuleObj)
+		r.conn, category, r.action, r.direction, r.ruleID, origRule, r.secPolicy, r.defaultRuleObj)
 }
 
 func (p *nsxConfigParser) getDefaultRule(secPolicy *collector.SecurityPolicy) *parsedRule {
@@ -281,7 +428,7 @@ type parsedRule struct {
 	conn           *netset.TransportSet
 	direction      string
 	ruleID         int
-	secPolicyName  string
+	secPolicy      *dfw.SecPolicyAttributes
 	defaultRuleObj *collector.FirewallRule
 }
 
@@ -309,6 +456,16 @@ func (p *nsxConfigParser) getEndpointsFromScopePaths(groupsPaths []string) ([]to
 }
 
 func (p *nsxConfigParser) getEndpointsFromGroupsPaths(groupsPaths []string, exclude bool) *dfw.RuleEndpoints {
+	return p.endpointsFromGroupsPaths(groupsPaths, exclude, false)
+}
+
+// getSrcEndpointsFromGroupsPaths is as getEndpointsFromGroupsPaths, for the sources of a dfw rule:
+// identity groups, which are supported by NSX only as sources, make the rule conditional on users rather than VMs
+func (p *nsxConfigParser) getSrcEndpointsFromGroupsPaths(groupsPaths []string, exclude bool) *dfw.RuleEndpoints {
+	return p.endpointsFromGroupsPaths(groupsPaths, exclude, true)
+}
+
+func (p *nsxConfigParser) endpointsFromGroupsPaths(groupsPaths []string, exclude, isSrc bool) *dfw.RuleEndpoints {
 	res := &dfw.RuleEndpoints{}
 	if slices.Contains(groupsPaths, anyStr) {
 		// TODO: if a VM is not within any group, this should not include that VM?
@@ -347,8 +504,13 @@ func (p *nsxConfigParser) getEndpointsFromGroupsPaths(groupsPaths []string, excl
 	res.Groups = make([]*collector.Group, len(groupsPaths))
 	for i, groupPath := range groupsPaths {
 		thisGroupVMs, thisGroup := p.getGroupVMs(groupPath)
-		res.VMs = append(res.VMs, thisGroupVMs...)
 		res.Groups[i] = thisGroup
+		if isSrc && !exclude && thisGroup != nil && thisGroup.IsIdentityGroup() {
+			// the members of the identity part of a group are users rather than VMs, thus the rule is conditional on users
+			// (the VMs of the other members of a mixed group are kept)
+			res.IdentityGroups = append(res.IdentityGroups, thisGroup)
+		}
+		res.VMs = append(res.VMs, thisGroupVMs...)
 	}
 
 	if exclude {
@@ -378,7 +540,7 @@ func (p *nsxConfigParser) getDFWRule(rule *collector.Rule) *parsedRule {
 	// the source groups. If false, the rule applies to the source groups
 	// TODO: handle excluded fields
 	// srcExclude := rule.SourcesExcluded
-	res.src = *p.getEndpointsFromGroupsPaths(srcGroups, rule.SourcesExcluded)
+	res.src = *p.getSrcEndpointsFromGroupsPaths(srcGroups, rule.SourcesExcluded)
 	res.dst = *p.getEndpointsFromGroupsPaths(dstGroups, rule.DestinationsExcluded)
 
 	res.action = string(*rule.Action)
@@ -541,7 +703,7 @@ func (p *nsxConfigParser) getGroupVMs(groupPath string) ([]topology.Endpoint, *c
 // comments for later
 
 // scope := secPolicy.Scope // support ANY at first
-// more fields to consider: sequence_number , stateful,tcp_strict, unique_id
+// more fields to consider: sequence_number, unique_id
 /*
 	If there are multiple policies with the same
 		// sequence number then their order is not deterministic. If a specific order of
@@ -9,6 +9,8 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/np-guard/vmware-analyzer/internal/common"
+	"github.com/np-guard/vmware-analyzer/pkg/data"
 	"github.com/np-guard/vmware-analyzer/pkg/internal/projectpath"
 	"github.com/np-guard/vmware-analyzer/pkg/internal/test_utils"
 	"github.com/np-guard/vmware-analyzer/pkg/logging"
@@ -156,6 +158,24 @@ func TestParser(t *testing.T) {
 	fmt.Println("done")
 }
 
+// validate that policies and rules are added to the DFW by their sequence numbers rather than by input order
+func TestRulesOrderBySequenceNumber(t *testing.T) {
+	rc, err := data.ExamplesGeneration(data.ExampleStatelessPolicy, false)
+	require.Nil(t, err)
+	policies := rc.DomainList[0].Resources.SecurityPolicyList
+	require.Len(t, policies, 2)
+	// the second policy (rules 1005, 1003) precedes the first policy (rule 1004)
+	policies[0].SequenceNumber = common.PointerTo(20)
+	policies[1].SequenceNumber = common.PointerTo(10)
+	// within the second policy, the default-deny rule precedes the allow rule
+	policies[1].Rules[0].SequenceNumber = common.PointerTo(2)
+	policies[1].Rules[1].SequenceNumber = common.PointerTo(1)
+
+	config, err := ConfigFromResourcesContainer(rc, &common.OutputParameters{})
+	require.Nil(t, err)
+	require.Equal(t, []int{1003, 1005, 1004}, config.FW.AllRulesIDs)
+}
+
 /*
 
 
@@ -0,0 +1,95 @@
+package configuration
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/np-guard/vmware-analyzer/internal/common"
+	"github.com/np-guard/vmware-analyzer/pkg/collector"
+	"github.com/np-guard/vmware-analyzer/pkg/logging"
+)
+
+// PartiallyUnderstoodGroupsHeader is the header of the partially understood groups report in the text outputs
+const PartiallyUnderstoodGroupsHeader = "Partially understood groups, the results of the DFW rules referring to them are approximate:"
+
+// PartialGroup is a partially understood group: its members of unsupported types are ignored, thus the results of the
+// rules referring to it are approximate
+type PartialGroup struct {
+	Group   *collector.Group
+	RuleIDs []int // the DFW rules referring to the group, by their sources, destinations or scope
+}
+
+func (g *PartialGroup) String() string {
+	rules := "no rules"
+	if len(g.RuleIDs) > 0 {
+		rules = "rules " + g.ruleIDsStr()
+	}
+	return fmt.Sprintf("partially understood group %s: members of unsupported types [%s] are ignored, results of %s are approximate",
+		g.Group.Name(), common.JoinStringifiedSlice(g.Group.UnsupportedMembers, common.CommaSeparator), rules)
+}
+
+func (g *PartialGroup) ruleIDsStr() string {
+	ids := make([]string, len(g.RuleIDs))
+	for i, id := range g.RuleIDs {
+		ids[i] = strconv.Itoa(id)
+	}
+	return strings.Join(ids, common.CommaSeparator)
+}
+
+// PartiallyUnderstoodGroups returns the groups with members of unsupported types, sorted by name
+func (c *Config) PartiallyUnderstoodGroups() []*PartialGroup {
+	byPath := map[string]*PartialGroup{}
+	var res []*PartialGroup
+	for _, group := range c.Groups {
+		if group.IsPartiallyUnderstood() && group.Path != nil {
+			byPath[*group.Path] = &PartialGroup{Group: group}
+			res = append(res, byPath[*group.Path])
+		}
+	}
+	if len(res) == 0 {
+		return nil
+	}
+	for i := range c.origNSXResources.DomainList {
+		policies := c.origNSXResources.DomainList[i].Resources.SecurityPolicyList
+		for j := range policies {
+			for k := range policies[j].Rules {
+				rule := &policies[j].Rules[k]
+				if rule.RuleId == nil {
+					continue
+				}
+				for _, path := range slices.Concat(policies[j].Scope, rule.SourceGroups, rule.DestinationGroups, rule.Scope) {
+					if g, ok := byPath[path]; ok && !slices.Contains(g.RuleIDs, *rule.RuleId) {
+						g.RuleIDs = append(g.RuleIDs, *rule.RuleId)
+					}
+				}
+			}
+		}
+	}
+	for _, g := range res {
+		slices.Sort(g.RuleIDs)
+	}
+	slices.SortFunc(res, func(a, b *PartialGroup) int { return strings.Compare(a.Group.Name(), b.Group.Name()) })
+	return res
+}
+
+// PartiallyUnderstoodGroupsReport returns a table of the partially understood groups, and of the rules referring to them
+func (c *Config) PartiallyUnderstoodGroupsReport(color bool) string {
+	var reportHeader = []string{"Group", "Unsupported members", "DFW rule IDs"}
+	var reportLines = [][]string{}
+	for _, g := range c.PartiallyUnderstoodGroups() {
+		reportLines = append(reportLines, []string{g.Group.Name(),
+			common.JoinStringifiedSlice(g.Group.UnsupportedMembers, common.CommaSpaceSeparator), g.ruleIDsStr()})
+	}
+	if len(reportLines) == 0 {
+		return ""
+	}
+	return common.GenerateTableString(reportHeader, reportLines, &common.TableOptions{SortLines: true, Colors: color})
+}
+
+func (c *Config) warnOnPartiallyUnderstoodGroups() {
+	for _, g := range c.PartiallyUnderstoodGroups() {
+		logging.Warnf("%s", g.String())
+	}
+}
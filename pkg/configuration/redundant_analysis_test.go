@@ -105,6 +105,30 @@ var rulesTests = []*rulesTest{
 			{"2", "Application", "IN_OUT", "[1]"}, // rule 2 is redundant, covered by rule 1
 		},
 	},
+	{
+		// scope-aware example - higher prio rule is applied to backend only, thus does not cover the outbound of frontend
+		testName: "scoped_rule_not_covering_lower_prio_rule",
+		appRulesList: []data.Rule{
+			{Name: "allowRule", Source: common.AnyStr, Dest: common.AnyStr, Services: services(common.AnyStr), Action: data.Allow,
+				Scope: "backend"}, // 1
+			{Name: "allowRule", Source: "frontend", Dest: "backend", Services: services("SMB"), Action: data.Allow},              // 2
+			{Name: "denyRule", Source: common.AnyStr, Dest: common.AnyStr, Services: services(common.AnyStr), Action: data.Drop}, // 3
+		},
+		expectedRes: [][]string{},
+	},
+	{
+		// scope-aware example - lower prio rule has no outbound component within its scope, and its inbound is covered
+		testName: "scoped_rule_redundant_within_scope",
+		appRulesList: []data.Rule{
+			{Name: "allowRule", Source: "frontend", Dest: "backend", Services: services(common.AnyStr), Action: data.Allow}, // 1
+			{Name: "allowRule", Source: "frontend", Dest: "backend", Services: services("SMB"), Action: data.Allow,
+				Scope: "backend"}, // 2
+			{Name: "denyRule", Source: common.AnyStr, Dest: common.AnyStr, Services: services(common.AnyStr), Action: data.Drop}, // 3
+		},
+		expectedRes: [][]string{
+			{"2", "Application", "IN_OUT", "[1]"}, // rule 2 is redundant within its scope, covered by rule 1
+		},
+	},
 }
 
 func (r *rulesTest) runTest(t *testing.T) {
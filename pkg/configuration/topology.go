@@ -76,6 +76,9 @@ func (p *nsxConfigParser) getSegments() (err error) {
 			if vm, ok := p.configRes.VMsMap[*vni.OwnerVmId]; ok {
 				p.configRes.Topology.VmSegments[vm] = append(p.configRes.Topology.VmSegments[vm], segment)
 				segment.VMs = append(segment.VMs, vm)
+				if vmObj, ok := vm.(*topology.VM); ok {
+					vmObj.AddNIC(&topology.NIC{MACAddress: common.SafePointerDeref(vni.MacAddress), Segment: segment})
+				}
 			}
 		}
 		p.configRes.Topology.allInternalIPBlock = p.configRes.Topology.allInternalIPBlock.Union(segment.Block)
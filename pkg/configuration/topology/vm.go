@@ -5,14 +5,24 @@ import (
 	"strings"
 
 	"github.com/np-guard/vmware-analyzer/internal/common"
+	"github.com/np-guard/vmware-analyzer/pkg/collector"
 )
 
 // VM captures vmware VM with its relevant properties
 type VM struct {
 	name        string
-	uid         string   // NSX UID of this VM
-	tags        []string // NSX tags attached to this VM
-	ipAddresses []string // list of IP addresses of this VM's interfaces
+	uid         string                   // NSX UID of this VM
+	tags        []string                 // NSX tags attached to this VM
+	ipAddresses []string                 // list of IP addresses of this VM's interfaces
+	vcenterInfo *collector.VCenterVMInfo // vCenter inventory data of this VM, if collected
+	powerState  string                   // NSX power state of this VM, e.g. VM_RUNNING
+	nics        []*NIC                   // network interfaces of this VM on NSX segments
+}
+
+// NIC captures a network interface (VIF) of a VM, connected to an NSX segment
+type NIC struct {
+	MACAddress string
+	Segment    *Segment
 }
 
 func (v *VM) ID() string {
@@ -48,6 +58,31 @@ func (v *VM) IPAddressesStr() string {
 }
 func (v *VM) IsExternal() bool { return false }
 
+func (v *VM) SetVCenterInfo(info *collector.VCenterVMInfo) {
+	v.vcenterInfo = info
+}
+
+func (v *VM) SetPowerState(powerState string) {
+	v.powerState = powerState
+}
+
+func (v *VM) PowerState() string {
+	return v.powerState
+}
+
+func (v *VM) AddNIC(nic *NIC) {
+	v.nics = append(v.nics, nic)
+}
+
+func (v *VM) NICs() []*NIC {
+	return v.nics
+}
+
+// VCenterInfo returns the vCenter inventory data of the VM, or nil if it was not collected
+func (v *VM) VCenterInfo() *collector.VCenterVMInfo {
+	return v.vcenterInfo
+}
+
 func (v *VM) AddTag(t string) {
 	if slices.Contains(v.tags, t) {
 		return
@@ -2525,6 +2525,131 @@ func createExampleAppWithGroups2() *Example {
 	return &res
 }
 
+var ExampleAppWithGroupsAdditionalRejectRule = registerExample(createExampleAppWithGroups3())
+
+//nolint:all
+func createExampleAppWithGroups3() *Example {
+	res := *ExampleAppWithGroups
+	res.Policies = slices.Clone(ExampleAppWithGroups.Policies)
+
+	newRules := []Rule{
+		{
+			Name:     "reject-icmp-foo-app-scope",
+			ID:       1029,
+			Source:   "research-app",
+			Dest:     "research-app",
+			Services: []string{"/infra/services/ICMPv4-ALL"},
+			Action:   Reject,
+			Scope:    "foo-app",
+		},
+	}
+
+	// add the above rule as first rule in first category
+	res.Policies[0].Rules = slices.Concat(newRules, res.Policies[0].Rules)
+
+	res.Name = "ExampleAppWithGroupsAdditionalRejectRule"
+	return &res
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// ExampleRedirection has a redirection policy by which SMB (but not HTTP) from A to B goes through a service chain
+var ExampleRedirection = registerExample(&Example{
+	Name: "ExampleRedirection",
+	VMs:  []string{"A", "B"},
+	GroupsByVMs: map[string][]string{
+		frontEnd: {"A"},
+		backEnd:  {"B"},
+	},
+	Policies: []Category{
+		{
+			Name:         "app-x",
+			CategoryType: "Application",
+			Rules: []Rule{
+				{
+					Name:     "allow_smb_and_http_incoming",
+					ID:       1004,
+					Source:   frontEnd,
+					Dest:     backEnd,
+					Services: []string{"/infra/services/SMB", "/infra/services/HTTP"},
+					Action:   Allow,
+				},
+				DefaultDenyRule(denyRuleIDApp),
+			},
+		},
+	},
+	RedirectionPolicies: []Category{
+		{
+			Name:       "ids-redirection",
+			RedirectTo: "/infra/service-chains/ids-chain",
+			Rules: []Rule{
+				{
+					Name:     "do_not_redirect_http",
+					ID:       2001,
+					Source:   frontEnd,
+					Dest:     backEnd,
+					Services: []string{"/infra/services/HTTP"},
+					Action:   DoNotRedirect,
+				},
+				{
+					Name:     "redirect_all_to_backend",
+					ID:       2002,
+					Source:   AnyStr,
+					Dest:     backEnd,
+					Services: []string{AnyStr},
+					Action:   Redirect,
+				},
+			},
+		},
+	},
+})
+
+///////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// ExampleStatelessPolicy has a stateless policy which allows SMB from A to B without allowing the responses,
+// and a tcp-strict policy which allows HTTP from B to A
+var ExampleStatelessPolicy = registerExample(&Example{
+	Name: "ExampleStatelessPolicy",
+	VMs:  []string{"A", "B"},
+	GroupsByVMs: map[string][]string{
+		frontEnd: {"A"},
+		backEnd:  {"B"},
+	},
+	Policies: []Category{
+		{
+			Name:         "stateless-smb",
+			CategoryType: "Application",
+			Stateless:    true,
+			Rules: []Rule{
+				{
+					Name:     "allow_smb_incoming",
+					ID:       1004,
+					Source:   frontEnd,
+					Dest:     backEnd,
+					Services: []string{"/infra/services/SMB"},
+					Action:   Allow,
+				},
+			},
+		},
+		{
+			Name:         "tcp-strict-http",
+			CategoryType: "Application",
+			TCPStrict:    true,
+			Rules: []Rule{
+				{
+					Name:     "allow_http_outgoing",
+					ID:       1005,
+					Source:   backEnd,
+					Dest:     frontEnd,
+					Services: []string{"/infra/services/HTTP"},
+					Action:   Allow,
+				},
+				DefaultDenyRule(denyRuleIDApp),
+			},
+		},
+	},
+})
+
 ///////////////////////////////////////////////////////////////////////////////////////////////////////
 
 var ExampleAppWithGroupsAndSegments = registerExample(&Example{
@@ -39,6 +39,9 @@ type Example struct {
 	// dfw details
 	Policies []Category
 
+	// redirection (service insertion) policies, with rules of REDIRECT/DO_NOT_REDIRECT action
+	RedirectionPolicies []Category
+
 	// additional info about example, relevant for synthesis
 	DisjointGroupsTags [][]string
 
@@ -187,6 +190,7 @@ func ExamplesGeneration(e *Example, override bool) (*collector.ResourcesContaine
 
 	// add dfw
 	res.DomainList[0].Resources.SecurityPolicyList = ToPoliciesList(e.Policies)
+	res.DomainList[0].Resources.RedirectionPolicyList = ToRedirectionPoliciesList(e.RedirectionPolicies)
 	res.ServiceList = getServices()
 
 	// store the example resources object generated as JSON file
@@ -233,6 +237,12 @@ func ToPoliciesList(policies []Category) []collector.SecurityPolicy {
 		newPolicy.Category = &policy.CategoryType
 		newPolicy.DisplayName = &policy.Name
 		newPolicy.Scope = []string{AnyStr} // TODO: add scope as configurable
+		if policy.Stateless {
+			newPolicy.Stateful = common.PointerTo(false)
+		}
+		if policy.TCPStrict {
+			newPolicy.TcpStrict = common.PointerTo(true)
+		}
 		newPolicy.Rules = make([]collector.Rule, len(policy.Rules))
 		newPolicy.SecurityPolicy.Rules = make([]nsx.Rule, len(policy.Rules))
 		// add policy rules
@@ -246,12 +256,34 @@ func ToPoliciesList(policies []Category) []collector.SecurityPolicy {
 	return policiesList
 }
 
+func ToRedirectionPoliciesList(policies []Category) []collector.RedirectionPolicy {
+	policiesList := []collector.RedirectionPolicy{}
+	for _, policy := range policies {
+		newPolicy := collector.RedirectionPolicy{}
+		newPolicy.DisplayName = &policy.Name
+		newPolicy.Scope = []string{AnyStr}
+		if policy.RedirectTo != "" {
+			newPolicy.RedirectTo = []string{policy.RedirectTo}
+		}
+		newPolicy.RedirectionRules = make([]collector.RedirectionRule, len(policy.Rules))
+		for i := range policy.Rules {
+			newPolicy.RedirectionRules[i] = policy.Rules[i].toCollectorRedirectionRule()
+		}
+		policiesList = append(policiesList, newPolicy)
+	}
+	return policiesList
+}
+
 // examples generator
 const (
 	AnyStr    = "ANY"
 	Drop      = "DROP"
+	Reject    = "REJECT"
 	Allow     = "ALLOW"
 	JumpToApp = "JUMP_TO_APPLICATION"
+
+	Redirect      = "REDIRECT"
+	DoNotRedirect = "DO_NOT_REDIRECT"
 )
 
 // example expr struct to ease testing
@@ -409,6 +441,26 @@ func (r *Rule) toCollectorRule() collector.Rule {
 	}
 }
 
+func (r *Rule) toCollectorRedirectionRule() collector.RedirectionRule {
+	rule := r.toCollectorRule()
+	return collector.RedirectionRule{
+		RedirectionRule: nsx.RedirectionRule{
+			DisplayName:          rule.DisplayName,
+			RuleId:               rule.RuleId,
+			Action:               (*nsx.RedirectionRuleAction)(&r.Action),
+			SourceGroups:         rule.SourceGroups,
+			DestinationGroups:    rule.DestinationGroups,
+			SourcesExcluded:      rule.SourcesExcluded,
+			DestinationsExcluded: rule.DestinationsExcluded,
+			Services:             rule.Services,
+			Direction:            nsx.RedirectionRuleDirection(rule.Direction),
+			Scope:                rule.Scope,
+			Description:          rule.Description,
+		},
+		ServiceEntries: rule.ServiceEntries,
+	}
+}
+
 var codeToProtocol = map[int]nsx.L4PortSetServiceEntryL4Protocol{
 	netset.UDPCode: nsx.L4PortSetServiceEntryL4ProtocolUDP,
 	netset.TCPCode: nsx.L4PortSetServiceEntryL4ProtocolTCP,
@@ -493,6 +545,9 @@ type Category struct {
 	Name         string
 	CategoryType string
 	Rules        []Rule
+	Stateless    bool // the policy is stateful unless set
+	TCPStrict    bool
+	RedirectTo   string // the service chain path, relevant only for redirection policies
 	// TODO: add scope, consider other fields
 }
 
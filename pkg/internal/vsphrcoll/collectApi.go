@@ -7,92 +7,76 @@ SPDX-License-Identifier: Apache-2.0
 package vsphrcoll
 
 import (
+	"context"
 	"crypto/tls"
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 
 	"github.com/np-guard/vmware-analyzer/pkg/logging"
 )
 
+const sessionHeader = "vmware-api-session-id"
+
 type serverData struct {
 	server, userName, password string
 	session                    string
-	disableInsecureSkipVerify  bool
+	client                     *http.Client
+	ctx                        context.Context
 }
 
-func (s *serverData) getSession() error {
-	if s.session != "" {
-		return nil
+func newServerData(ctx context.Context, server, userName, password string, disableInsecureSkipVerify bool) *serverData {
+	//nolint:gosec // need insecure TLS option for testing and development
+	tr := &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: !disableInsecureSkipVerify},
+	}
+	return &serverData{
+		server:   strings.TrimSuffix(server, "/"),
+		userName: userName,
+		password: password,
+		client:   &http.Client{Transport: tr},
+		ctx:      ctx,
 	}
-	return curlRequestSession(s)
 }
 
-func collectResource[A any](server *serverData, resourceQuery string, resource A) error {
-	b, err := curlRequest(server, resourceQuery)
-	if err != nil {
-		return err
+func (s *serverData) getSession() error {
+	if s.session != "" {
+		return nil
 	}
-	err = json.Unmarshal(b, resource)
+	b, err := s.request(http.MethodPost, "api/session")
 	if err != nil {
 		return err
 	}
-	return nil
+	// the session id is returned as a json string
+	return json.Unmarshal(b, &s.session)
 }
 
-func curlRequestSession(server *serverData) error {
-	// Generated by curl-to-Go: https://mholt.github.io/curl-to-go
-
-	//nolint:gosec // need insecure TLS option for testing and development
-	tr := &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: !server.disableInsecureSkipVerify},
-	}
-	client := &http.Client{Transport: tr}
-
-	//nolint:noctx // no context for testing and development
-	req, err := http.NewRequest(http.MethodPost, server.server+"/api/session", http.NoBody)
-
-	if err != nil {
+func collectResource[A any](server *serverData, resourceQuery string, resource A) error {
+	if err := server.getSession(); err != nil {
 		return err
 	}
-	auth := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s", server.userName, server.password)))
-	fmt.Println(auth)
-
-	req.Header.Set("Authorization", "Basic "+auth)
-	resp, err := client.Do(req)
+	b, err := server.request(http.MethodGet, resourceQuery)
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
-	if _, err := fmt.Fscanf(resp.Body, "%q", &server.session); err != nil {
-		return err
-	}
-	return nil
+	return json.Unmarshal(b, resource)
 }
 
-func curlRequest(server *serverData, query string) ([]byte, error) {
-	// Generated by curl-to-Go: https://mholt.github.io/curl-to-go
-
-	//nolint:gosec // need insecure TLS option for testing and development
-	tr := &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: !server.disableInsecureSkipVerify},
-	}
-	client := &http.Client{Transport: tr}
-
-	//nolint:noctx // no context for testing and development
-	req, err := http.NewRequest(http.MethodGet, server.server+"/"+query, http.NoBody)
-	logging.Infof("GET %s\n", query)
+// request sends a request to vCenter, authenticated by the session if it was created, and by basic auth otherwise
+func (s *serverData) request(method, query string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(s.ctx, method, s.server+"/"+query, http.NoBody)
 	if err != nil {
 		return nil, err
 	}
-
-	if err := server.getSession(); err != nil {
-		return nil, err
+	logging.Infof("%s %s", method, query)
+	if s.session != "" {
+		req.Header.Set(sessionHeader, s.session)
+	} else {
+		req.SetBasicAuth(s.userName, s.password)
 	}
-	req.Header.Set("vmware-api-session-id", server.session)
-	resp, err := client.Do(req)
+	resp, err := s.client.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -101,5 +85,8 @@ func curlRequest(server *serverData, query string) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return nil, fmt.Errorf("%s %s failed with status %d %s", method, query, resp.StatusCode, strings.TrimSpace(string(b)))
+	}
 	return b, nil
 }
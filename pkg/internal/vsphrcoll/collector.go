@@ -8,11 +8,28 @@ SPDX-License-Identifier: Apache-2.0
 package vsphrcoll
 
 import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
 	"github.com/np-guard/vmware-analyzer/internal/common"
+	"github.com/np-guard/vmware-analyzer/pkg/collector"
+	"github.com/np-guard/vmware-analyzer/pkg/logging"
 )
 
 const (
 	virtualMachineQuery = "api/vcenter/vm"
+	vmsOfFolderQuery    = virtualMachineQuery + "?folders=%s"
+	networkQuery        = "api/vcenter/network"
+	folderQuery         = "api/vcenter/folder?type=VIRTUAL_MACHINE"
+	// custom attributes are available only by the VI/JSON API (vSphere 8.0 U1 and later)
+	customFieldsQuery = "sdk/vim25/8.0.1.0/CustomFieldsManager/CustomFieldsManager/field"
+	customValuesQuery = "sdk/vim25/8.0.1.0/VirtualMachine/%s/customValue"
+
+	biosUUIDPrefix     = "biosUuid:"
+	instanceUUIDPrefix = "instanceUuid:"
 )
 
 type portResource struct {
@@ -41,12 +58,14 @@ type portResource struct {
 }
 
 type vmResource struct {
-	Vm              string
-	Name            string
-	Power_state     string
-	Cpu_count       int
-	Memory_size_MiB int
-	VmInfo          vmInfo
+	Vm               string
+	Name             string
+	Power_state      string
+	Cpu_count        int
+	Memory_size_MiB  int
+	VmInfo           vmInfo
+	Folder           string
+	CustomAttributes map[string]string
 }
 
 type vmInfo struct {
@@ -88,9 +107,35 @@ type vmInfo struct {
 		Allow_guest_control bool
 	}
 }
+
+type networkResource struct {
+	Network string
+	Name    string
+	Type    string
+}
+
+type folderResource struct {
+	Folder string
+	Name   string
+	Type   string
+}
+
+type customFieldDef struct {
+	Key               int
+	Name              string
+	ManagedObjectType string
+}
+
+type customFieldValue struct {
+	Key   int
+	Value string
+}
+
 type ResourcesContainerModel struct {
-	Vms   []vmResource
-	Ports map[string]*portResource
+	Vms      []vmResource
+	Ports    map[string]*portResource
+	Networks []networkResource
+	Folders  []folderResource
 }
 
 func NewResourcesContainerModel() *ResourcesContainerModel {
@@ -104,15 +149,17 @@ func (resources *ResourcesContainerModel) ToJSONString() (string, error) {
 	return common.MarshalJSON(resources)
 }
 
-func CollectResources(nsxServer, userName, password string, disableInsecureSkipVerify bool) (*ResourcesContainerModel, error) {
-	server := &serverData{nsxServer, userName, password, "", disableInsecureSkipVerify}
+// CollectResources collects the VMs of the given vCenter, with their NICs, networks, folders and custom attributes
+func CollectResources(ctx context.Context, server, userName, password string, disableInsecureSkipVerify bool) (
+	*ResourcesContainerModel, error) {
+	vcServer := newServerData(ctx, server, userName, password, disableInsecureSkipVerify)
 	res := NewResourcesContainerModel()
-	err := collectResource(server, virtualMachineQuery, &res.Vms)
+	err := collectResource(vcServer, virtualMachineQuery, &res.Vms)
 	if err != nil {
 		return nil, err
 	}
 	for vi := range res.Vms {
-		err = collectResource(server, virtualMachineQuery+"/"+res.Vms[vi].Vm, &res.Vms[vi].VmInfo)
+		err = collectResource(vcServer, virtualMachineQuery+"/"+res.Vms[vi].Vm, &res.Vms[vi].VmInfo)
 		if err != nil {
 			return nil, err
 		}
@@ -120,5 +167,150 @@ func CollectResources(nsxServer, userName, password string, disableInsecureSkipV
 			res.Ports[p.Backing.Network] = p
 		}
 	}
+	if err := collectResource(vcServer, networkQuery, &res.Networks); err != nil {
+		return nil, err
+	}
+	if err := res.collectFolders(vcServer); err != nil {
+		return nil, err
+	}
+	res.collectCustomAttributes(vcServer)
 	return res, nil
 }
+
+// collectFolders collects the VM folders, and sets the folder of each VM
+func (resources *ResourcesContainerModel) collectFolders(server *serverData) error {
+	if err := collectResource(server, folderQuery, &resources.Folders); err != nil {
+		return err
+	}
+	for _, folder := range resources.Folders {
+		var folderVMs []vmResource
+		if err := collectResource(server, fmt.Sprintf(vmsOfFolderQuery, folder.Folder), &folderVMs); err != nil {
+			return err
+		}
+		for _, folderVM := range folderVMs {
+			if vm := resources.vm(folderVM.Vm); vm != nil {
+				vm.Folder = folder.Name
+			}
+		}
+	}
+	return nil
+}
+
+// collectCustomAttributes sets the custom attributes of each VM.
+// Custom attributes are not available from older vCenter versions, so they are skipped upon failure
+func (resources *ResourcesContainerModel) collectCustomAttributes(server *serverData) {
+	var fields []customFieldDef
+	if err := collectResource(server, customFieldsQuery, &fields); err != nil {
+		logging.Debugf("skipping vCenter custom attributes: %s", err.Error())
+		return
+	}
+	fieldNames := map[int]string{}
+	for _, field := range fields {
+		if field.ManagedObjectType == "" || field.ManagedObjectType == "VirtualMachine" {
+			fieldNames[field.Key] = field.Name
+		}
+	}
+	if len(fieldNames) == 0 {
+		return
+	}
+	for vi := range resources.Vms {
+		var values []customFieldValue
+		if err := collectResource(server, fmt.Sprintf(customValuesQuery, resources.Vms[vi].Vm), &values); err != nil {
+			logging.Debugf("skipping custom attributes of vCenter VM %s: %s", resources.Vms[vi].Name, err.Error())
+			continue
+		}
+		for _, value := range values {
+			if name, ok := fieldNames[value.Key]; ok {
+				if resources.Vms[vi].CustomAttributes == nil {
+					resources.Vms[vi].CustomAttributes = map[string]string{}
+				}
+				resources.Vms[vi].CustomAttributes[name] = value.Value
+			}
+		}
+	}
+}
+
+func (resources *ResourcesContainerModel) vm(id string) *vmResource {
+	for i := range resources.Vms {
+		if resources.Vms[i].Vm == id {
+			return &resources.Vms[i]
+		}
+	}
+	return nil
+}
+
+// EnrichNSXVMs sets the vCenter info of the NSX VMs, by joining them to the vCenter VMs by their BIOS or instance UUID.
+// It returns the number of NSX VMs found in vCenter
+func (resources *ResourcesContainerModel) EnrichNSXVMs(rc *collector.ResourcesContainerModel) int {
+	vmsByUUID := map[string]*vmResource{}
+	for i := range resources.Vms {
+		identity := resources.Vms[i].VmInfo.Identity
+		for _, uuid := range []string{identity.Bios_uuid, identity.Instance_uuid} {
+			if uuid != "" {
+				vmsByUUID[strings.ToLower(uuid)] = &resources.Vms[i]
+			}
+		}
+	}
+	networks := map[string]networkResource{}
+	for _, network := range resources.Networks {
+		networks[network.Network] = network
+	}
+	matched := 0
+	for i := range rc.VirtualMachineList {
+		nsxVM := &rc.VirtualMachineList[i]
+		for _, uuid := range nsxVMUUIDs(nsxVM) {
+			if vm, ok := vmsByUUID[strings.ToLower(uuid)]; ok {
+				nsxVM.VCenterInfo = vm.vcenterInfo(networks)
+				matched++
+				break
+			}
+		}
+		if nsxVM.VCenterInfo == nil {
+			logging.Debugf("NSX VM %s was not found in vCenter", common.SafePointerDeref(nsxVM.DisplayName))
+		}
+	}
+	return matched
+}
+
+// nsxVMUUIDs returns the BIOS and instance UUIDs of an NSX VM, as found in its compute ids and external id
+func nsxVMUUIDs(vm *collector.VirtualMachine) []string {
+	var res []string
+	for _, computeID := range vm.ComputeIds {
+		for _, prefix := range []string{biosUUIDPrefix, instanceUUIDPrefix} {
+			if uuid, ok := strings.CutPrefix(computeID, prefix); ok {
+				res = append(res, uuid)
+			}
+		}
+	}
+	if vm.ExternalId != nil {
+		res = append(res, *vm.ExternalId)
+	}
+	return res
+}
+
+func (vm *vmResource) vcenterInfo(networks map[string]networkResource) *collector.VCenterVMInfo {
+	res := &collector.VCenterVMInfo{
+		ID:               vm.Vm,
+		GuestOS:          vm.VmInfo.Guest_OS,
+		CPUCount:         vm.Cpu_count,
+		MemoryMiB:        vm.Memory_size_MiB,
+		PowerState:       vm.Power_state,
+		Folder:           vm.Folder,
+		CustomAttributes: vm.CustomAttributes,
+	}
+	for _, key := range slices.Sorted(maps.Keys(vm.VmInfo.Nics)) {
+		nic := vm.VmInfo.Nics[key]
+		network := networks[nic.Backing.Network]
+		name := nic.Backing.Network_name
+		if name == "" {
+			name = network.Name
+		}
+		res.NICs = append(res.NICs, collector.VCenterNIC{
+			Label:       nic.Label,
+			MACAddress:  nic.Mac_address,
+			Network:     name,
+			NetworkType: network.Type,
+		})
+	}
+	return res
+}
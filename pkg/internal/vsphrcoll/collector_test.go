@@ -7,12 +7,19 @@ SPDX-License-Identifier: Apache-2.0
 package vsphrcoll
 
 import (
+	"context"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path"
 	"testing"
 
+	"github.com/stretchr/testify/require"
+
 	"github.com/np-guard/vmware-analyzer/internal/common"
+	"github.com/np-guard/vmware-analyzer/pkg/collector"
+	nsx "github.com/np-guard/vmware-analyzer/pkg/configuration/generated"
 )
 
 const (
@@ -48,7 +55,7 @@ func TestCollectResources(t *testing.T) {
 				}
 				tt.args = args{os.Getenv("VSPHERE_HOST"), os.Getenv("VSPHERE_USER"), os.Getenv("VSPHERE_PASSWORD"), true}
 			}
-			got, err := CollectResources(tt.args.server, tt.args.userName, tt.args.password, tt.args.disableInsecureSkipVerify)
+			got, err := CollectResources(context.Background(), tt.args.server, tt.args.userName, tt.args.password, tt.args.disableInsecureSkipVerify)
 			if err != nil {
 				t.Errorf("CollectResources() error = %v", err)
 				return
@@ -70,3 +77,96 @@ func TestCollectResources(t *testing.T) {
 		})
 	}
 }
+
+// vCenter REST API responses of a vCenter with two VMs, one of them in a folder and with a custom attribute
+var vcenterResponses = map[string]string{
+	"/api/vcenter/vm": `[{"vm":"vm-1","name":"web","power_state":"POWERED_ON","cpu_count":2,"memory_size_MiB":4096},
+		{"vm":"vm-2","name":"db","power_state":"POWERED_OFF","cpu_count":4,"memory_size_MiB":8192}]`,
+	"/api/vcenter/vm/vm-1": `{"guest_OS":"RHEL_9_64","name":"web",
+		"identity":{"name":"web","bios_uuid":"4212AAAA-0000-0000-0000-000000000001","instance_uuid":"5012aaaa-0000-0000-0000-000000000001"},
+		"nics":{"4000":{"label":"Network adapter 1","mac_address":"00:50:56:00:00:01",
+			"backing":{"type":"DISTRIBUTED_PORTGROUP","network":"dvportgroup-10"}}}}`,
+	"/api/vcenter/vm/vm-2": `{"guest_OS":"UBUNTU_64","name":"db",
+		"identity":{"name":"db","bios_uuid":"4212aaaa-0000-0000-0000-000000000002","instance_uuid":"5012aaaa-0000-0000-0000-000000000002"},
+		"nics":{}}`,
+	"/api/vcenter/network": `[{"network":"dvportgroup-10","name":"web-pg","type":"DISTRIBUTED_PORTGROUP"}]`,
+	"/api/vcenter/folder":  `[{"folder":"group-v5","name":"production","type":"VIRTUAL_MACHINE"}]`,
+	"/sdk/vim25/8.0.1.0/CustomFieldsManager/CustomFieldsManager/field": `[
+		{"_typeName":"CustomFieldDef","key":101,"name":"owner","managedObjectType":"VirtualMachine"}]`,
+	"/sdk/vim25/8.0.1.0/VirtualMachine/vm-1/customValue": `[{"_typeName":"CustomFieldStringValue","key":101,"value":"team-a"}]`,
+	"/sdk/vim25/8.0.1.0/VirtualMachine/vm-2/customValue": `[]`,
+}
+
+func newVCenterMock(t *testing.T) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/session" {
+			if user, password, ok := r.BasicAuth(); !ok || user != "user" || password != "password" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprint(w, `"session-id"`)
+			return
+		}
+		if r.Header.Get(sessionHeader) != "session-id" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		response := vcenterResponses[r.URL.Path]
+		if r.URL.Path == "/api/vcenter/vm" && r.URL.Query().Get("folders") == "group-v5" {
+			response = `[{"vm":"vm-1","name":"web"}]`
+		}
+		if response == "" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, response)
+	}))
+}
+
+func TestEnrichNSXVMs(t *testing.T) {
+	server := newVCenterMock(t)
+	defer server.Close()
+
+	_, err := CollectResources(context.Background(), server.URL, "user", "wrong", false)
+	require.ErrorContains(t, err, "failed with status 401")
+
+	vc, err := CollectResources(context.Background(), server.URL, "user", "password", false)
+	require.Nil(t, err)
+	require.Len(t, vc.Vms, 2)
+
+	// the first VM is joined by its BIOS UUID (case insensitive), the second by its instance UUID, the third is not in vCenter
+	rc := collector.NewResourcesContainerModel()
+	rc.VirtualMachineList = []collector.VirtualMachine{
+		{VirtualMachine: nsx.VirtualMachine{DisplayName: common.PointerTo("web"),
+			ComputeIds: []string{"moIdOnHost:1", "biosUuid:4212aaaa-0000-0000-0000-000000000001"}}},
+		{VirtualMachine: nsx.VirtualMachine{DisplayName: common.PointerTo("db"),
+			ExternalId: common.PointerTo("5012aaaa-0000-0000-0000-000000000002")}},
+		{VirtualMachine: nsx.VirtualMachine{DisplayName: common.PointerTo("other"),
+			ExternalId: common.PointerTo("5012aaaa-0000-0000-0000-000000000003")}},
+	}
+	require.Equal(t, 2, vc.EnrichNSXVMs(rc))
+
+	require.Equal(t, &collector.VCenterVMInfo{
+		ID:               "vm-1",
+		GuestOS:          "RHEL_9_64",
+		CPUCount:         2,
+		MemoryMiB:        4096,
+		PowerState:       "POWERED_ON",
+		Folder:           "production",
+		CustomAttributes: map[string]string{"owner": "team-a"},
+		NICs: []collector.VCenterNIC{{Label: "Network adapter 1", MACAddress: "00:50:56:00:00:01",
+			Network: "web-pg", NetworkType: "DISTRIBUTED_PORTGROUP"}},
+	}, rc.VirtualMachineList[0].VCenterInfo)
+	require.Equal(t, "vm-2", rc.VirtualMachineList[1].VCenterInfo.ID)
+	require.Equal(t, "POWERED_OFF", rc.VirtualMachineList[1].VCenterInfo.PowerState)
+	require.Empty(t, rc.VirtualMachineList[1].VCenterInfo.Folder)
+	require.Nil(t, rc.VirtualMachineList[2].VCenterInfo)
+
+	// the vCenter info is kept in the resources dump
+	jsonOut, err := rc.ToJSONString()
+	require.Nil(t, err)
+	rc2, err := collector.FromJSONString([]byte(jsonOut))
+	require.Nil(t, err)
+	require.Equal(t, rc.VirtualMachineList[0].VCenterInfo, rc2.VirtualMachineList[0].VCenterInfo)
+}
@@ -0,0 +1,229 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package nsxmock
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/np-guard/vmware-analyzer/internal/common"
+	"github.com/np-guard/vmware-analyzer/pkg/analyzer"
+	"github.com/np-guard/vmware-analyzer/pkg/collector"
+	"github.com/np-guard/vmware-analyzer/pkg/collector/anonymizer"
+	"github.com/np-guard/vmware-analyzer/pkg/runner"
+	"github.com/np-guard/vmware-analyzer/pkg/synthesis/ocpvirt/utils"
+)
+
+// pathFields are the json fields of paths, and of lists of paths, which refer to other resources
+var pathFields = []string{"path", "parent_path", "connectivity_path", "tier0_path", "paths", "source_groups", "destination_groups", "scope"}
+
+// ipPattern matches ip addresses and cidrs, which are anonymized by a permutation rather than renamed
+var ipPattern = regexp.MustCompile(`\d+\.\d+\.\d+\.\d+(/\d+)?`)
+
+// nsxDump returns a copy of resources collected from the mock of an example, with identifiers as of an NSX manager:
+// the paths of the example (the names of its resources) are replaced by NSX paths, and segment ports have unique ids
+// of their own, rather than the ids of their attachments
+func nsxDump(t *testing.T, resources *collector.ResourcesContainerModel) *collector.ResourcesContainerModel {
+	t.Helper()
+	res, err := copyResources(resources)
+	require.Nil(t, err)
+	paths := map[string]string{}
+	setPath := func(path, id *string, format string, args ...any) {
+		if path != nil && id != nil && !strings.HasPrefix(*path, "/") {
+			paths[*path] = fmt.Sprintf(format, append(args, *id)...)
+		}
+	}
+	for i := range res.SegmentList {
+		segment := &res.SegmentList[i]
+		setPath(segment.Path, segment.Id, "/infra/segments/%s")
+		for j := range segment.SegmentPorts {
+			segment.SegmentPorts[j].UniqueId = common.PointerTo(fmt.Sprintf("%s-port-%d", *segment.Id, j))
+		}
+	}
+	for i := range res.Tier0List {
+		setPath(res.Tier0List[i].Path, res.Tier0List[i].Id, "/infra/tier-0s/%s")
+	}
+	for i := range res.Tier1List {
+		setPath(res.Tier1List[i].Path, res.Tier1List[i].Id, "/infra/tier-1s/%s")
+	}
+	for i := range res.DomainList {
+		domain := &res.DomainList[i]
+		domain.Path = common.PointerTo("/infra/domains/" + *domain.Id)
+		for j := range domain.Resources.GroupList {
+			group := &domain.Resources.GroupList[j]
+			setPath(group.Path, group.Id, "/infra/domains/%s/groups/%s", *domain.Id)
+		}
+	}
+	content := toJSONContent(t, res)
+	replacePaths(content, paths)
+	b, err := json.Marshal(content)
+	require.Nil(t, err)
+	res, err = collector.FromJSONString(b)
+	require.Nil(t, err)
+	return res
+}
+
+func replacePaths(content any, paths map[string]string) {
+	replace := func(v any) any {
+		if s, ok := v.(string); ok {
+			if p, ok := paths[s]; ok {
+				return p
+			}
+		}
+		return v
+	}
+	switch c := content.(type) {
+	case map[string]any:
+		for key, v := range c {
+			if slices.Contains(pathFields, key) {
+				c[key] = replace(v)
+				if list, ok := v.([]any); ok {
+					for i := range list {
+						list[i] = replace(list[i])
+					}
+				}
+			}
+			replacePaths(v, paths)
+		}
+	case []any:
+		for _, v := range c {
+			replacePaths(v, paths)
+		}
+	}
+}
+
+func toJSONContent(t *testing.T, object any) any {
+	t.Helper()
+	b, err := json.Marshal(object)
+	require.Nil(t, err)
+	var content any
+	require.Nil(t, json.Unmarshal(b, &content))
+	return content
+}
+
+// anonymizedNames returns a replacer of the values of the anonymized resources by their original values,
+// both as is and as legal k8s strings, as they appear in the analysis and in the generated resources
+func anonymizedNames(t *testing.T, resources, anonymized *collector.ResourcesContainerModel) *strings.Replacer {
+	t.Helper()
+	names := map[string]string{}
+	var collect func(original, anon any)
+	collect = func(original, anon any) {
+		switch a := anon.(type) {
+		case string:
+			if o, ok := original.(string); ok && o != a && !ipPattern.MatchString(a) {
+				names[a] = o
+				names[utils.ToLegalK8SString(a)] = utils.ToLegalK8SString(o)
+			}
+		case map[string]any:
+			if o, ok := original.(map[string]any); ok {
+				for key, v := range a {
+					collect(o[key], v)
+				}
+			}
+		case []any:
+			// slices of ip elements may differ in length, as ip ranges are split to cidrs
+			if o, ok := original.([]any); ok && len(o) == len(a) {
+				for i := range a {
+					collect(o[i], a[i])
+				}
+			}
+		}
+	}
+	collect(toJSONContent(t, resources), toJSONContent(t, anonymized))
+	anonNames := slices.Collect(maps.Keys(names))
+	// longer names first, so a name is not replaced within a longer name
+	slices.SortFunc(anonNames, func(n1, n2 string) int { return cmp.Or(len(n2)-len(n1), strings.Compare(n1, n2)) })
+	oldNew := make([]string, 0, 2*len(anonNames))
+	for _, name := range anonNames {
+		oldNew = append(oldNew, name, names[name])
+	}
+	return strings.NewReplacer(oldNew...)
+}
+
+// canonical returns the given json content with its names replaced, its ip addresses masked, and its lists sorted,
+// so contents which are equal up to the anonymization are equal
+func canonical(content any, names *strings.Replacer) any {
+	switch c := content.(type) {
+	case string:
+		return ipPattern.ReplaceAllString(names.Replace(c), "<ip>")
+	case map[string]any:
+		res := map[string]any{}
+		for key, v := range c {
+			res[canonical(key, names).(string)] = canonical(v, names)
+		}
+		return res
+	case []any:
+		res := make([]any, len(c))
+		for i := range c {
+			res[i] = canonical(c[i], names)
+		}
+		slices.SortFunc(res, func(v1, v2 any) int {
+			b1, _ := json.Marshal(v1)
+			b2, _ := json.Marshal(v2)
+			return bytes.Compare(b1, b2)
+		})
+		return res
+	}
+	return content
+}
+
+// analysisAndSynthesis returns the analyzed connectivity of the given resources, and the k8s resources generated for them
+func analysisAndSynthesis(t *testing.T, resources *collector.ResourcesContainerModel) (connectivity, generated any) {
+	t.Helper()
+	_, _, conns, err := analyzer.NSXConnectivityFromResourcesContainer(resources, &common.OutputParameters{Format: common.JSONFormat})
+	require.Nil(t, err)
+	require.Nil(t, json.Unmarshal([]byte(conns), &connectivity))
+	// external addresses are analyzed by disjoint ip ranges, which a prefix-preserving permutation does not keep,
+	// so only the connectivity between VMs is compared
+	connectivity = slices.DeleteFunc(connectivity.([]any), func(entry any) bool {
+		conn := entry.(map[string]any)
+		return ipPattern.MatchString(conn["src"].(string)) || ipPattern.MatchString(conn["dst"].(string))
+	})
+	r, err := runner.NewRunnerWithOptionsList(
+		runner.WithNSXResources(resources),
+		runner.WithCmd(common.CmdGenerate),
+		runner.WithSynthesisDir(t.TempDir()),
+		runner.WithSynthAdminPolicies(true),
+	)
+	require.Nil(t, err)
+	_, err = r.Run()
+	require.Nil(t, err)
+	return connectivity, toJSONContent(t, r.GetGeneratedResources())
+}
+
+// anonymizing a dump with NSX paths keeps its analyzed connectivity and its generated k8s resources, up to renaming.
+// (examples with ip ranges in rules are not compared, since the ranges are replaced by cidrs, which are synthesized
+// separately)
+func TestAnonymizeCollected(t *testing.T) {
+	for _, name := range []string{"Example1", "ExampleAppWithGroupsAndSegments", "ExampleExprAndConds", "ExampleExprTwoScopes",
+		"ExampleHogwartsExternal", "ExampleInternalWithInterDenyAllowWithSegments"} {
+		t.Run(name, func(t *testing.T) {
+			_, server := newTestServer(t, name)
+			collected, err := collector.CollectResources(server)
+			require.Nil(t, err)
+			resources := nsxDump(t, collected)
+			anonymized := nsxDump(t, collected)
+			require.Nil(t, anonymizer.AnonymizeNsx(anonymized, "key"))
+			names := anonymizedNames(t, resources, anonymized)
+
+			connectivity, generated := analysisAndSynthesis(t, resources)
+			anonConnectivity, anonGenerated := analysisAndSynthesis(t, anonymized)
+			require.NotEqual(t, generated, anonGenerated)
+			require.Equal(t, canonical(connectivity, strings.NewReplacer()), canonical(anonConnectivity, names))
+			require.Equal(t, canonical(generated, strings.NewReplacer()), canonical(anonGenerated, names))
+		})
+	}
+}
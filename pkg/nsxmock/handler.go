@@ -0,0 +1,184 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package nsxmock simulates the REST API of an NSX manager from a dump of NSX resources, serving the Policy and
+// Manager API endpoints used by the collector, and traceflows with results computed by the analyzer.
+package nsxmock
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/np-guard/vmware-analyzer/internal/common"
+	"github.com/np-guard/vmware-analyzer/pkg/collector"
+	"github.com/np-guard/vmware-analyzer/pkg/logging"
+)
+
+// the API endpoints used by the collector
+const (
+	domainsPath                = "policy/api/v1/infra/domains"
+	servicesPath               = "policy/api/v1/infra/services"
+	segmentsPath               = "policy/api/v1/infra/segments"
+	segmentPortsPath           = "policy/api/v1/infra/segments/%s/ports"
+	tier0Path                  = "policy/api/v1/infra/tier-0s"
+	tier1Path                  = "policy/api/v1/infra/tier-1s"
+	tierNatPath                = "%s/%s/nat"
+	tierNatRulePath            = "%s/%s/nat/%s/nat-rules"
+	virtualMachinesPath        = "api/v1/fabric/virtual-machines"
+	virtualInterfacesPath      = "api/v1/fabric/vifs"
+	groupsPath                 = "policy/api/v1/infra/domains/%s/groups"
+	groupPath                  = "policy/api/v1/infra/domains/%s/groups/%s"
+	groupMemberTypesPath       = "policy/api/v1/infra/domains/%s/groups/%s/member-types"
+	groupMembersPath           = "policy/api/v1/infra/domains/%s/groups/%s/members/%s"
+	securityPoliciesPath       = "policy/api/v1/infra/domains/%s/security-policies"
+	securityPolicyPath         = "policy/api/v1/infra/domains/%s/security-policies/%s"
+	securityPolicyRulePath     = "policy/api/v1/infra/domains/%s/security-policies/%s/rules/%s"
+	gatewayPoliciesPath        = "policy/api/v1/infra/domains/%s/gateway-policies"
+	gatewayPolicyPath          = "policy/api/v1/infra/domains/%s/gateway-policies/%s"
+	gatewayPolicyRulePath      = "policy/api/v1/infra/domains/%s/gateway-policies/%s/rules/%s"
+	redirectionPoliciesPath    = "policy/api/v1/infra/domains/%s/redirection-policies"
+	redirectionPolicyPath      = "policy/api/v1/infra/domains/%s/redirection-policies/%s"
+	redirectionPolicyRulePath  = "policy/api/v1/infra/domains/%s/redirection-policies/%s/rules/%s"
+	firewallRulePath           = "api/v1/firewall/rules/%d"
+	traceflowsPath             = "api/v1/traceflows"
+	traceflowPath              = "api/v1/traceflows/%s"
+	traceflowObservationsPath  = "api/v1/traceflows/%s/observations"
+	cursorParam                = "cursor"
+	defaultPageSize            = 1000
+	objectNotFoundErrorCode    = 600
+	invalidRequestErrorCode    = 255
+	contentTypeJSON            = "application/json"
+	readHeaderTimeout          = 10 * time.Second
+	notFoundErrorMessageFormat = "The requested object : %s could not be found. Object identifiers are case sensitive."
+)
+
+// Handler is an http.Handler simulating an NSX manager, e.g. for testing with httptest.NewServer
+type Handler struct {
+	pageSize   int
+	lists      map[string][]json.RawMessage // paged list endpoints, by path
+	objects    map[string]json.RawMessage   // single resource endpoints, by path
+	traceflows *traceflowSimulator
+	mux        *http.ServeMux
+}
+
+// HandlerOption is the type for specifying options for Handler
+type HandlerOption func(*Handler)
+
+// WithPageSize sets the max number of results per page of the list endpoints (paged by a cursor)
+func WithPageSize(pageSize int) HandlerOption {
+	return func(h *Handler) {
+		h.pageSize = pageSize
+	}
+}
+
+// NewHandler returns a Handler serving the given NSX resources. Resources without ids are served with generated ids,
+// and rules without a manager API firewall rule are served with a minimal firewall rule, holding only the rule id.
+func NewHandler(resources *collector.ResourcesContainerModel, opts ...HandlerOption) (*Handler, error) {
+	h := &Handler{pageSize: defaultPageSize, lists: map[string][]json.RawMessage{}, objects: map[string]json.RawMessage{}}
+	for _, o := range opts {
+		o(h)
+	}
+	if h.pageSize < 1 {
+		return nil, fmt.Errorf("invalid page size %d: should be positive", h.pageSize)
+	}
+	resources, err := copyResources(resources)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.addResources(resources); err != nil {
+		return nil, err
+	}
+	h.traceflows = newTraceflowSimulator(resources)
+	h.mux = http.NewServeMux()
+	h.mux.HandleFunc("POST /"+traceflowsPath, h.postTraceflow)
+	h.mux.HandleFunc("GET /"+fmt.Sprintf(traceflowObservationsPath, "{id}"), h.getTraceflowObservations)
+	h.mux.HandleFunc("DELETE /"+fmt.Sprintf(traceflowPath, "{id}"), h.deleteTraceflow)
+	h.mux.HandleFunc("GET /", h.getResource)
+	return h, nil
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+// ListenAndServe serves the simulated NSX API on the given address
+func (h *Handler) ListenAndServe(address string) error {
+	logging.Infof("serving simulated NSX API on %s", address)
+	server := &http.Server{Addr: address, Handler: h, ReadHeaderTimeout: readHeaderTimeout}
+	return server.ListenAndServe()
+}
+
+func (h *Handler) getResource(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/")
+	if object, ok := h.objects[path]; ok {
+		writeJSON(w, http.StatusOK, object)
+		return
+	}
+	list, ok := h.lists[path]
+	if !ok {
+		writeNotFound(w, path)
+		return
+	}
+	h.writePage(w, list, r.URL.Query().Get(cursorParam))
+}
+
+// listPage is the response of a list endpoint; the cursor of the next page is set if there are more results
+type listPage struct {
+	Results     []json.RawMessage `json:"results"`
+	ResultCount int               `json:"result_count"`
+	Cursor      string            `json:"cursor,omitempty"`
+}
+
+func (h *Handler) writePage(w http.ResponseWriter, list []json.RawMessage, cursor string) {
+	start := 0
+	if cursor != "" {
+		var err error
+		if start, err = strconv.Atoi(cursor); err != nil || start < 0 || start > len(list) {
+			writeError(w, http.StatusBadRequest, invalidRequestErrorCode, fmt.Sprintf("Invalid cursor %s", cursor))
+			return
+		}
+	}
+	end := min(start+h.pageSize, len(list))
+	page := listPage{Results: list[start:end], ResultCount: len(list)}
+	if page.Results == nil {
+		page.Results = []json.RawMessage{}
+	}
+	if end < len(list) {
+		page.Cursor = strconv.Itoa(end)
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+// apiError is the error response of the NSX API
+type apiError struct {
+	HTTPStatus   string `json:"httpStatus"`
+	ErrorCode    int    `json:"error_code"`
+	ErrorMessage string `json:"error_message"`
+}
+
+func writeError(w http.ResponseWriter, status, code int, message string) {
+	httpStatus := strings.ToUpper(strings.ReplaceAll(http.StatusText(status), common.Space, "_"))
+	writeJSON(w, status, apiError{HTTPStatus: httpStatus, ErrorCode: code, ErrorMessage: message})
+}
+
+func writeNotFound(w http.ResponseWriter, path string) {
+	writeError(w, http.StatusNotFound, objectNotFoundErrorCode, fmt.Sprintf(notFoundErrorMessageFormat, path))
+}
+
+func writeJSON(w http.ResponseWriter, status int, content any) {
+	b, err := json.Marshal(content)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, 0, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", contentTypeJSON)
+	w.WriteHeader(status)
+	_, _ = w.Write(b)
+}
@@ -0,0 +1,220 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package nsxmock
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/np-guard/vmware-analyzer/internal/common"
+	"github.com/np-guard/vmware-analyzer/pkg/analyzer"
+	"github.com/np-guard/vmware-analyzer/pkg/collector"
+	"github.com/np-guard/vmware-analyzer/pkg/configuration"
+	nsx "github.com/np-guard/vmware-analyzer/pkg/configuration/generated"
+	"github.com/np-guard/vmware-analyzer/pkg/configuration/lint"
+)
+
+const testDataDir = "../data/json"
+
+// handlerClient is a collector client sending the requests directly to a handler, without the rate limit of NSX requests
+type handlerClient struct {
+	handler http.Handler
+}
+
+func (c *handlerClient) Get(ctx context.Context, query string) ([]byte, error) {
+	return c.do(ctx, http.MethodGet, query, nil)
+}
+
+func (c *handlerClient) Post(ctx context.Context, query string, body []byte) ([]byte, error) {
+	return c.do(ctx, http.MethodPost, query, body)
+}
+
+func (c *handlerClient) Delete(ctx context.Context, query string) ([]byte, error) {
+	return c.do(ctx, http.MethodDelete, query, nil)
+}
+
+func (c *handlerClient) do(ctx context.Context, method, query string, body []byte) ([]byte, error) {
+	req := httptest.NewRequestWithContext(ctx, method, "/"+query, bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	c.handler.ServeHTTP(rec, req)
+	return io.ReadAll(rec.Result().Body)
+}
+
+func readResources(t *testing.T, name string) *collector.ResourcesContainerModel {
+	t.Helper()
+	b, err := os.ReadFile(filepath.Join(testDataDir, name+".json"))
+	require.Nil(t, err)
+	resources, err := collector.FromJSONString(b)
+	require.Nil(t, err)
+	return resources
+}
+
+func newTestServer(t *testing.T, name string, opts ...HandlerOption) (*collector.ResourcesContainerModel, collector.ServerData) {
+	t.Helper()
+	h, err := NewHandler(readResources(t, name), opts...)
+	require.Nil(t, err)
+	return readResources(t, name), collector.NewServerDataWithClient(&handlerClient{handler: h})
+}
+
+func TestCollectFromMock(t *testing.T) {
+	for _, name := range []string{"Example1", "ExampleAppWithGroupsAndSegments", "ExampleHogwarts", "ExampleRedirection"} {
+		t.Run(name, func(t *testing.T) {
+			// a small page size for paging the lists of services
+			resources, server := newTestServer(t, name, WithPageSize(50))
+			collected, err := collector.CollectResources(server)
+			require.Nil(t, err)
+
+			require.Equal(t, len(resources.VirtualMachineList), len(collected.VirtualMachineList))
+			require.Equal(t, len(resources.ServiceList), len(collected.ServiceList))
+			require.Equal(t, len(resources.SegmentList), len(collected.SegmentList))
+			_, _, expected, err := analyzer.NSXConnectivityFromResourcesContainer(resources, common.DefaultOutputParameters())
+			require.Nil(t, err)
+			_, _, actual, err := analyzer.NSXConnectivityFromResourcesContainer(collected, common.DefaultOutputParameters())
+			require.Nil(t, err)
+			require.Equal(t, expected, actual)
+		})
+	}
+}
+
+func TestUnsupportedMembers(t *testing.T) {
+	resources := readResources(t, "ExampleHogwarts")
+	// a group referred to by rules
+	group := &resources.DomainList[0].Resources.GroupList[0]
+	count := 2
+	unsupported := []collector.UnsupportedMember{{Type: "PhysicalServer", Count: &count}, {Type: "Pod"}}
+	group.UnsupportedMembers = unsupported
+	h, err := NewHandler(resources)
+	require.Nil(t, err)
+	collected, err := collector.CollectResources(collector.NewServerDataWithClient(&handlerClient{handler: h}))
+	require.Nil(t, err)
+	collectedGroup := &collected.DomainList[0].Resources.GroupList[0]
+	require.Equal(t, unsupported, collectedGroup.UnsupportedMembers)
+	require.Empty(t, collected.DomainList[0].Resources.GroupList[1].UnsupportedMembers)
+
+	config, err := configuration.ConfigFromResourcesContainer(collected, common.DefaultOutputParameters())
+	require.Nil(t, err)
+	partial := config.PartiallyUnderstoodGroups()
+	require.Len(t, partial, 1)
+	require.Equal(t, group.Name(), partial[0].Group.Name())
+	require.NotEmpty(t, partial[0].RuleIDs)
+	require.Contains(t, partial[0].String(), "members of unsupported types [PhysicalServer (2),Pod] are ignored")
+
+	// the partially understood groups are reported by the analysis and lint text outputs
+	_, _, analysisRes, err := analyzer.NSXConnectivityFromResourcesContainer(collected, common.DefaultOutputParameters())
+	require.Nil(t, err)
+	lintRes := lint.LintReport(config, false)
+	for _, res := range []string{analysisRes, lintRes} {
+		require.Contains(t, res, configuration.PartiallyUnderstoodGroupsHeader)
+		require.Contains(t, res, group.Name())
+		require.Contains(t, res, "PhysicalServer (2), Pod")
+	}
+}
+
+func TestMockErrors(t *testing.T) {
+	h, err := NewHandler(readResources(t, "ExampleHogwarts"))
+	require.Nil(t, err)
+	ts := httptest.NewServer(h)
+	defer ts.Close()
+	for _, path := range []string{"/policy/api/v1/infra/domains/default/groups/unknown", "/api/v1/traceflows/unknown/observations"} {
+		resp, err := ts.Client().Get(ts.URL + path)
+		require.Nil(t, err)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+		b, err := io.ReadAll(resp.Body)
+		require.Nil(t, err)
+		resp.Body.Close()
+		apiErrors, err := collector.TryUnmarshalError(b)
+		require.Nil(t, err)
+		require.Len(t, apiErrors, 1)
+	}
+	resp, err := ts.Client().Get(ts.URL + "/" + virtualMachinesPath + "?cursor=abc")
+	require.Nil(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	_, err = NewHandler(readResources(t, "ExampleHogwarts"), WithPageSize(0))
+	require.NotNil(t, err)
+}
+
+func vmAddress(t *testing.T, resources *collector.ResourcesContainerModel, name string) string {
+	t.Helper()
+	vms := resources.GetVMsByNames([]string{name})
+	require.Len(t, vms, 1)
+	addresses := resources.GetVirtualMachineAddresses(*vms[0].ExternalId)
+	require.NotEmpty(t, addresses)
+	return addresses[0]
+}
+
+func TestTraceflows(t *testing.T) {
+	resources, server := newTestServer(t, "ExampleAppWithGroupsAndSegments")
+	tcpHeader := func(dstPort int) *nsx.TransportProtocolHeader {
+		srcPort := 1
+		return &nsx.TransportProtocolHeader{TcpHeader: &nsx.TcpHeader{SrcPort: &srcPort, DstPort: &dstPort}}
+	}
+	tests := []struct {
+		src, dst             string
+		header               *nsx.TransportProtocolHeader
+		expectedObservations []string
+		expectedRules        []int
+	}{
+		{"New-VM-3", "New-VM-4", tcpHeader(80), []string{observationForwarded, observationForwarded, observationDelivered}, []int{1027, 1027}},
+		{"New-VM-3", "New-VM-4", tcpHeader(443), []string{observationDropped}, []int{1028}},
+		{"New-VM-1", "New-VM-2", &nsx.TransportProtocolHeader{IcmpEchoRequestHeader: &nsx.IcmpEchoRequestHeader{}},
+			[]string{observationDropped}, []int{1021}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src+"-"+tt.dst, func(t *testing.T) {
+			srcIP := nsx.IPAddress(vmAddress(t, resources, tt.src))
+			dstIP := nsx.IPAddress(vmAddress(t, resources, tt.dst))
+			request := &collector.TraceflowConfig{Packet: &nsx.FieldsPacketData{
+				IpHeader:        &nsx.Ipv4Header{SrcIp: &srcIP, DstIp: &dstIP},
+				TransportHeader: tt.header,
+			}}
+			response := &collector.TraceflowResponse{}
+			require.Nil(t, collector.PostResource(server, traceflowsPath, request, response))
+			require.NotNil(t, response.ID)
+
+			b, err := server.Client().Get(context.Background(), "api/v1/traceflows/"+*response.ID+"/observations")
+			require.Nil(t, err)
+			page := struct {
+				Results collector.TraceFlowObservations `json:"results"`
+			}{}
+			require.Nil(t, json.Unmarshal(b, &page), "observations should be parsed by the collector")
+			raw := struct {
+				Results []struct {
+					ResourceType string `json:"resource_type"`
+					ACLRuleID    int    `json:"acl_rule_id"`
+				} `json:"results"`
+			}{}
+			require.Nil(t, json.Unmarshal(b, &raw))
+			var observations []string
+			var rules []int
+			for _, o := range raw.Results {
+				observations = append(observations, o.ResourceType)
+				if o.ACLRuleID != 0 {
+					rules = append(rules, o.ACLRuleID)
+				}
+			}
+			require.Equal(t, tt.expectedObservations, observations)
+			require.Equal(t, tt.expectedRules, rules)
+
+			require.Nil(t, collector.DeleteResource(server, "api/v1/traceflows/"+*response.ID))
+			b, err = server.Client().Get(context.Background(), "api/v1/traceflows/"+*response.ID+"/observations")
+			require.Nil(t, err)
+			_, err = collector.TryUnmarshalError(b)
+			require.Nil(t, err, "a deleted traceflow should not be found")
+		})
+	}
+}
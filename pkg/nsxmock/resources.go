@@ -0,0 +1,298 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package nsxmock
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/np-guard/vmware-analyzer/internal/common"
+	"github.com/np-guard/vmware-analyzer/pkg/collector"
+)
+
+// copyResources returns a copy of the resources, with ids set for the resources without ids (e.g. in generated
+// examples), since the collector queries resources by their ids
+func copyResources(resources *collector.ResourcesContainerModel) (*collector.ResourcesContainerModel, error) {
+	b, err := json.Marshal(resources)
+	if err != nil {
+		return nil, err
+	}
+	res, err := collector.FromJSONString(b)
+	if err != nil {
+		return nil, err
+	}
+	for i := range res.SegmentList {
+		setMissingID(&res.SegmentList[i].Id, "segment", i)
+	}
+	for i := range res.Tier0List {
+		setMissingID(&res.Tier0List[i].Id, "tier0", i)
+		setMissingPolicyNatIDs(res.Tier0List[i].PolicyNats)
+	}
+	for i := range res.Tier1List {
+		setMissingID(&res.Tier1List[i].Id, "tier1", i)
+		setMissingPolicyNatIDs(res.Tier1List[i].PolicyNats)
+	}
+	for i := range res.DomainList {
+		domain := &res.DomainList[i]
+		setMissingID(&domain.Id, "domain", i)
+		for j := range domain.Resources.GroupList {
+			setMissingID(&domain.Resources.GroupList[j].Id, "group", j)
+		}
+		for j := range domain.Resources.SecurityPolicyList {
+			policy := &domain.Resources.SecurityPolicyList[j]
+			setMissingID(&policy.Id, "security-policy", j)
+			for k := range policy.Rules {
+				setMissingID(&policy.Rules[k].Id, "rule", k)
+			}
+		}
+		for j := range domain.Resources.GatewayPolicyList {
+			policy := &domain.Resources.GatewayPolicyList[j]
+			setMissingID(&policy.Id, "gateway-policy", j)
+			for k := range policy.Rules {
+				setMissingID(&policy.Rules[k].Id, "rule", k)
+			}
+		}
+		for j := range domain.Resources.RedirectionPolicyList {
+			policy := &domain.Resources.RedirectionPolicyList[j]
+			setMissingID(&policy.Id, "redirection-policy", j)
+			for k := range policy.RedirectionRules {
+				setMissingID(&policy.RedirectionRules[k].Id, "rule", k)
+			}
+		}
+	}
+	return res, nil
+}
+
+func setMissingPolicyNatIDs(policyNats []collector.PolicyNat) {
+	for i := range policyNats {
+		setMissingID(&policyNats[i].Id, "nat", i)
+	}
+}
+
+func setMissingID(id **string, kind string, index int) {
+	if *id == nil {
+		newID := fmt.Sprintf("%s-%d", kind, index)
+		*id = &newID
+	}
+}
+
+func addList[A any](h *Handler, path string, list []A) error {
+	res := make([]json.RawMessage, len(list))
+	for i := range list {
+		b, err := json.Marshal(&list[i])
+		if err != nil {
+			return err
+		}
+		res[i] = b
+	}
+	h.lists[path] = res
+	return nil
+}
+
+func (h *Handler) addObject(path string, object any) error {
+	b, err := json.Marshal(object)
+	if err != nil {
+		return err
+	}
+	h.objects[path] = b
+	return nil
+}
+
+func (h *Handler) addResources(resources *collector.ResourcesContainerModel) error {
+	if err := addList(h, virtualMachinesPath, resources.VirtualMachineList); err != nil {
+		return err
+	}
+	if err := addList(h, virtualInterfacesPath, resources.VirtualNetworkInterfaceList); err != nil {
+		return err
+	}
+	if err := addList(h, servicesPath, resources.ServiceList); err != nil {
+		return err
+	}
+	if err := addList(h, segmentsPath, resources.SegmentList); err != nil {
+		return err
+	}
+	for i := range resources.SegmentList {
+		segment := &resources.SegmentList[i]
+		if err := addList(h, fmt.Sprintf(segmentPortsPath, common.SafePointerDeref(segment.Id)), segment.SegmentPorts); err != nil {
+			return err
+		}
+	}
+	if err := addList(h, tier0Path, resources.Tier0List); err != nil {
+		return err
+	}
+	for i := range resources.Tier0List {
+		if err := h.addPolicyNats(tier0Path, common.SafePointerDeref(resources.Tier0List[i].Id), resources.Tier0List[i].PolicyNats); err != nil {
+			return err
+		}
+	}
+	if err := addList(h, tier1Path, resources.Tier1List); err != nil {
+		return err
+	}
+	for i := range resources.Tier1List {
+		if err := h.addPolicyNats(tier1Path, common.SafePointerDeref(resources.Tier1List[i].Id), resources.Tier1List[i].PolicyNats); err != nil {
+			return err
+		}
+	}
+	if err := addList(h, domainsPath, resources.DomainList); err != nil {
+		return err
+	}
+	for i := range resources.DomainList {
+		if err := h.addDomain(&resources.DomainList[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *Handler) addPolicyNats(tierPath, tierID string, policyNats []collector.PolicyNat) error {
+	if err := addList(h, fmt.Sprintf(tierNatPath, tierPath, tierID), policyNats); err != nil {
+		return err
+	}
+	for i := range policyNats {
+		natID := common.SafePointerDeref(policyNats[i].Id)
+		if err := addList(h, fmt.Sprintf(tierNatRulePath, tierPath, tierID, natID), policyNats[i].Rules); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *Handler) addDomain(domain *collector.Domain) error {
+	domainID := common.SafePointerDeref(domain.Id)
+	resources := &domain.Resources
+	if err := addList(h, fmt.Sprintf(groupsPath, domainID), resources.GroupList); err != nil {
+		return err
+	}
+	for i := range resources.GroupList {
+		if err := h.addGroup(domainID, &resources.GroupList[i]); err != nil {
+			return err
+		}
+	}
+	if err := addList(h, fmt.Sprintf(securityPoliciesPath, domainID), resources.SecurityPolicyList); err != nil {
+		return err
+	}
+	for i := range resources.SecurityPolicyList {
+		if err := h.addSecurityPolicy(domainID, &resources.SecurityPolicyList[i]); err != nil {
+			return err
+		}
+	}
+	if err := addList(h, fmt.Sprintf(gatewayPoliciesPath, domainID), resources.GatewayPolicyList); err != nil {
+		return err
+	}
+	for i := range resources.GatewayPolicyList {
+		policy := &resources.GatewayPolicyList[i]
+		policyID := common.SafePointerDeref(policy.Id)
+		if err := h.addObject(fmt.Sprintf(gatewayPolicyPath, domainID, policyID), policy); err != nil {
+			return err
+		}
+		for j := range policy.Rules {
+			rule := &policy.Rules[j]
+			if err := h.addObject(fmt.Sprintf(gatewayPolicyRulePath, domainID, policyID, common.SafePointerDeref(rule.Id)), rule); err != nil {
+				return err
+			}
+		}
+	}
+	if err := addList(h, fmt.Sprintf(redirectionPoliciesPath, domainID), resources.RedirectionPolicyList); err != nil {
+		return err
+	}
+	for i := range resources.RedirectionPolicyList {
+		policy := &resources.RedirectionPolicyList[i]
+		policyID := common.SafePointerDeref(policy.Id)
+		if err := h.addObject(fmt.Sprintf(redirectionPolicyPath, domainID, policyID), policy); err != nil {
+			return err
+		}
+		for j := range policy.RedirectionRules {
+			rule := &policy.RedirectionRules[j]
+			path := fmt.Sprintf(redirectionPolicyRulePath, domainID, policyID, common.SafePointerDeref(rule.Id))
+			if err := h.addObject(path, rule); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (h *Handler) addGroup(domainID string, group *collector.Group) error {
+	groupID := common.SafePointerDeref(group.Id)
+	if err := h.addObject(fmt.Sprintf(groupPath, domainID, groupID), group); err != nil {
+		return err
+	}
+	// the members of a group by their members query and member type
+	memberLists := []struct {
+		query, memberType string
+		size              int
+		add               func(path string) error
+	}{
+		{"virtual-machines", "VirtualMachine", len(group.VMMembers), func(p string) error { return addList(h, p, group.VMMembers) }},
+		{"vifs", "VirtualNetworkInterface", len(group.VIFMembers), func(p string) error { return addList(h, p, group.VIFMembers) }},
+		{"ip-addresses", "IPAddress", len(group.AddressMembers), func(p string) error { return addList(h, p, group.AddressMembers) }},
+		{"segments", "Segment", len(group.Segments), func(p string) error { return addList(h, p, group.Segments) }},
+		{"segment-ports", "SegmentPort", len(group.SegmentPorts), func(p string) error { return addList(h, p, group.SegmentPorts) }},
+		{"ip-groups", "Group", len(group.IPGroups), func(p string) error { return addList(h, p, group.IPGroups) }},
+		{"transport-nodes", "TransportNode", len(group.TransportNodes), func(p string) error { return addList(h, p, group.TransportNodes) }},
+	}
+	memberTypes := []string{}
+	for _, members := range memberLists {
+		if members.size > 0 {
+			memberTypes = append(memberTypes, members.memberType)
+		}
+		if err := members.add(fmt.Sprintf(groupMembersPath, domainID, groupID, members.query)); err != nil {
+			return err
+		}
+	}
+	// members of unsupported types are served by their type and count, by placeholders
+	for _, member := range group.UnsupportedMembers {
+		memberTypes = append(memberTypes, member.Type)
+		query, ok := collector.UnsupportedMembersQueries[member.Type]
+		if !ok || member.Count == nil {
+			continue
+		}
+		placeholders := make([]map[string]string, *member.Count)
+		for i := range placeholders {
+			placeholders[i] = map[string]string{"id": fmt.Sprintf("%s-%d", member.Type, i)}
+		}
+		if err := addList(h, fmt.Sprintf(groupMembersPath, domainID, groupID, query), placeholders); err != nil {
+			return err
+		}
+	}
+	return addList(h, fmt.Sprintf(groupMemberTypesPath, domainID, groupID), memberTypes)
+}
+
+func (h *Handler) addSecurityPolicy(domainID string, policy *collector.SecurityPolicy) error {
+	policyID := common.SafePointerDeref(policy.Id)
+	if err := h.addObject(fmt.Sprintf(securityPolicyPath, domainID, policyID), policy); err != nil {
+		return err
+	}
+	if policy.DefaultRuleId != nil {
+		if err := h.addFirewallRule(*policy.DefaultRuleId, policy.DefaultRule); err != nil {
+			return err
+		}
+	}
+	for i := range policy.Rules {
+		rule := &policy.Rules[i]
+		if err := h.addObject(fmt.Sprintf(securityPolicyRulePath, domainID, policyID, common.SafePointerDeref(rule.Id)), rule); err != nil {
+			return err
+		}
+		if rule.RuleId != nil {
+			if err := h.addFirewallRule(*rule.RuleId, rule.FirewallRule); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// addFirewallRule adds the manager API firewall rule of a policy rule
+func (h *Handler) addFirewallRule(ruleID int, rule *collector.FirewallRule) error {
+	if rule == nil {
+		id := strconv.Itoa(ruleID)
+		rule = &collector.FirewallRule{}
+		rule.Id = &id
+	}
+	return h.addObject(fmt.Sprintf(firewallRulePath, ruleID), rule)
+}
@@ -0,0 +1,218 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package nsxmock
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/np-guard/models/pkg/netset"
+
+	"github.com/np-guard/vmware-analyzer/internal/common"
+	"github.com/np-guard/vmware-analyzer/pkg/analyzer"
+	"github.com/np-guard/vmware-analyzer/pkg/analyzer/connectivity"
+	"github.com/np-guard/vmware-analyzer/pkg/collector"
+	"github.com/np-guard/vmware-analyzer/pkg/configuration/dfw"
+	nsx "github.com/np-guard/vmware-analyzer/pkg/configuration/generated"
+)
+
+const (
+	observationForwarded = "TraceflowObservationForwardedLogical"
+	observationDropped   = "TraceflowObservationDroppedLogical"
+	observationDelivered = "TraceflowObservationDelivered"
+
+	icmpEchoRequestType = 8
+)
+
+// traceflowSimulator computes the observations of traceflows by the connectivity analysis of the resources:
+// an allowed packet is forwarded by the deciding egress and ingress DFW rules and delivered, and a denied packet is
+// dropped by the deciding egress DFW rule, or forwarded by the egress rule and dropped by the ingress rule.
+type traceflowSimulator struct {
+	resources *collector.ResourcesContainerModel
+
+	analysisOnce sync.Once // the analysis is computed upon the first traceflow
+	connMap      connectivity.ConnMap
+	analysisErr  error
+
+	lock         sync.Mutex
+	count        int
+	observations map[string][]json.RawMessage // the observations of the traceflows, by their id
+}
+
+func newTraceflowSimulator(resources *collector.ResourcesContainerModel) *traceflowSimulator {
+	return &traceflowSimulator{resources: resources, observations: map[string][]json.RawMessage{}}
+}
+
+func (h *Handler) postTraceflow(w http.ResponseWriter, r *http.Request) {
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, invalidRequestErrorCode, err.Error())
+		return
+	}
+	config := &collector.TraceflowConfig{}
+	if err := json.Unmarshal(b, config); err != nil {
+		writeError(w, http.StatusBadRequest, invalidRequestErrorCode, fmt.Sprintf("Invalid traceflow request: %s", err.Error()))
+		return
+	}
+	id, err := h.traceflows.add(config)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, invalidRequestErrorCode, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, collector.TraceflowResponse{ID: &id, LPortID: config.LPortID})
+}
+
+func (h *Handler) getTraceflowObservations(w http.ResponseWriter, r *http.Request) {
+	observations, ok := h.traceflows.get(r.PathValue("id"))
+	if !ok {
+		writeNotFound(w, r.URL.Path)
+		return
+	}
+	h.writePage(w, observations, r.URL.Query().Get(cursorParam))
+}
+
+func (h *Handler) deleteTraceflow(w http.ResponseWriter, r *http.Request) {
+	if !h.traceflows.delete(r.PathValue("id")) {
+		writeNotFound(w, r.URL.Path)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *traceflowSimulator) get(id string) ([]json.RawMessage, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	observations, ok := s.observations[id]
+	return observations, ok
+}
+
+func (s *traceflowSimulator) delete(id string) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	_, ok := s.observations[id]
+	delete(s.observations, id)
+	return ok
+}
+
+// add simulates a traceflow, and returns its id
+func (s *traceflowSimulator) add(config *collector.TraceflowConfig) (string, error) {
+	observations, err := s.simulate(config)
+	if err != nil {
+		return "", err
+	}
+	raw := make([]json.RawMessage, len(observations))
+	for i, observation := range observations {
+		observation["sequence_no"] = i
+		if raw[i], err = json.Marshal(observation); err != nil {
+			return "", err
+		}
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.count++
+	id := fmt.Sprintf("traceflow-%d", s.count)
+	s.observations[id] = raw
+	return id, nil
+}
+
+type observation map[string]any
+
+func newObservation(resourceType string, componentType nsx.TraceflowComponentType, ruleID *int) observation {
+	res := observation{"resource_type": resourceType, "component_type": componentType}
+	if ruleID != nil {
+		res["acl_rule_id"] = *ruleID
+	}
+	return res
+}
+
+func (s *traceflowSimulator) simulate(config *collector.TraceflowConfig) ([]observation, error) {
+	s.analysisOnce.Do(func() {
+		_, s.connMap, _, s.analysisErr = analyzer.NSXConnectivityFromResourcesContainer(s.resources, common.DefaultOutputParameters())
+	})
+	if s.analysisErr != nil {
+		return nil, s.analysisErr
+	}
+	packet := config.Packet
+	if packet == nil || packet.IpHeader == nil || packet.IpHeader.SrcIp == nil || packet.IpHeader.DstIp == nil {
+		return nil, fmt.Errorf("traceflow packet should have source and destination IP addresses")
+	}
+	conn, err := packetConnection(packet.TransportHeader)
+	if err != nil {
+		return nil, err
+	}
+	srcVM := s.vmName(string(*packet.IpHeader.SrcIp))
+	dstVM := s.vmName(string(*packet.IpHeader.DstIp))
+	if srcVM == "" {
+		return nil, fmt.Errorf("traceflow source %s is not an address of a VM", *packet.IpHeader.SrcIp)
+	}
+	allowed, denied := s.connMap.GetDisjointExplanationsPerEndpoints(srcVM, dstVM)
+	for _, detailedConn := range allowed {
+		if conn.IsSubset(detailedConn.Conn) {
+			explanation := detailedConn.ExplanationObj
+			return []observation{
+				newObservation(observationForwarded, nsx.TraceflowComponentTypeDFW, decidingRule(explanation.EgressExplanations, false)),
+				newObservation(observationForwarded, nsx.TraceflowComponentTypeDFW, decidingRule(explanation.IngressExplanations, false)),
+				newObservation(observationDelivered, nsx.TraceflowComponentTypePHYSICAL, nil),
+			}, nil
+		}
+	}
+	for _, detailedConn := range denied {
+		if conn.IsSubset(detailedConn.Conn) {
+			explanation := detailedConn.ExplanationObj
+			if egressRule := decidingRule(explanation.EgressExplanations, true); egressRule != nil {
+				return []observation{newObservation(observationDropped, nsx.TraceflowComponentTypeDFW, egressRule)}, nil
+			}
+			return []observation{
+				newObservation(observationForwarded, nsx.TraceflowComponentTypeDFW, decidingRule(explanation.EgressExplanations, false)),
+				newObservation(observationDropped, nsx.TraceflowComponentTypeDFW, decidingRule(explanation.IngressExplanations, true)),
+			}, nil
+		}
+	}
+	// the destination is not a VM of the analysis
+	return []observation{newObservation(observationDropped, nsx.TraceflowComponentTypeLR, nil)}, nil
+}
+
+func (s *traceflowSimulator) vmName(address string) string {
+	vni := s.resources.GetVirtualNetworkInterfaceByAddress(address)
+	if vni == nil || vni.OwnerVmId == nil {
+		return ""
+	}
+	vm := s.resources.GetVirtualMachine(*vni.OwnerVmId)
+	if vm == nil {
+		return ""
+	}
+	return common.SafePointerDeref(vm.DisplayName)
+}
+
+// decidingRule returns the id of the first rule denying (or allowing) a connection, or nil if there is no such rule
+func decidingRule(explanations []*connectivity.RuleAndConn, deny bool) *int {
+	for _, explanation := range explanations {
+		if (explanation.Action != dfw.ActionAllow) == deny && explanation.Action != dfw.ActionJumpToApp {
+			return &explanation.RuleID
+		}
+	}
+	return nil
+}
+
+func packetConnection(header *nsx.TransportProtocolHeader) (*netset.TransportSet, error) {
+	switch {
+	case header == nil:
+		return nil, fmt.Errorf("traceflow packet should have a transport header")
+	case header.TcpHeader != nil:
+		src, dst := int64(common.SafePointerDeref(header.TcpHeader.SrcPort)), int64(common.SafePointerDeref(header.TcpHeader.DstPort))
+		return netset.NewTCPTransport(src, src, dst, dst), nil
+	case header.UdpHeader != nil:
+		src, dst := int64(header.UdpHeader.SrcPort), int64(header.UdpHeader.DstPort)
+		return netset.NewUDPTransport(src, src, dst, dst), nil
+	case header.IcmpEchoRequestHeader != nil:
+		return netset.NewICMPTransport(icmpEchoRequestType, icmpEchoRequestType, 0, 0), nil
+	}
+	return nil, fmt.Errorf("traceflow packet should have a TCP, UDP or ICMP echo request header")
+}
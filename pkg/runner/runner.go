@@ -1,11 +1,15 @@
 package runner
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	v1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 	v1alpha1 "sigs.k8s.io/network-policy-api/apis/v1alpha1"
 
 	"github.com/np-guard/vmware-analyzer/internal/common"
@@ -15,10 +19,12 @@ import (
 	"github.com/np-guard/vmware-analyzer/pkg/collector/anonymizer"
 	"github.com/np-guard/vmware-analyzer/pkg/configuration"
 	"github.com/np-guard/vmware-analyzer/pkg/configuration/lint"
+	"github.com/np-guard/vmware-analyzer/pkg/internal/vsphrcoll"
 	"github.com/np-guard/vmware-analyzer/pkg/logging"
 	synth_config "github.com/np-guard/vmware-analyzer/pkg/synthesis/config"
 	"github.com/np-guard/vmware-analyzer/pkg/synthesis/model/symbolicexpr"
 	"github.com/np-guard/vmware-analyzer/pkg/synthesis/ocpvirt"
+	"github.com/np-guard/vmware-analyzer/pkg/synthesis/ocpvirt/resources"
 )
 
 // Runner provides API to run NSX collection / analysis / synthesis operations.
@@ -63,48 +69,117 @@ type Runner struct {
 		lint bool*/
 
 	// runner state
-	nsxResources *collector.ResourcesContainerModel // can be given as input..
+	ctx            context.Context                    // canceling it cancels the collection from NSX
+	nsxResources   *collector.ResourcesContainerModel // can be given as input..
+	suppressStdout bool                               // results are only kept in the runner, not printed
+	// the connectivity is analyzed also for synthesis, to be included in the observations
+	analyzeForSynthesis bool
+	// PEM contents of the NSX client certificate and key, and of the NSX CA certificates, given instead of files
+	nsxClientCertPEM, nsxClientKeyPEM, nsxCACertPEM []byte
 
 	// runner objects holding results
 	generatedK8sPolicies       []*v1.NetworkPolicy
 	generatedK8sAdminPolicies  []*v1alpha1.AdminNetworkPolicy
+	generatedK8sResources      *resources.Generated
 	connectivityAnalysisOutput string
+	lintReport                 string
 	analyzedConnectivity       connectivity.ConnMap
 	parsedConfig               *configuration.Config
+	collectionTime             time.Time
+	synthesisSummary           *SynthesisSummary
+	phaseDurations             map[Phase]time.Duration
 }
 
 func (r *Runner) GetGeneratedPolicies() ([]*v1.NetworkPolicy, []*v1alpha1.AdminNetworkPolicy) {
 	return r.generatedK8sPolicies, r.generatedK8sAdminPolicies
 }
 
+// GetGeneratedResources returns all k8s resources generated by the synthesis (nil if synthesis was not run)
+func (r *Runner) GetGeneratedResources() *resources.Generated {
+	return r.generatedK8sResources
+}
+
 func (r *Runner) GetConnectivityOutput() string {
 	return r.connectivityAnalysisOutput
 }
 
+// GetLintReport returns the report of the lint command (empty if lint was not run)
+func (r *Runner) GetLintReport() string {
+	return r.lintReport
+}
+
 func (r *Runner) GetAnalyzedConnectivity() connectivity.ConnMap {
 	return r.analyzedConnectivity
 }
 
-// Run executes collector/analysis/synthesis components, and returns Observations objects
+// Phase is a phase of the runner's run
+type Phase string
+
+const (
+	PhaseCollection Phase = "collection"
+	PhaseAnalysis   Phase = "analysis"
+	PhaseSynthesis  Phase = "synthesis"
+)
+
+// PhaseError is an error returned by Run, with the phase in which it occurred
+type PhaseError struct {
+	Phase Phase
+	Err   error
+}
+
+func (e *PhaseError) Error() string {
+	return fmt.Sprintf("%s failed: %s", e.Phase, e.Err.Error())
+}
+
+func (e *PhaseError) Unwrap() error {
+	return e.Err
+}
+
+func phaseErr(phase Phase, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &PhaseError{Phase: phase, Err: err}
+}
+
+// Run executes collector/analysis/synthesis components, and returns Observations objects.
+// Errors of the components are returned as *PhaseError.
 func (r *Runner) Run() (*Observations, error) {
 	if err := r.initLogger(); err != nil {
 		return nil, err
 	}
-	if err := r.runCollector(); err != nil {
+	r.phaseDurations = map[Phase]time.Duration{}
+	// the collector is skipped for given NSX resources
+	if err := r.runPhase(PhaseCollection, r.nsxResources == nil, r.runCollector); err != nil {
 		return nil, err
 	}
-	if err := r.runAnalyzer(); err != nil {
+	if err := r.runPhase(PhaseAnalysis, r.args.Cmd == common.CmdAnalyze, r.runAnalyzer); err != nil {
 		return nil, err
 	}
-	if err := r.runLint(); err != nil {
+	if err := r.runPhase(PhaseAnalysis, r.args.Cmd == common.CmdLint, r.runLint); err != nil {
 		return nil, err
 	}
-	if err := r.runSynthesis(); err != nil {
+	if err := r.runPhase(PhaseSynthesis, r.args.Cmd == common.CmdGenerate, r.runSynthesis); err != nil {
 		return nil, err
 	}
 	return &Observations{r}, nil
 }
 
+// runPhase runs a component of the given phase if it is required, and adds its run time to the phase duration.
+// a phase none of whose components is required has no duration
+func (r *Runner) runPhase(phase Phase, required bool, run func() error) error {
+	if err := r.ctx.Err(); err != nil {
+		return phaseErr(phase, err)
+	}
+	if !required {
+		return nil
+	}
+	start := time.Now()
+	err := run()
+	r.phaseDurations[phase] += time.Since(start)
+	return phaseErr(phase, err)
+}
+
 func (r *Runner) initLogger() error {
 	if r.args.Quiet {
 		r.args.LogLevel = common.LogLevelFatal
@@ -118,9 +193,6 @@ func (r *Runner) initLogger() error {
 // runCollector should assign collected NSX resources into r.resources
 // (possibly with anonymization, if set true)
 func (r *Runner) runCollector() error {
-	if r.nsxResources != nil {
-		return nil // skip collector
-	}
 	var err error
 	if r.args.ResourceInputFile != "" {
 		err = r.resourcesFromInputFile()
@@ -130,10 +202,20 @@ func (r *Runner) runCollector() error {
 	if err != nil {
 		return err
 	}
+	if err := r.resourcesFromVCenter(); err != nil {
+		return err
+	}
+	r.collectionTime = time.Now()
 	if r.args.Anonymize {
-		if err := anonymizer.AnonymizeNsx(r.nsxResources); err != nil {
+		if err := anonymizer.AnonymizeNsx(r.nsxResources, r.args.AnonymizationKey); err != nil {
 			return err
 		}
+		// the ids and errors of collection warnings refer to the original resources
+		for i := range r.nsxResources.CollectionWarnings {
+			r.nsxResources.CollectionWarnings[i].ID = ""
+			r.nsxResources.CollectionWarnings[i].Error = ""
+		}
+		anonymizeVCenterInfo(r.nsxResources)
 	}
 	if err := r.resourcesToFile(); err != nil {
 		return err
@@ -142,10 +224,6 @@ func (r *Runner) runCollector() error {
 }
 
 func (r *Runner) runAnalyzer() error {
-	if r.args.Cmd != common.CmdAnalyze {
-		return nil
-	}
-
 	params := &common.OutputParameters{
 		Format:   r.args.OutputFormat,
 		FileName: r.args.OutputFile,
@@ -154,6 +232,16 @@ func (r *Runner) runAnalyzer() error {
 		Color:    r.args.Color,
 	}
 
+	if err := r.analyzeConnectivity(params); err != nil {
+		return err
+	}
+	// TODO: remove print?
+	r.printResult(r.connectivityAnalysisOutput)
+
+	return nil
+}
+
+func (r *Runner) analyzeConnectivity(params *common.OutputParameters) error {
 	logging.Infof("starting connectivity analysis")
 	parsedConfig, connMap, connResStr, err := analyzer.NSXConnectivityFromResourcesContainer(r.nsxResources, params)
 	if err != nil {
@@ -162,29 +250,26 @@ func (r *Runner) runAnalyzer() error {
 	r.connectivityAnalysisOutput = connResStr
 	r.analyzedConnectivity = connMap
 	r.parsedConfig = parsedConfig
-	// TODO: remove print?
-	fmt.Println(connResStr)
-
 	return nil
 }
 
 func (r *Runner) runLint() error {
-	if r.args.Cmd != common.CmdLint {
-		return nil
-	}
 	config, err := configuration.ConfigFromResourcesContainer(r.nsxResources, &common.OutputParameters{Color: r.args.Color})
 	if err != nil {
 		return err
 	}
-	lintReport := lint.LintReport(config, r.args.Color) // currently only redundant rules analysis
-	fmt.Println(lintReport)
+	r.lintReport = lint.LintReport(config, r.args.Color) // currently only redundant rules analysis
+	r.printResult(r.lintReport)
 	return nil
 }
 
-func (r *Runner) runSynthesis() error {
-	if r.args.Cmd != common.CmdGenerate {
-		return nil
+func (r *Runner) printResult(res string) {
+	if !r.suppressStdout {
+		fmt.Println(res)
 	}
+}
+
+func (r *Runner) runSynthesis() error {
 	hints := &symbolicexpr.Hints{GroupsDisjoint: make([][]string, len(r.args.DisjointHints))}
 	for i, hint := range r.args.DisjointHints {
 		hints.GroupsDisjoint[i] = strings.Split(hint, common.CommaSeparator)
@@ -199,13 +284,32 @@ func (r *Runner) runSynthesis() error {
 		EndpointsMapping:        r.args.EndpointsMapping,
 		SegmentsMapping:         r.args.SegmentsMapping,
 		PolicyOptimizationLevel: r.args.PolicyOptimizationLevel,
+		MigratedVMs:             r.args.MigratedVMs,
+		MigratedSegments:        r.args.MigratedSegments,
+		PolicyNamePrefix:        r.args.PolicyNamePrefix,
+		ANPPriorityOffset:       r.args.ANPPriorityOffset,
+		VMSpecs:                 r.args.VMSpecs,
+	}
+	if opts.IsPhased() && len(opts.FilterVMs) > 0 {
+		return errors.New("output filter is not supported in a phased migration")
+	}
+	if r.parsedConfig == nil && r.analyzeForSynthesis {
+		// the connectivity is analyzed here if the analyzer was not run, so that the runner's observations
+		// include the parsed config and its connectivity also for synthesis
+		params := &common.OutputParameters{Format: common.TextFormat, VMs: r.args.OutputFilter, Color: r.args.Color}
+		if err := r.analyzeConnectivity(params); err != nil {
+			return err
+		}
 	}
 	k8sResources, err := ocpvirt.NSXToK8sSynthesis(r.nsxResources, r.parsedConfig, opts)
 	if err != nil {
 		return err
 	}
+	r.synthesisSummary = newSynthesisSummary(k8sResources.NotFullySupportedVMs, k8sResources.UnsupportedConstructs,
+		&k8sResources.Generated)
 	r.generatedK8sPolicies = k8sResources.NetworkPolicies
 	r.generatedK8sAdminPolicies = k8sResources.AdminNetworkPolicies
+	r.generatedK8sResources = &k8sResources.Generated
 	if r.args.SynthesisDir == "" {
 		return nil
 	}
@@ -246,24 +350,119 @@ func (r *Runner) resourcesFromInputFile() error {
 	if err != nil {
 		return err
 	}
+	if warnings := len(r.nsxResources.CollectionWarnings); warnings > 0 {
+		logging.Warnf("the input NSX config is partial: %d resources failed to be collected (see its collection_warnings)", warnings)
+	}
 	return nil
 }
 
 func (r *Runner) resourcesFromNSXEnv() error {
-	server, err := collector.GetNSXServerDate(r.args.Host, r.args.User, r.args.Password, r.args.DisableInsecureSkipVerify)
+	server, err := r.nsxServer()
 	if err != nil {
 		return err
 	}
-	r.nsxResources, err = collector.CollectResources(server)
+	r.nsxResources, err = collector.CollectResources(server.WithContext(r.ctx).WithContinueOnError(r.args.ContinueOnError))
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// resourcesFromVCenter enriches the NSX VMs with the inventory data of their vCenter VMs, if a vCenter is given
+func (r *Runner) resourcesFromVCenter() error {
+	if r.args.VCenterHost == "" {
+		return nil
+	}
+	logging.Infof("collecting VMs inventory from vCenter %s", r.args.VCenterHost)
+	vcResources, err := vsphrcoll.CollectResources(r.ctx, r.args.VCenterHost, r.args.VCenterUser, r.args.VCenterPassword,
+		r.args.DisableInsecureSkipVerify)
+	if err != nil {
+		return fmt.Errorf("failed to collect from vCenter: %w", err)
+	}
+	matched := vcResources.EnrichNSXVMs(r.nsxResources)
+	logging.Infof("%d of %d NSX VMs were found in vCenter", matched, len(r.nsxResources.VirtualMachineList))
+	return nil
+}
+
+// anonymizeVCenterInfo clears the vCenter data which may identify the VMs, keeping their guest OS, sizing, power state
+// and network types
+func anonymizeVCenterInfo(rc *collector.ResourcesContainerModel) {
+	for i := range rc.VirtualMachineList {
+		info := rc.VirtualMachineList[i].VCenterInfo
+		if info == nil {
+			continue
+		}
+		info.ID = ""
+		info.Folder = ""
+		info.CustomAttributes = nil
+		for j := range info.NICs {
+			info.NICs[j] = collector.VCenterNIC{NetworkType: info.NICs[j].NetworkType}
+		}
+	}
+}
+
+// nsxServer returns the NSX manager to collect from: a live NSX manager, possibly recording the interactions with it,
+// or the interactions replayed from a recording
+func (r *Runner) nsxServer() (collector.ServerData, error) {
+	if r.args.NSXReplayDir != "" {
+		logging.Infof("replaying NSX interactions recorded in %s", r.args.NSXReplayDir)
+		client, err := collector.NewReplayClient(r.args.NSXReplayDir)
+		if err != nil {
+			return collector.ServerData{}, err
+		}
+		return collector.NewServerDataWithClient(client), nil
+	}
+	opts, err := r.nsxClientOptions()
+	if err != nil {
+		return collector.ServerData{}, err
+	}
+	server, err := collector.GetNSXServerDate(r.args.Host, r.args.User, r.args.Password, r.args.DisableInsecureSkipVerify, opts...)
+	if err != nil || r.args.NSXRecordDir == "" {
+		return server, err
+	}
+	logging.Infof("recording NSX interactions to %s", r.args.NSXRecordDir)
+	client, err := collector.NewRecordingClient(server.Client(), r.args.NSXRecordDir)
+	if err != nil {
+		return collector.ServerData{}, err
+	}
+	return collector.NewServerDataWithClient(client), nil
+}
+
+// nsxClientOptions returns the options of the client of a live NSX manager: authentication, CAs and timeout
+func (r *Runner) nsxClientOptions() ([]collector.HTTPClientOption, error) {
+	opts := []collector.HTTPClientOption{
+		collector.WithSessionAuth(r.args.NSXSessionAuth),
+		collector.WithRequestTimeout(r.args.NSXRequestTimeout),
+		collector.WithMaxRetries(r.args.NSXMaxRetries),
+	}
+	certPEM, keyPEM, caPEM := r.nsxClientCertPEM, r.nsxClientKeyPEM, r.nsxCACertPEM
+	var err error
+	if r.args.NSXClientCertFile != "" {
+		if certPEM, err = os.ReadFile(r.args.NSXClientCertFile); err != nil {
+			return nil, err
+		}
+		if keyPEM, err = os.ReadFile(r.args.NSXClientKeyFile); err != nil {
+			return nil, err
+		}
+	}
+	if r.args.NSXCACertFile != "" {
+		if caPEM, err = os.ReadFile(r.args.NSXCACertFile); err != nil {
+			return nil, err
+		}
+	}
+	if certPEM != nil {
+		opts = append(opts, collector.WithClientCertificate(certPEM, keyPEM))
+	}
+	if caPEM != nil {
+		opts = append(opts, collector.WithCACertificates(caPEM))
+	}
+	return opts, nil
+}
+
 func newDefaultRunner() *Runner {
 	r := Runner{
 		args: &common.InputArgs{},
+		ctx:  context.Background(),
 	}
 	r.args.SetDefault()
 	return &r
@@ -348,6 +547,23 @@ func WithQuietVerbosity(quiet bool) RunnerOption {
 	}
 }
 
+// WithSuppressedStdout keeps the analysis and lint results in the runner only, without printing them to stdout
+func WithSuppressedStdout(suppress bool) RunnerOption {
+	return func(r *Runner) error {
+		r.suppressStdout = suppress
+		return nil
+	}
+}
+
+// WithAnalysisForSynthesis analyzes the connectivity also for the generate command, so that the observations of the run
+// include the connectivity and the analysis summary (false by default, since synthesis does not need it)
+func WithAnalysisForSynthesis(analyze bool) RunnerOption {
+	return func(r *Runner) error {
+		r.analyzeForSynthesis = analyze
+		return nil
+	}
+}
+
 func WithResourcesInputFile(l string) RunnerOption {
 	return func(r *Runner) error {
 		r.args.ResourceInputFile = l
@@ -362,6 +578,14 @@ func WithResourcesAnonymization(anonymize bool) RunnerOption {
 	}
 }
 
+// WithAnonymizationKey sets the key of the prefix-preserving anonymization of IP addresses
+func WithAnonymizationKey(key string) RunnerOption {
+	return func(r *Runner) error {
+		r.args.AnonymizationKey = key
+		return nil
+	}
+}
+
 func WithResourcesDumpFile(l string) RunnerOption {
 	return func(r *Runner) error {
 		r.args.ResourceDumpFile = l
@@ -451,6 +675,137 @@ func WithNSXResources(rc *collector.ResourcesContainerModel) RunnerOption {
 	}
 }
 
+// WithVMSpecs generates VirtualMachine specs populated from the collected VMs data: compute resources, run strategy,
+// interfaces and placeholder disks; otherwise the VirtualMachines have only the labels required by the generated policies
+func WithVMSpecs(vmSpecs bool) RunnerOption {
+	return func(r *Runner) error {
+		r.args.VMSpecs = vmSpecs
+		return nil
+	}
+}
+
+// WithNSXRecordDir records the interactions with the NSX manager to the given cassette directory
+func WithNSXRecordDir(dir string) RunnerOption {
+	return func(r *Runner) error {
+		if dir != "" && r.args.NSXReplayDir != "" {
+			return errors.New("NSX interactions cannot be both recorded and replayed")
+		}
+		r.args.NSXRecordDir = dir
+		return nil
+	}
+}
+
+// WithNSXReplayDir collects the NSX resources by replaying the interactions recorded to the given cassette directory,
+// instead of accessing an NSX manager
+func WithNSXReplayDir(dir string) RunnerOption {
+	return func(r *Runner) error {
+		if dir != "" && r.args.NSXRecordDir != "" {
+			return errors.New("NSX interactions cannot be both recorded and replayed")
+		}
+		r.args.NSXReplayDir = dir
+		return nil
+	}
+}
+
+// WithContext sets the context of the run; canceling it cancels the collection from NSX, and the phases not started yet
+func WithContext(ctx context.Context) RunnerOption {
+	return func(r *Runner) error {
+		if ctx == nil {
+			return errors.New("nil context")
+		}
+		r.ctx = ctx
+		return nil
+	}
+}
+
+// WithNSXSessionAuth authenticates to NSX by a session created with the user credentials, instead of basic auth
+func WithNSXSessionAuth(sessionAuth bool) RunnerOption {
+	return func(r *Runner) error {
+		r.args.NSXSessionAuth = sessionAuth
+		return nil
+	}
+}
+
+// WithNSXClientCertificate authenticates to NSX by the client certificate and key in the given PEM files
+func WithNSXClientCertificate(certFile, keyFile string) RunnerOption {
+	return func(r *Runner) error {
+		if (certFile == "") != (keyFile == "") {
+			return errors.New("NSX client certificate and key should be given together")
+		}
+		r.args.NSXClientCertFile = certFile
+		r.args.NSXClientKeyFile = keyFile
+		return nil
+	}
+}
+
+// WithNSXCACertificate verifies the NSX manager certificate by the CA certificates in the given PEM file
+func WithNSXCACertificate(caFile string) RunnerOption {
+	return func(r *Runner) error {
+		r.args.NSXCACertFile = caFile
+		return nil
+	}
+}
+
+// WithNSXClientCertificatePEM authenticates to NSX by the given client certificate and key in PEM format,
+// e.g. as read from a k8s secret
+func WithNSXClientCertificatePEM(certPEM, keyPEM []byte) RunnerOption {
+	return func(r *Runner) error {
+		if (len(certPEM) == 0) != (len(keyPEM) == 0) {
+			return errors.New("NSX client certificate and key should be given together")
+		}
+		r.nsxClientCertPEM = certPEM
+		r.nsxClientKeyPEM = keyPEM
+		return nil
+	}
+}
+
+// WithNSXCACertificatePEM verifies the NSX manager certificate by the given CA certificates in PEM format
+func WithNSXCACertificatePEM(caPEM []byte) RunnerOption {
+	return func(r *Runner) error {
+		r.nsxCACertPEM = caPEM
+		return nil
+	}
+}
+
+// WithNSXRequestTimeout sets the timeout of each request to NSX (0 for no timeout)
+func WithNSXRequestTimeout(timeout time.Duration) RunnerOption {
+	return func(r *Runner) error {
+		r.args.NSXRequestTimeout = timeout
+		return nil
+	}
+}
+
+// WithNSXMaxRetries sets the max number of retries of a request rejected by NSX as rate limited or unavailable
+func WithNSXMaxRetries(maxRetries int) RunnerOption {
+	return func(r *Runner) error {
+		if maxRetries < 0 {
+			return fmt.Errorf("invalid NSX max retries %d: should not be negative", maxRetries)
+		}
+		r.args.NSXMaxRetries = maxRetries
+		return nil
+	}
+}
+
+// WithVCenter enriches the collected NSX VMs with inventory data of the given vCenter, such as guest OS, sizing,
+// port groups, folders and custom attributes
+func WithVCenter(host, user, password string) RunnerOption {
+	return func(r *Runner) error {
+		r.args.VCenterHost = host
+		r.args.VCenterUser = user
+		r.args.VCenterPassword = password
+		return nil
+	}
+}
+
+// WithContinueOnError continues the collection from NSX upon failures to collect individual groups and policies,
+// which are recorded as collection warnings in the collected resources
+func WithContinueOnError(continueOnError bool) RunnerOption {
+	return func(r *Runner) error {
+		r.args.ContinueOnError = continueOnError
+		return nil
+	}
+}
+
 func WithDisableInsecureSkipVerify(disableInsecureSkipVerify bool) RunnerOption {
 	return func(r *Runner) error {
 		r.args.DisableInsecureSkipVerify = disableInsecureSkipVerify
@@ -497,3 +852,47 @@ func WithPolicyOptimizationLevel(optLevel string) RunnerOption {
 		return nil
 	}
 }
+
+// WithMigratedVMs sets the names of the VMs migrated so far in a phased migration
+func WithMigratedVMs(vms []string) RunnerOption {
+	return func(r *Runner) error {
+		r.args.MigratedVMs = vms
+		return nil
+	}
+}
+
+// WithMigratedSegments sets the names of the segments whose VMs were migrated so far in a phased migration
+func WithMigratedSegments(segments []string) RunnerOption {
+	return func(r *Runner) error {
+		r.args.MigratedSegments = segments
+		return nil
+	}
+}
+
+// maxAdminPolicyPriority is the max priority of an AdminNetworkPolicy
+const maxAdminPolicyPriority = 1000
+
+// WithANPPriorityOffset sets an offset to the priorities of the generated admin policies,
+// which must be lower than the max admin policy priority
+func WithANPPriorityOffset(offset int) RunnerOption {
+	return func(r *Runner) error {
+		if offset < 0 || offset >= maxAdminPolicyPriority {
+			return fmt.Errorf("invalid admin policy priority offset %d: must be in range [0,%d)", offset, maxAdminPolicyPriority)
+		}
+		r.args.ANPPriorityOffset = offset
+		return nil
+	}
+}
+
+// WithPolicyNamePrefix sets a prefix for the names of the generated policies, which must be a valid DNS label
+func WithPolicyNamePrefix(prefix string) RunnerOption {
+	return func(r *Runner) error {
+		if prefix != "" {
+			if errs := validation.IsDNS1123Label(prefix); len(errs) > 0 {
+				return fmt.Errorf("invalid policy name prefix %s: %s", prefix, strings.Join(errs, ", "))
+			}
+		}
+		r.args.PolicyNamePrefix = prefix
+		return nil
+	}
+}
@@ -0,0 +1,123 @@
+package runner
+
+import (
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/np-guard/vmware-analyzer/pkg/synthesis/ocpvirt/resources"
+)
+
+// CollectionSummary summarizes the collected NSX resources
+type CollectionSummary struct {
+	Time             time.Time
+	VMs              int
+	Segments         int
+	Groups           int
+	SecurityPolicies int
+	Rules            int
+	Services         int
+	Warnings         int // number of groups and policies which failed to be collected, when continuing on errors
+	// number of groups with members of unsupported types, which are ignored by the analysis and the synthesis
+	PartiallyUnderstoodGroups int
+}
+
+// AnalysisSummary summarizes the parsed NSX config and its connectivity analysis
+type AnalysisSummary struct {
+	VMPairs           int   // number of analyzed pairs of VMs
+	PermittedVMPairs  int   // number of analyzed pairs of VMs with permitted connectivity
+	RulesNotEvaluated []int // IDs of DFW rules that are not part of any explanation of the analyzed connectivity
+	// descriptions of the groups with members of unsupported types, and of the rules whose results are thus approximate
+	PartiallyUnderstoodGroups []string
+}
+
+// SynthesisSummary summarizes the k8s resources generated by the synthesis
+type SynthesisSummary struct {
+	NetworkPolicies       int
+	AdminNetworkPolicies  int
+	Namespaces            int
+	UDNs                  int
+	NotFullySupportedVMs  []string // VMs for which the generated resources do not fully preserve the connectivity
+	UnsupportedConstructs []string // NSX constructs for which policies were not generated
+}
+
+// Summary summarizes the results of the runner's phases; a phase that was not run has a nil summary
+type Summary struct {
+	Collection *CollectionSummary
+	Analysis   *AnalysisSummary
+	Synthesis  *SynthesisSummary
+	// the run time of each of the phases that were run; skipped phases have no duration
+	Durations map[Phase]time.Duration
+}
+
+func newSynthesisSummary(notFullySupportedVMs, unsupportedConstructs []string, generated *resources.Generated) *SynthesisSummary {
+	unsupported := slices.Clone(unsupportedConstructs)
+	slices.Sort(unsupported)
+	return &SynthesisSummary{
+		NetworkPolicies:       len(generated.NetworkPolicies),
+		AdminNetworkPolicies:  len(generated.AdminNetworkPolicies),
+		Namespaces:            len(generated.Namespaces),
+		UDNs:                  len(generated.UDNs),
+		NotFullySupportedVMs:  notFullySupportedVMs,
+		UnsupportedConstructs: slices.Compact(unsupported),
+	}
+}
+
+// Summary returns a summary of the results of the run
+func (o *Observations) Summary() *Summary {
+	return &Summary{
+		Collection: o.r.collectionSummary(),
+		Analysis:   o.r.analysisSummary(),
+		Synthesis:  o.r.synthesisSummary,
+		Durations:  maps.Clone(o.r.phaseDurations),
+	}
+}
+
+func (r *Runner) collectionSummary() *CollectionSummary {
+	if r.nsxResources == nil {
+		return nil
+	}
+	res := &CollectionSummary{
+		Time:     r.collectionTime,
+		VMs:      len(r.nsxResources.VirtualMachineList),
+		Segments: len(r.nsxResources.SegmentList),
+		Services: len(r.nsxResources.ServiceList),
+		Warnings: len(r.nsxResources.CollectionWarnings),
+	}
+	for i := range r.nsxResources.DomainList {
+		domainResources := &r.nsxResources.DomainList[i].Resources
+		res.Groups += len(domainResources.GroupList)
+		for j := range domainResources.GroupList {
+			if domainResources.GroupList[j].IsPartiallyUnderstood() {
+				res.PartiallyUnderstoodGroups++
+			}
+		}
+		res.SecurityPolicies += len(domainResources.SecurityPolicyList)
+		for j := range domainResources.SecurityPolicyList {
+			res.Rules += len(domainResources.SecurityPolicyList[j].Rules)
+		}
+	}
+	return res
+}
+
+func (r *Runner) analysisSummary() *AnalysisSummary {
+	if r.parsedConfig == nil || r.analyzedConnectivity == nil {
+		return nil
+	}
+	res := &AnalysisSummary{RulesNotEvaluated: r.analyzedConnectivity.RulesNotEvaluated(r.parsedConfig.FW.AllRulesIDs)}
+	for _, g := range r.parsedConfig.PartiallyUnderstoodGroups() {
+		res.PartiallyUnderstoodGroups = append(res.PartiallyUnderstoodGroups, g.String())
+	}
+	for src, dsts := range r.analyzedConnectivity {
+		for dst, conn := range dsts {
+			if src.IsExternal() || dst.IsExternal() {
+				continue
+			}
+			res.VMPairs++
+			if !conn.Conn.IsEmpty() {
+				res.PermittedVMPairs++
+			}
+		}
+	}
+	return res
+}
@@ -0,0 +1,65 @@
+package runner_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/np-guard/vmware-analyzer/internal/common"
+	"github.com/np-guard/vmware-analyzer/pkg/data"
+	"github.com/np-guard/vmware-analyzer/pkg/runner"
+)
+
+func TestRunSummary(t *testing.T) {
+	rc, err := data.ExamplesGeneration(data.ExampleStatelessPolicy, false)
+	require.Nil(t, err)
+
+	runnerObj, err := runner.NewRunnerWithOptionsList(
+		runner.WithCmd(common.CmdGenerate),
+		runner.WithNSXResources(rc),
+		runner.WithAnalysisForSynthesis(true),
+	)
+	require.Nil(t, err)
+	observations, err := runnerObj.Run()
+	require.Nil(t, err)
+	summary := observations.Summary()
+
+	require.NotNil(t, summary.Collection)
+	require.Equal(t, 2, summary.Collection.VMs)
+	require.Equal(t, 3, summary.Collection.Rules)
+	require.Zero(t, summary.Collection.PartiallyUnderstoodGroups)
+
+	// the connectivity is analyzed also for the generate command, if asked for
+	require.NotNil(t, summary.Analysis)
+	require.Equal(t, 2, summary.Analysis.VMPairs)
+	require.Equal(t, 2, summary.Analysis.PermittedVMPairs)
+
+	require.NotNil(t, summary.Synthesis)
+	require.Equal(t, len(runnerObj.GetGeneratedResources().NetworkPolicies), summary.Synthesis.NetworkPolicies)
+	require.Positive(t, summary.Synthesis.NetworkPolicies)
+
+	// the collection phase is skipped for given NSX resources, and the analysis phase for the generate command,
+	// so only the duration of the synthesis is reported
+	require.Len(t, summary.Durations, 1)
+	require.NotContains(t, summary.Durations, runner.PhaseCollection)
+	require.Positive(t, summary.Durations[runner.PhaseSynthesis])
+}
+
+func TestRunSummaryWithoutAnalysis(t *testing.T) {
+	rc, err := data.ExamplesGeneration(data.ExampleStatelessPolicy, false)
+	require.Nil(t, err)
+
+	runnerObj, err := runner.NewRunnerWithOptionsList(
+		runner.WithCmd(common.CmdGenerate),
+		runner.WithNSXResources(rc),
+	)
+	require.Nil(t, err)
+	observations, err := runnerObj.Run()
+	require.Nil(t, err)
+	summary := observations.Summary()
+
+	// the generate command does not analyze the connectivity by default
+	require.Nil(t, summary.Analysis)
+	require.Nil(t, runnerObj.GetAnalyzedConnectivity())
+	require.NotNil(t, summary.Synthesis)
+}
@@ -0,0 +1,115 @@
+package server
+
+import (
+	"errors"
+
+	"github.com/np-guard/vmware-analyzer/internal/common"
+	"github.com/np-guard/vmware-analyzer/pkg/runner"
+)
+
+// query parameters of the runs, named as the matching flags of the nsxanalyzer commands
+const (
+	paramOutput                  = "output"
+	paramOutputFilter            = "output-filter"
+	paramExplain                 = "explain"
+	paramSynthesizeAdmin         = "synthesize-admin-policies"
+	paramCreateDNSPolicy         = "create-dns-policy"
+	paramDisjointHint            = "disjoint-hint"
+	paramHintsInference          = "hints-inference"
+	paramEndpointsMapping        = "endpoints-mapping"
+	paramSegmentsMapping         = "segments-mapping"
+	paramPolicyOptimizationLevel = "policy-optimization-level"
+	paramMigratedVMs             = "migrated-vms"
+	paramMigratedSegments        = "migrated-segments"
+	paramPolicyNamePrefix        = "policy-name-prefix"
+)
+
+var outputContentTypes = map[common.OutFormat]string{
+	common.TextFormat: contentTypeText,
+	common.DotFormat:  contentTypeText,
+	common.JSONFormat: contentTypeJSON,
+	common.SVGFormat:  contentTypeSVG,
+}
+
+// analysisRun analyzes the connectivity of a config, optionally filtered by VMs and explained by the rules
+var analysisRun = &configRun{
+	cmd: common.CmdAnalyze,
+	options: func(query queryParams) ([]runner.RunnerOption, error) {
+		explain, err := query.boolean(paramExplain)
+		if err != nil {
+			return nil, err
+		}
+		format := query.str(paramOutput)
+		if format == "" {
+			format = string(common.JSONFormat)
+		}
+		return []runner.RunnerOption{
+			runner.WithOutputFormat(format),
+			runner.WithAnalysisVMsFilter(query.list(paramOutputFilter)),
+			runner.WithAnalysisExplain(explain),
+		}, nil
+	},
+	output: func(r *runner.Runner, query queryParams) (*result, error) {
+		format := common.OutFormat(query.str(paramOutput))
+		if format == "" {
+			format = common.JSONFormat
+		}
+		return &result{contentType: outputContentTypes[format], body: []byte(r.GetConnectivityOutput())}, nil
+	},
+}
+
+// lintRun reports the potentially redundant DFW rules of a config
+var lintRun = &configRun{
+	cmd: common.CmdLint,
+	options: func(_ queryParams) ([]runner.RunnerOption, error) {
+		return nil, nil
+	},
+	output: func(r *runner.Runner, _ queryParams) (*result, error) {
+		return &result{contentType: contentTypeText, body: []byte(r.GetLintReport())}, nil
+	},
+}
+
+// generateRun synthesizes the OCP-Virt resources of a config, returned as a multi-document YAML
+var generateRun = &configRun{
+	cmd: common.CmdGenerate,
+	options: func(query queryParams) ([]runner.RunnerOption, error) {
+		admin, err1 := query.boolean(paramSynthesizeAdmin)
+		dns, err2 := query.boolean(paramCreateDNSPolicy)
+		inferHints, err3 := query.boolean(paramHintsInference)
+		if err := errors.Join(err1, err2, err3); err != nil {
+			return nil, err
+		}
+		opts := []runner.RunnerOption{
+			runner.WithSynthAdminPolicies(admin),
+			runner.WithSynthDNSPolicies(dns),
+			runner.WithSynthesisHints(query[paramDisjointHint]),
+			runner.WithInferHints(inferHints),
+			runner.WithAnalysisVMsFilter(query.list(paramOutputFilter)),
+			runner.WithMigratedVMs(query.list(paramMigratedVMs)),
+			runner.WithMigratedSegments(query.list(paramMigratedSegments)),
+			runner.WithPolicyNamePrefix(query.str(paramPolicyNamePrefix)),
+		}
+		// enum options which are not set are left to the runner defaults
+		for param, option := range map[string]func(string) runner.RunnerOption{
+			paramEndpointsMapping:        runner.WithEndpointsMapping,
+			paramSegmentsMapping:         runner.WithSegmentsMapping,
+			paramPolicyOptimizationLevel: runner.WithPolicyOptimizationLevel,
+		} {
+			if value := query.str(param); value != "" {
+				opts = append(opts, option(value))
+			}
+		}
+		return opts, nil
+	},
+	output: func(r *runner.Runner, _ queryParams) (*result, error) {
+		generated := r.GetGeneratedResources()
+		if generated == nil {
+			return nil, errors.New("no resources were generated")
+		}
+		yaml, err := generated.ToYAML()
+		if err != nil {
+			return nil, err
+		}
+		return &result{contentType: contentTypeYAML, body: []byte(yaml)}, nil
+	},
+}
@@ -0,0 +1,373 @@
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/np-guard/vmware-analyzer/internal/common"
+	"github.com/np-guard/vmware-analyzer/pkg/logging"
+	"github.com/np-guard/vmware-analyzer/pkg/runner"
+)
+
+const (
+	apiPrefix = "/api/v1"
+
+	defaultMaxConfigs    = 16
+	defaultMaxUploadSize = 256 << 20 // 256MiB
+
+	readHeaderTimeout = 10 * time.Second
+
+	contentTypeJSON = "application/json"
+	contentTypeYAML = "application/yaml"
+	contentTypeText = "text/plain; charset=utf-8"
+	contentTypeSVG  = "image/svg+xml"
+)
+
+//go:embed openapi.json
+var openAPISpec []byte
+
+// gzipMagic is the header of gzip compressed data
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// Server serves an HTTP JSON API over the runner: NSX resource dumps are uploaded, and then analyzed,
+// linted and synthesized by their id. The results are cached per uploaded config and query.
+type Server struct {
+	configs       *store
+	maxUploadSize int64
+	runnerOptions []runner.RunnerOption // options applied to all runs, e.g. logging options
+
+	// runs are serialized, since the runner uses a global logger
+	runLock sync.Mutex
+}
+
+// ServerOption is the type for specifying options for Server
+type ServerOption func(*Server)
+
+// WithMaxConfigs sets the max number of uploaded configs kept by the server
+func WithMaxConfigs(maxConfigs int) ServerOption {
+	return func(s *Server) {
+		s.configs.maxConfigs = maxConfigs
+	}
+}
+
+// WithMaxUploadSize sets the max size in bytes of an uploaded config
+func WithMaxUploadSize(size int64) ServerOption {
+	return func(s *Server) {
+		s.maxUploadSize = size
+	}
+}
+
+// WithRunnerOptions sets runner options applied to all runs, such as logging options (runs are quiet by default)
+func WithRunnerOptions(opts ...runner.RunnerOption) ServerOption {
+	return func(s *Server) {
+		s.runnerOptions = opts
+	}
+}
+
+func NewServer(opts ...ServerOption) *Server {
+	s := &Server{configs: newStore(defaultMaxConfigs), maxUploadSize: defaultMaxUploadSize,
+		runnerOptions: []runner.RunnerOption{runner.WithQuietVerbosity(true)}}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+// Handler returns the HTTP handler of the API
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, _ *http.Request) { writeJSON(w, http.StatusOK, map[string]string{}) })
+	mux.HandleFunc("GET "+apiPrefix+"/openapi.json", func(w http.ResponseWriter, _ *http.Request) {
+		writeResult(w, &result{contentType: contentTypeJSON, body: openAPISpec})
+	})
+	mux.HandleFunc("POST "+apiPrefix+"/configs", s.uploadConfig)
+	mux.HandleFunc("GET "+apiPrefix+"/configs", s.listConfigs)
+	mux.HandleFunc("GET "+apiPrefix+"/configs/{id}", s.getConfig)
+	mux.HandleFunc("DELETE "+apiPrefix+"/configs/{id}", s.deleteConfig)
+	mux.HandleFunc("GET "+apiPrefix+"/configs/{id}/analysis", s.runOnConfig(analysisRun))
+	mux.HandleFunc("GET "+apiPrefix+"/configs/{id}/lint", s.runOnConfig(lintRun))
+	mux.HandleFunc("GET "+apiPrefix+"/configs/{id}/generate", s.runOnConfig(generateRun))
+	return mux
+}
+
+// ListenAndServe serves the API on the given address
+func (s *Server) ListenAndServe(address string) error {
+	logging.Infof("serving nsxanalyzer API on %s", address)
+	server := &http.Server{Addr: address, Handler: s.Handler(), ReadHeaderTimeout: readHeaderTimeout}
+	return server.ListenAndServe()
+}
+
+// configInfo is the JSON representation of an uploaded config
+type configInfo struct {
+	ID       string         `json:"id"`
+	Uploaded time.Time      `json:"uploaded"`
+	Summary  *configSummary `json:"summary"`
+}
+
+type configSummary struct {
+	VMs              int `json:"vms"`
+	Segments         int `json:"segments"`
+	Groups           int `json:"groups"`
+	SecurityPolicies int `json:"securityPolicies"`
+	Rules            int `json:"rules"`
+	Services         int `json:"services"`
+}
+
+func newConfigInfo(c *config) *configInfo {
+	s := c.summary
+	return &configInfo{ID: c.id, Uploaded: c.uploaded, Summary: &configSummary{VMs: s.VMs, Segments: s.Segments, Groups: s.Groups,
+		SecurityPolicies: s.SecurityPolicies, Rules: s.Rules, Services: s.Services}}
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func (s *Server) uploadConfig(w http.ResponseWriter, req *http.Request) {
+	data, err := io.ReadAll(http.MaxBytesReader(w, req.Body, s.maxUploadSize))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			writeError(w, http.StatusRequestEntityTooLarge, err)
+			return
+		}
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if data, err = decompress(data, s.maxUploadSize); err != nil {
+		if errors.Is(err, errDecompressedTooLarge) {
+			writeError(w, http.StatusRequestEntityTooLarge, err)
+			return
+		}
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if c := s.configs.get(configID(data)); c != nil {
+		writeJSON(w, http.StatusOK, newConfigInfo(c))
+		return
+	}
+	summary, err := s.collectionSummary(data)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid NSX resources: %w", err))
+		return
+	}
+	c, exists := s.configs.add(data, summary)
+	status := http.StatusCreated
+	if exists {
+		status = http.StatusOK
+	}
+	logging.Infof("uploaded NSX config %s", c.id)
+	writeJSON(w, status, newConfigInfo(c))
+}
+
+// collectionSummary parses an uploaded config and returns its summary
+func (s *Server) collectionSummary(data []byte) (*runner.CollectionSummary, error) {
+	c := &config{data: data}
+	rc, err := c.resources()
+	if err != nil {
+		return nil, err
+	}
+	_, observations, err := s.run(runner.WithCmd(common.CmdCollect), runner.WithNSXResources(rc))
+	if err != nil {
+		return nil, err
+	}
+	return observations.Summary().Collection, nil
+}
+
+func (s *Server) listConfigs(w http.ResponseWriter, _ *http.Request) {
+	configs := s.configs.list()
+	res := make([]*configInfo, len(configs))
+	for i, c := range configs {
+		res[i] = newConfigInfo(c)
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (s *Server) getConfig(w http.ResponseWriter, req *http.Request) {
+	c := s.configs.get(req.PathValue("id"))
+	if c == nil {
+		writeNotFound(w, req.PathValue("id"))
+		return
+	}
+	writeJSON(w, http.StatusOK, newConfigInfo(c))
+}
+
+func (s *Server) deleteConfig(w http.ResponseWriter, req *http.Request) {
+	if !s.configs.delete(req.PathValue("id")) {
+		writeNotFound(w, req.PathValue("id"))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// configRun runs the runner over an uploaded config, with the options of the request's query parameters
+type configRun struct {
+	cmd     string
+	options func(query queryParams) ([]runner.RunnerOption, error)
+	output  func(r *runner.Runner, query queryParams) (*result, error)
+}
+
+func (s *Server) runOnConfig(run *configRun) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		c := s.configs.get(req.PathValue("id"))
+		if c == nil {
+			writeNotFound(w, req.PathValue("id"))
+			return
+		}
+		// url.Values.Encode() sorts the parameters by key, thus equivalent queries share a cache key
+		key := req.URL.Path + "?" + req.URL.Query().Encode()
+		if res := s.configs.cachedResult(c, key); res != nil {
+			w.Header().Set("X-Cache", "hit")
+			writeResult(w, res)
+			return
+		}
+		query := queryParams(req.URL.Query())
+		opts, err := run.options(query)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		rc, err := c.resources()
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		r, _, err := s.run(append(opts, runner.WithCmd(run.cmd), runner.WithNSXResources(rc))...)
+		if err != nil {
+			writeError(w, runErrorStatus(err), err)
+			return
+		}
+		res, err := run.output(r, query)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		s.configs.cacheResult(c, key, res)
+		w.Header().Set("X-Cache", "miss")
+		writeResult(w, res)
+	}
+}
+
+// runErrorStatus returns the status of a failed run: invalid options are bad requests, other failures are
+// failures of the config's analysis or synthesis
+func runErrorStatus(err error) int {
+	var phaseErr *runner.PhaseError
+	if errors.As(err, &phaseErr) {
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusBadRequest
+}
+
+func (s *Server) run(opts ...runner.RunnerOption) (*runner.Runner, *runner.Observations, error) {
+	s.runLock.Lock()
+	defer s.runLock.Unlock()
+	// results are returned in the responses rather than printed
+	opts = append(append(slices.Clone(s.runnerOptions), runner.WithSuppressedStdout(true)), opts...)
+	runnerObj, err := runner.NewRunnerWithOptionsList(opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	observations, err := runnerObj.Run()
+	return runnerObj, observations, err
+}
+
+var errDecompressedTooLarge = errors.New("decompressed config exceeds the max upload size")
+
+// decompress returns gzip compressed data decompressed, failing if its decompressed size exceeds maxSize
+func decompress(data []byte, maxSize int64) ([]byte, error) {
+	if !bytes.HasPrefix(data, gzipMagic) {
+		return data, nil
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	res, err := io.ReadAll(io.LimitReader(reader, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(res)) > maxSize {
+		return nil, errDecompressedTooLarge
+	}
+	return res, nil
+}
+
+// queryParams are the query parameters of a request; list parameters may be repeated and/or comma separated
+type queryParams map[string][]string
+
+func (q queryParams) str(name string) string {
+	if values := q[name]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func (q queryParams) list(name string) []string {
+	var res []string
+	for _, value := range q[name] {
+		for _, item := range strings.Split(value, common.CommaSeparator) {
+			if item != "" {
+				res = append(res, item)
+			}
+		}
+	}
+	return res
+}
+
+func (q queryParams) boolean(name string) (bool, error) {
+	value := q.str(name)
+	if value == "" {
+		return false, nil
+	}
+	res, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid value %s of query parameter %s: should be a boolean", value, name)
+	}
+	return res, nil
+}
+
+func writeResult(w http.ResponseWriter, res *result) {
+	w.Header().Set("Content-Type", res.contentType)
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(res.body); err != nil {
+		logging.Debugf("failed to write response: %s", err.Error())
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, content any) {
+	body, err := json.MarshalIndent(content, "", "  ")
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", contentTypeJSON)
+	w.WriteHeader(status)
+	if _, err := w.Write(body); err != nil {
+		logging.Debugf("failed to write response: %s", err.Error())
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	body, _ := json.Marshal(errorResponse{Error: err.Error()})
+	w.Header().Set("Content-Type", contentTypeJSON)
+	w.WriteHeader(status)
+	if _, err := w.Write(body); err != nil {
+		logging.Debugf("failed to write response: %s", err.Error())
+	}
+}
+
+func writeNotFound(w http.ResponseWriter, id string) {
+	writeError(w, http.StatusNotFound, fmt.Errorf("config %s not found", id))
+}
@@ -0,0 +1,154 @@
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testConfigFile   = "../data/json/ExampleAppWithGroupsAndSegments.json"
+	testConfigPrefix = "/api/v1/configs"
+)
+
+func request(t *testing.T, ts *httptest.Server, method, path string, body []byte) (*http.Response, string) {
+	t.Helper()
+	req, err := http.NewRequest(method, ts.URL+path, bytes.NewReader(body))
+	require.Nil(t, err)
+	resp, err := ts.Client().Do(req)
+	require.Nil(t, err)
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	require.Nil(t, err)
+	return resp, string(b)
+}
+
+func uploadTestConfig(t *testing.T, ts *httptest.Server, data []byte, expectedStatus int) *configInfo {
+	t.Helper()
+	resp, body := request(t, ts, http.MethodPost, testConfigPrefix, data)
+	require.Equal(t, expectedStatus, resp.StatusCode, body)
+	info := &configInfo{}
+	require.Nil(t, json.Unmarshal([]byte(body), info))
+	return info
+}
+
+func TestServerConfigs(t *testing.T) {
+	ts := httptest.NewServer(NewServer(WithMaxConfigs(1)).Handler())
+	defer ts.Close()
+	data, err := os.ReadFile(testConfigFile)
+	require.Nil(t, err)
+
+	info := uploadTestConfig(t, ts, data, http.StatusCreated)
+	require.Equal(t, configID(data), info.ID)
+	require.Equal(t, 5, info.Summary.VMs)
+
+	// uploading the same config, also compressed, returns the existing config
+	var compressed bytes.Buffer
+	w := gzip.NewWriter(&compressed)
+	_, err = w.Write(data)
+	require.Nil(t, err)
+	require.Nil(t, w.Close())
+	require.Equal(t, info.ID, uploadTestConfig(t, ts, compressed.Bytes(), http.StatusOK).ID)
+
+	resp, body := request(t, ts, http.MethodGet, testConfigPrefix, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Contains(t, body, info.ID)
+
+	// a compressed config is rejected if it exceeds the max upload size once decompressed
+	smallTS := httptest.NewServer(NewServer(WithMaxUploadSize(int64(compressed.Len()))).Handler())
+	defer smallTS.Close()
+	resp, _ = request(t, smallTS, http.MethodPost, testConfigPrefix, compressed.Bytes())
+	require.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+
+	resp, _ = request(t, ts, http.MethodPost, testConfigPrefix, []byte("not a config"))
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	// a new config evicts the least recently used config beyond the max number of configs
+	otherData, err := os.ReadFile("../data/json/ExampleHogwarts.json")
+	require.Nil(t, err)
+	uploadTestConfig(t, ts, otherData, http.StatusCreated)
+	resp, _ = request(t, ts, http.MethodGet, testConfigPrefix+"/"+info.ID, nil)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp, _ = request(t, ts, http.MethodDelete, testConfigPrefix+"/"+configID(otherData), nil)
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+	resp, _ = request(t, ts, http.MethodDelete, testConfigPrefix+"/"+configID(otherData), nil)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestServerRuns(t *testing.T) {
+	ts := httptest.NewServer(NewServer().Handler())
+	defer ts.Close()
+	data, err := os.ReadFile(testConfigFile)
+	require.Nil(t, err)
+	configPath := testConfigPrefix + "/" + uploadTestConfig(t, ts, data, http.StatusCreated).ID
+
+	resp, body := request(t, ts, http.MethodGet, configPath+"/analysis?output-filter=New-VM-3,New-VM-4", nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode, body)
+	require.Equal(t, contentTypeJSON, resp.Header.Get("Content-Type"))
+	require.Equal(t, "miss", resp.Header.Get("X-Cache"))
+	require.True(t, json.Valid([]byte(body)))
+	require.Contains(t, body, "New-VM-3")
+	require.NotContains(t, body, "New-VM-1")
+
+	// the result of an equivalent query is cached
+	resp, cachedBody := request(t, ts, http.MethodGet, configPath+"/analysis?output-filter=New-VM-3%2CNew-VM-4", nil)
+	require.Equal(t, "hit", resp.Header.Get("X-Cache"))
+	require.Equal(t, body, cachedBody)
+
+	resp, body = request(t, ts, http.MethodGet, configPath+"/analysis?output=txt&explain=true", nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode, body)
+	require.Contains(t, body, "rule")
+
+	resp, body = request(t, ts, http.MethodGet, configPath+"/lint", nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode, body)
+
+	resp, body = request(t, ts, http.MethodGet, configPath+"/generate?synthesize-admin-policies=true", nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode, body)
+	require.Equal(t, contentTypeYAML, resp.Header.Get("Content-Type"))
+	require.Contains(t, body, "kind: NetworkPolicy")
+	require.Contains(t, body, "kind: Namespace")
+
+	for _, query := range []string{"/analysis?output=pdf", "/analysis?explain=maybe", "/generate?endpoints-mapping=containers"} {
+		resp, body = request(t, ts, http.MethodGet, configPath+query, nil)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode, query)
+		require.True(t, strings.HasPrefix(body, `{"error":`), body)
+	}
+	resp, _ = request(t, ts, http.MethodGet, testConfigPrefix+"/unknown/lint", nil)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestStoreResultsLimit(t *testing.T) {
+	s := newStore(defaultMaxConfigs)
+	c, _ := s.add([]byte("{}"), nil)
+	for i := range maxResultsPerConfig + 1 {
+		s.cacheResult(c, strconv.Itoa(i), &result{})
+	}
+	require.Len(t, c.results, maxResultsPerConfig)
+	require.Nil(t, s.cachedResult(c, "0"))
+	require.NotNil(t, s.cachedResult(c, strconv.Itoa(maxResultsPerConfig)))
+}
+
+func TestServerOpenAPI(t *testing.T) {
+	ts := httptest.NewServer(NewServer().Handler())
+	defer ts.Close()
+	resp, body := request(t, ts, http.MethodGet, "/api/v1/openapi.json", nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	spec := map[string]any{}
+	require.Nil(t, json.Unmarshal([]byte(body), &spec))
+	paths, ok := spec["paths"].(map[string]any)
+	require.True(t, ok)
+	for _, path := range []string{"/api/v1/configs", "/api/v1/configs/{id}/analysis", "/api/v1/configs/{id}/lint",
+		"/api/v1/configs/{id}/generate"} {
+		require.Contains(t, paths, path)
+	}
+}
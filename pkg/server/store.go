@@ -0,0 +1,128 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/np-guard/vmware-analyzer/pkg/collector"
+	"github.com/np-guard/vmware-analyzer/pkg/runner"
+)
+
+// maxResultsPerConfig is the max number of results cached per config; above it the earliest cached result is evicted
+const maxResultsPerConfig = 64
+
+// result is a cached result of a run over an uploaded config
+type result struct {
+	contentType string
+	body        []byte
+}
+
+// config is an uploaded NSX resources dump, with the results of runs over it
+type config struct {
+	id       string
+	data     []byte // the NSX resources JSON, parsed again per run since runs may modify the parsed resources
+	summary  *runner.CollectionSummary
+	uploaded time.Time
+	lastUsed time.Time
+	results  map[string]*result // from request key (path and canonical query) to its result
+	keys     []string           // the keys of the cached results, in the order they were cached
+}
+
+func (c *config) resources() (*collector.ResourcesContainerModel, error) {
+	return collector.FromJSONString(c.data)
+}
+
+// store holds the uploaded configs by their hash, evicting the least recently used config above maxConfigs
+type store struct {
+	mu         sync.Mutex
+	configs    map[string]*config
+	maxConfigs int
+}
+
+func newStore(maxConfigs int) *store {
+	return &store{configs: map[string]*config{}, maxConfigs: maxConfigs}
+}
+
+func configID(data []byte) string {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
+
+// add stores a config, and returns it and whether it was already stored
+func (s *store) add(data []byte, summary *runner.CollectionSummary) (*config, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := configID(data)
+	now := time.Now()
+	if c, ok := s.configs[id]; ok {
+		c.lastUsed = now
+		return c, true
+	}
+	c := &config{id: id, data: data, summary: summary, uploaded: now, lastUsed: now, results: map[string]*result{}}
+	s.configs[id] = c
+	for len(s.configs) > s.maxConfigs {
+		s.evictLeastRecentlyUsed()
+	}
+	return c, false
+}
+
+func (s *store) evictLeastRecentlyUsed() {
+	var oldest *config
+	for _, c := range s.configs {
+		if oldest == nil || c.lastUsed.Before(oldest.lastUsed) {
+			oldest = c
+		}
+	}
+	delete(s.configs, oldest.id)
+}
+
+func (s *store) get(id string) *config {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.configs[id]
+	if ok {
+		c.lastUsed = time.Now()
+	}
+	return c
+}
+
+func (s *store) delete(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.configs[id]
+	delete(s.configs, id)
+	return ok
+}
+
+func (s *store) list() []*config {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	res := make([]*config, 0, len(s.configs))
+	for _, c := range s.configs {
+		res = append(res, c)
+	}
+	slices.SortFunc(res, func(a, b *config) int { return a.uploaded.Compare(b.uploaded) })
+	return res
+}
+
+func (s *store) cachedResult(c *config, key string) *result {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return c.results[key]
+}
+
+func (s *store) cacheResult(c *config, key string, res *result) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := c.results[key]; !ok {
+		c.keys = append(c.keys, key)
+	}
+	c.results[key] = res
+	for len(c.keys) > maxResultsPerConfig {
+		delete(c.results, c.keys[0])
+		c.keys = c.keys[1:]
+	}
+}
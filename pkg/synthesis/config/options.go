@@ -15,6 +15,20 @@ type SynthesisOptions struct {
 	EndpointsMapping        common.Endpoints
 	SegmentsMapping         common.Segments
 	PolicyOptimizationLevel common.PolicyOptimizationLevel
+	// phased migration: names of the VMs and segments migrated so far; the other VMs remain on NSX
+	MigratedVMs      []string
+	MigratedSegments []string
+	// prefix for the names of generated policies, to avoid conflicts between policies generated by multiple phases
+	PolicyNamePrefix string
+	// offset of the priorities of generated admin policies, to avoid conflicts between admin policies generated by multiple phases
+	ANPPriorityOffset int
+	// generate VirtualMachine specs with compute, disks and networks, rather than only the labels required by policies
+	VMSpecs bool
+}
+
+// IsPhased returns true iff only some of the VMs are migrated
+func (options *SynthesisOptions) IsPhased() bool {
+	return len(options.MigratedVMs) > 0 || len(options.MigratedSegments) > 0
 }
 
 func (options *SynthesisOptions) OutputOption() *common.OutputParameters {
@@ -165,6 +165,11 @@ func NSXConfigToAbstractModel(
 		PrintPreProcessingSymbolicPolicy(
 			nsxConfig.FW.CategoriesSpecs, preProcessingCategoryToPolicy, options.Color))
 
+	// reject rules are translated as drop rules, since k8s policies have no reject action
+	warnOnRejectRules(nsxConfig.FW.CategoriesSpecs, options.SynthesizeAdmin)
+	// identity firewall rules have no k8s equivalent
+	warnOnUserConditionalRules(nsxConfig.FW)
+
 	// computes disjoint groups, based on "hints" given by the user, and potentially current groups snapshot
 	inferredHints := inferDisjointGroups(nsxConfig.Groups, options.InferHints, groupToDNF)
 	allHints := getAllHints(options.Hints, inferredHints)
@@ -200,6 +205,35 @@ func NSXConfigToAbstractModel(
 	return abstractModel, nil
 }
 
+// warnOnRejectRules logs a warning per NSX reject rule, since the synthesized k8s policies can only drop the
+// connections denied by such rules: applications will observe timeouts instead of fast TCP RST / ICMP unreachable
+func warnOnRejectRules(categoriesSpecs []*dfw.CategorySpec, synthesizeAdmin bool) {
+	warned := map[int]bool{}
+	for _, category := range categoriesSpecs {
+		for _, rule := range slices.Concat(category.EvaluatedRules.InboundRules, category.EvaluatedRules.OutboundRules) {
+			if !rule.RuleObj.IsReject() || warned[rule.RuleObj.RuleID] {
+				continue
+			}
+			warned[rule.RuleObj.RuleID] = true
+			if synthesizeAdmin && category.Category < collector.MinNonAdminCategory() {
+				logging.Warnf("nsx rule %d has action reject, synthesized as AdminNetworkPolicy Deny (connections are dropped, not rejected)",
+					rule.RuleObj.RuleID)
+			} else {
+				logging.Warnf("nsx rule %d has action reject, synthesized as not allowed by NetworkPolicies (connections are dropped, not rejected)",
+					rule.RuleObj.RuleID)
+			}
+		}
+	}
+}
+
+// warnOnUserConditionalRules logs a warning per NSX identity firewall rule, since k8s policies cannot condition
+// connections on the users that initiate them
+func warnOnUserConditionalRules(fw *dfw.DFW) {
+	for _, rule := range fw.UserConditionalRules() {
+		logging.Warnf("nsx rule %d applies to users in AD groups, which is not supported by k8s policies", rule.RuleID)
+	}
+}
+
 func (a *AbstractModelSyn) addLabelsInfo() {
 	a.LabelsToVMsMap = map[string][]topology.Endpoint{}
 	a.VMToLablesMap = map[string][]string{}
@@ -72,8 +72,12 @@ func (a *absToNXS) convertPolicies(policy []*model.SymbolicPolicy, synthesizeAdm
 		for rules, dir := range rulesToDirection {
 			for _, rule := range *rules {
 				if synthesizeAdmin && rule.OrigRuleCategory < collector.MinNonAdminCategory() {
+					action := fwRuleToDataRuleAction[rule.OrigRule.Action]
+					if rule.OrigRule.IsReject() {
+						action = data.Reject
+					}
 					for _, p := range *rule.OrigSymbolicPaths {
-						a.pathToRule(p, dir, fwRuleToDataRuleAction[rule.OrigRule.Action], rule.OrigRuleCategory.String())
+						a.pathToRule(p, dir, action, rule.OrigRuleCategory.String())
 					}
 				} else {
 					for _, p := range rule.OptimizedAllowOnlyPaths {
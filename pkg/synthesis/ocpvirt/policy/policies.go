@@ -28,15 +28,15 @@ func (np *PolicyGenerator) addDefaultDenyNetworkPolicy() {
 	if np.synthModel.DefaultDenyRule != nil {
 		ruleID = np.synthModel.DefaultDenyRule.RuleIDStr()
 	}
-	for _, namespace := range np.NamespacesInfo.Namespaces {
-		policy := newNetworkPolicy(defaultDenyNetpolName(namespace.Name), namespace.Name,
+	for _, namespace := range np.NamespacesInfo.MigratedNamespaces() {
+		policy := newNetworkPolicy(np.policyName(defaultDenyNetpolName(namespace.Name)), namespace.Name,
 			defaultDenyNetpolDescription(namespace.Name), ruleID, ingressAndEgressType)
 		np.NetworkPolicies = append(np.NetworkPolicies, policy)
 	}
 }
 
 func (np *PolicyGenerator) newPolicy(namespace string, typeValue policyType, description, nsxRuleID string) *networkingv1.NetworkPolicy {
-	policyName := fmt.Sprintf("policy-%d", len(np.NetworkPolicies))
+	policyName := np.policyName(fmt.Sprintf("policy-%d", len(np.NetworkPolicies)))
 	policy := newNetworkPolicy(policyName, namespace, description, nsxRuleID, typeValue)
 	np.NetworkPolicies = append(np.NetworkPolicies, policy)
 	logging.Debugf("added NetworkPolicy %s", policyName)
@@ -48,7 +48,7 @@ func (np *PolicyGenerator) addNetworkPolicy(srcSelector, dstSelector *policySele
 	description, nsxRuleID string) {
 	ports := connToPolicyPort(conn)
 	if isInbound {
-		for _, namespace := range dstSelector.namespaces {
+		for _, namespace := range dstSelector.subjectNamespaces {
 			from := srcSelector.toPolicyPeers(namespace)
 			if len(from) == 0 { // skip policy generation if no rules are present
 				continue
@@ -59,7 +59,7 @@ func (np *PolicyGenerator) addNetworkPolicy(srcSelector, dstSelector *policySele
 			policy.Spec.PodSelector = dstSelector.toPodSelector()
 		}
 	} else {
-		for _, namespace := range srcSelector.namespaces {
+		for _, namespace := range srcSelector.subjectNamespaces {
 			to := dstSelector.toPolicyPeers(namespace)
 			if len(to) == 0 { // skip policy generation if no rules are present
 				continue
@@ -73,13 +73,15 @@ func (np *PolicyGenerator) addNetworkPolicy(srcSelector, dstSelector *policySele
 }
 
 func (np *PolicyGenerator) addAdminNetworkPolicy(srcSelector, dstSelector *policySelector,
-	conn *netset.TransportSet, inbound bool, action adminv1alpha1.AdminNetworkPolicyRuleAction, description, nsxRuleID string) {
+	conn *netset.TransportSet, inbound bool, action adminv1alpha1.AdminNetworkPolicyRuleAction,
+	description, nsxRuleID string) *adminv1alpha1.AdminNetworkPolicy {
 	ports := connToAdminPolicyPort(conn)
 	policy := newAdminNetworkPolicy(
-		fmt.Sprintf("admin-policy-%d", len(np.AdminNetworkPolicies)),
+		np.policyName(fmt.Sprintf("admin-policy-%d", len(np.AdminNetworkPolicies))),
 		description,
 		nsxRuleID)
 	np.setAdminNetworkPolicy(policy, ports, inbound, action, srcSelector, dstSelector)
+	return policy
 }
 
 func dnsPolicyDescription(namespace string) string {
@@ -90,8 +92,9 @@ func dnsPolicyName(namespace string) string {
 }
 
 func (np *PolicyGenerator) addDNSAllowNetworkPolicy() {
-	for _, namespace := range np.NamespacesInfo.Namespaces {
-		policy := newNetworkPolicy(dnsPolicyName(namespace.Name), namespace.Name, dnsPolicyDescription(namespace.Name), noNSXRuleID, egressType)
+	for _, namespace := range np.NamespacesInfo.MigratedNamespaces() {
+		policy := newNetworkPolicy(np.policyName(dnsPolicyName(namespace.Name)), namespace.Name,
+			dnsPolicyDescription(namespace.Name), noNSXRuleID, egressType)
 		np.NetworkPolicies = append(np.NetworkPolicies, policy)
 		policy.Spec.PodSelector = metav1.LabelSelector{}
 		to := []networkingv1.NetworkPolicyPeer{{
@@ -111,7 +114,7 @@ func (np *PolicyGenerator) addDNSAllowAdminNetworkPolicy() {
 	}}
 	allSelector := newEmptyPolicySelector()
 	ports := connToAdminPolicyPort(dnsPortConn)
-	egressPolicy := newAdminNetworkPolicy("egress-dns-policy",
+	egressPolicy := newAdminNetworkPolicy(np.policyName("egress-dns-policy"),
 		"Admin Network Policy To Allow Egress Access To DNS Server",
 		noNSXRuleID)
 	np.setAdminNetworkPolicy(egressPolicy, ports, false, adminv1alpha1.AdminNetworkPolicyRuleActionAllow, allSelector, dnsSelector)
@@ -123,8 +126,9 @@ func (np *PolicyGenerator) setAdminNetworkPolicy(
 	srcSelector, dstSelector *policySelector) {
 	logging.Debug2f("setAdminNetworkPolicy with srcSelector: %s, dstSelector %s  ", srcSelector.string(), dstSelector.string())
 	np.AdminNetworkPolicies = append(np.AdminNetworkPolicies, policy)
+	// the priorities of admin policies generated by multiple phases of a migration are offset, so they do not conflict
 	//nolint:gosec // priority should fit int32:
-	policy.Spec.Priority = int32(len(np.AdminNetworkPolicies))
+	policy.Spec.Priority = int32(np.priorityOffset + len(np.AdminNetworkPolicies))
 	if isInbound {
 		from := srcSelector.toAdminPolicyIngressPeers()
 		rules := []adminv1alpha1.AdminNetworkPolicyIngressRule{{From: from, Action: action, Ports: &ports}}
@@ -11,6 +11,7 @@ import (
 	"github.com/np-guard/vmware-analyzer/pkg/logging"
 	"github.com/np-guard/vmware-analyzer/pkg/synthesis/model"
 	"github.com/np-guard/vmware-analyzer/pkg/synthesis/model/symbolicexpr"
+	"github.com/np-guard/vmware-analyzer/pkg/synthesis/ocpvirt/policy_utils"
 	"github.com/np-guard/vmware-analyzer/pkg/synthesis/ocpvirt/resources"
 	"github.com/np-guard/vmware-analyzer/pkg/synthesis/ocpvirt/topology"
 	"github.com/np-guard/vmware-analyzer/pkg/synthesis/ocpvirt/utils"
@@ -23,27 +24,42 @@ type PolicyGenerator struct {
 	ExternalIP      *netset.IPBlock
 	synthModel      *model.AbstractModelSyn
 	createDNSPolicy bool
+	namePrefix      string
+	priorityOffset  int
 
 	// internal caching
 	conjunctionToSelector map[string]*policySelector
 
 	// additional output indicators
-	NotFullySupported bool
+	NotFullySupported     bool
+	UnsupportedConstructs []string // descriptions of nsx constructs for which policies were not generated
 
 	// generated resources
 	resources.Generated
 }
 
-func NewPolicyGenerator(synthModel *model.AbstractModelSyn, createDNSPolicy bool) *PolicyGenerator {
+func NewPolicyGenerator(synthModel *model.AbstractModelSyn, createDNSPolicy bool, namePrefix string,
+	priorityOffset int) *PolicyGenerator {
 	return &PolicyGenerator{
 		synthModel:      synthModel,
 		ExternalIP:      synthModel.ExternalIP,
 		createDNSPolicy: createDNSPolicy,
+		namePrefix:      namePrefix,
+		priorityOffset:  priorityOffset,
 
 		conjunctionToSelector: map[string]*policySelector{},
 	}
 }
 
+// policyName returns the name of a generated policy, prefixed by the name prefix if set,
+// so that policies generated by multiple phases of a migration do not conflict
+func (np *PolicyGenerator) policyName(name string) string {
+	if np.namePrefix == "" {
+		return name
+	}
+	return np.namePrefix + "-" + name
+}
+
 // main func to generate policy resources
 func (np *PolicyGenerator) Generate(ni *topology.NamespacesInfo) {
 	np.NamespacesInfo = ni
@@ -78,23 +94,26 @@ func (np *PolicyGenerator) symbolicRuleToPolicies(rule *model.SymbolicRule, isIn
 
 	for _, p := range paths {
 		if !p.Conn.TCPUDPSet().IsEmpty() {
-			np.symbolicPathToPolicy(p, isInbound, isAdmin, rule.OrigRule.Action, rule.OrigRule.RuleIDStr())
+			np.symbolicPathToPolicy(p, isInbound, isAdmin, rule.OrigRule)
 		} else {
 			logging.Infof("did not create the following k8s %s policy for nsx rule %d, since connection %s is not supported: %s",
 				directionStr(isInbound), rule.OrigRule.RuleID, p.Conn.String(), p.String())
+			np.UnsupportedConstructs = append(np.UnsupportedConstructs,
+				fmt.Sprintf("rule %d: connection %s is not supported", rule.OrigRule.RuleID, p.Conn.String()))
 		}
 	}
 }
 
-func (np *PolicyGenerator) symbolicPathToPolicy(path *symbolicexpr.SymbolicPath, isInbound, isAdmin bool,
-	action dfw.RuleAction, nsxRuleID string) {
+func (np *PolicyGenerator) symbolicPathToPolicy(path *symbolicexpr.SymbolicPath, isInbound, isAdmin bool, origRule *dfw.FwRule) {
+	action := origRule.Action
+	nsxRuleID := origRule.RuleIDStr()
 	srcSelector := np.createSelector(path.Src)
 	dstSelector := np.createSelector(path.Dst)
-	if len(srcSelector.namespaces) == 0 && !isInbound {
+	if len(srcSelector.subjectNamespaces) == 0 && !isInbound {
 		logging.Debugf("skip symbolicPathToPolicy for path [%s] , due to empty namespaces list on src", path.String())
 		return
 	}
-	if len(dstSelector.namespaces) == 0 && isInbound {
+	if len(dstSelector.subjectNamespaces) == 0 && isInbound {
 		logging.Debugf("skip symbolicPathToPolicy for path [%s] , due to empty namespaces list on dst", path.String())
 		return
 	}
@@ -108,14 +127,28 @@ func (np *PolicyGenerator) symbolicPathToPolicy(path *symbolicexpr.SymbolicPath,
 	if isAdmin && isInbound && !srcSelector.isTautology() && len(srcSelector.cidrs) > 0 {
 		logging.Warnf("Ignoring symbolic-path [ %s ] : ANP with src IP peers for Ingress is not supported", path.String())
 		np.NotFullySupported = true
+		np.UnsupportedConstructs = append(np.UnsupportedConstructs,
+			fmt.Sprintf("rule %s: ANP with src IP peers for Ingress is not supported", nsxRuleID))
+		return
+	}
+	if isAdmin && isInbound && len(srcSelector.notMigratedCidrs) > 0 {
+		logging.Warnf("Ignoring symbolic-path [ %s ] : ANP with src IP peers of VMs remaining on NSX for Ingress is not supported",
+			path.String())
+		np.NotFullySupported = true
+		np.UnsupportedConstructs = append(np.UnsupportedConstructs,
+			fmt.Sprintf("rule %s: ANP with src IP peers of VMs remaining on NSX for Ingress is not supported", nsxRuleID))
 		return
 	}
 	description := policyDescriptionFromSymbolicPath(path, isAdmin, action.String())
 
 	if isAdmin {
 		adminAction := abstractToAdminRuleAction[action]
-		np.addAdminNetworkPolicy(srcSelector, dstSelector, path.Conn, isInbound,
+		policy := np.addAdminNetworkPolicy(srcSelector, dstSelector, path.Conn, isInbound,
 			adminAction, description, nsxRuleID)
+		if origRule.IsReject() {
+			// AdminNetworkPolicy has no reject action - document the original action on the Deny policy
+			policy.Annotations[policy_utils.AnnotationNSXAction] = origRule.OrigAction.String()
+		}
 	} else {
 		np.addNetworkPolicy(srcSelector, dstSelector, path.Conn, isInbound, description, nsxRuleID)
 	}
@@ -130,7 +163,9 @@ func policyDescriptionFromSymbolicPath(path *symbolicexpr.SymbolicPath, isAdmin
 
 func (np *PolicyGenerator) createSelector(con symbolicexpr.Term) *policySelector {
 	if con == nil {
-		return newEmptyPolicySelector()
+		res := newEmptyPolicySelector()
+		res.subjectNamespaces = res.namespaces
+		return res
 	}
 
 	if cachedRes := np.conjunctionToSelector[con.String()]; cachedRes != nil {
@@ -167,6 +202,10 @@ func (np *PolicyGenerator) createSelector(con symbolicexpr.Term) *policySelector
 			res.pods.MatchExpressions = append(res.pods.MatchExpressions, req)
 		}
 	}
+	res.subjectNamespaces = np.NamespacesInfo.MigratedConjunctionNamespaces(con, res.namespaces)
+	if len(res.cidrs) == 0 {
+		res.notMigratedCidrs = np.NamespacesInfo.NotMigratedConjunctionCidrs(con)
+	}
 	np.conjunctionToSelector[con.String()] = res
 	logging.Debug2f("caching for conjunction %s , the following policySelector: %s", con.String(), res.string())
 	return res
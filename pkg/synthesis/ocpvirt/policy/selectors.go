@@ -20,6 +20,11 @@ type policySelector struct {
 	pods       *metav1.LabelSelector
 	cidrs      []string
 	namespaces []string
+
+	// phased migration: the namespaces of migrated vms selected as policy subject,
+	// and the cidrs of selected vms which remain on NSX, added as peers
+	subjectNamespaces []string
+	notMigratedCidrs  []string
 }
 
 func newEmptyPolicySelector() *policySelector {
@@ -66,22 +71,27 @@ func (selector *policySelector) convertAllCidrToAllPodsSelector() {
 	selector.cidrs = []string{}
 }
 
+func cidrsToPolicyPeers(cidrs []string) []networkingv1.NetworkPolicyPeer {
+	res := make([]networkingv1.NetworkPolicyPeer, len(cidrs))
+	for i, cidr := range cidrs {
+		res[i] = networkingv1.NetworkPolicyPeer{IPBlock: &networkingv1.IPBlock{CIDR: cidr}}
+	}
+	return res
+}
+
 func (selector *policySelector) toPolicyPeers(policyNamespace string) []networkingv1.NetworkPolicyPeer {
 	if !selector.isTautology() && len(selector.cidrs) > 0 {
-		res := make([]networkingv1.NetworkPolicyPeer, len(selector.cidrs))
-		for i, cidr := range selector.cidrs {
-			res[i] = networkingv1.NetworkPolicyPeer{IPBlock: &networkingv1.IPBlock{CIDR: cidr}}
-		}
-		return res
+		return cidrsToPolicyPeers(selector.cidrs)
 	}
 	nsSelector, discard := selector.namespaceLabelSelector(policyNamespace)
 	res := []networkingv1.NetworkPolicyPeer{{PodSelector: selector.pods, NamespaceSelector: nsSelector}}
 	if selector.isTautology() {
-		res = append(res, networkingv1.NetworkPolicyPeer{IPBlock: &networkingv1.IPBlock{CIDR: netset.CidrAll}})
+		// the vms which remain on NSX are already included in the all cidr
+		return append(res, networkingv1.NetworkPolicyPeer{IPBlock: &networkingv1.IPBlock{CIDR: netset.CidrAll}})
 	} else if discard {
-		return []networkingv1.NetworkPolicyPeer{}
+		res = []networkingv1.NetworkPolicyPeer{}
 	}
-	return res
+	return append(res, cidrsToPolicyPeers(selector.notMigratedCidrs)...)
 }
 
 func (selector *policySelector) toPodSelector() metav1.LabelSelector {
@@ -106,14 +116,19 @@ func (selector *policySelector) toAdminPolicyEgressPeers() []adminv1alpha1.Admin
 	}
 
 	if len(selector.cidrs) > 0 {
-		res := make([]adminv1alpha1.AdminNetworkPolicyEgressPeer, len(selector.cidrs))
-		for i, cidr := range selector.cidrs {
-			res[i] = adminv1alpha1.AdminNetworkPolicyEgressPeer{Networks: []adminv1alpha1.CIDR{adminv1alpha1.CIDR(cidr)}}
-		}
-		return res
+		return cidrsToAdminPolicyEgressPeers(selector.cidrs)
 	}
-	return []adminv1alpha1.AdminNetworkPolicyEgressPeer{
+	res := []adminv1alpha1.AdminNetworkPolicyEgressPeer{
 		{Pods: &adminv1alpha1.NamespacedPod{PodSelector: *selector.pods, NamespaceSelector: *selector.adminNamespaceLabelSelector()}}}
+	return append(res, cidrsToAdminPolicyEgressPeers(selector.notMigratedCidrs)...)
+}
+
+func cidrsToAdminPolicyEgressPeers(cidrs []string) []adminv1alpha1.AdminNetworkPolicyEgressPeer {
+	res := make([]adminv1alpha1.AdminNetworkPolicyEgressPeer, len(cidrs))
+	for i, cidr := range cidrs {
+		res[i] = adminv1alpha1.AdminNetworkPolicyEgressPeer{Networks: []adminv1alpha1.CIDR{adminv1alpha1.CIDR(cidr)}}
+	}
+	return res
 }
 func (selector *policySelector) toAdminPolicySubject() adminv1alpha1.AdminNetworkPolicySubject {
 	if selector.isTautology() {
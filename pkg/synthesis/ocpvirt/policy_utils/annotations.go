@@ -3,3 +3,4 @@ package policy_utils
 // common annotations consts
 const AnnotationDescription = "description"
 const AnnotationNSXRuleUID = "nsx-id"
+const AnnotationNSXAction = "nsx-action"
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"strings"
 
 	udnv1 "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/userdefinednetwork/v1"
 	core "k8s.io/api/core/v1"
@@ -23,6 +24,7 @@ type Generated struct {
 	VMs                  []*kubevirt.VirtualMachine
 	Namespaces           []*core.Namespace
 	UDNs                 []*udnv1.UserDefinedNetwork
+	ClusterUDNs          []*udnv1.ClusterUserDefinedNetwork
 	NetworkPolicies      []*networking.NetworkPolicy
 	AdminNetworkPolicies []*admin.AdminNetworkPolicy
 }
@@ -32,6 +34,7 @@ func (g *Generated) Log() {
 	logGeneratedResources("admin network policies", len(g.AdminNetworkPolicies))
 	logGeneratedResources("namespaces", len(g.Namespaces))
 	logGeneratedResources("udns", len(g.UDNs))
+	logGeneratedResources("cluster udns", len(g.ClusterUDNs))
 	logGeneratedResources("pods", len(g.Pods))
 	logGeneratedResources("vms", len(g.VMs))
 
@@ -52,6 +55,7 @@ func (g *Generated) CopyTopologyResources(g1 *Generated) {
 	g.VMs = g1.VMs
 	g.Namespaces = g1.Namespaces
 	g.UDNs = g1.UDNs
+	g.ClusterUDNs = g1.ClusterUDNs
 }
 
 const K8sResourcesDir = "k8s_resources" // todo: rename to ocp-virt-resources
@@ -70,8 +74,32 @@ func (g *Generated) WriteResourcesToDir(outDir string) error {
 	err4 := yamlWriter(g.UDNs, "udns.yaml", outDir)
 	err5 := yamlWriter(g.VMs, "vms.yaml", outDir)
 	err6 := yamlWriter(g.Pods, "pods.yaml", outDir)
+	err7 := yamlWriter(g.ClusterUDNs, "cudns.yaml", outDir)
 
-	return errors.Join(err1, err2, err3, err4, err5, err6)
+	return errors.Join(err1, err2, err3, err4, err5, err6, err7)
+}
+
+// ToYAML returns a single multi-document YAML with all generated OCP-Virt resources
+func (g *Generated) ToYAML() (string, error) {
+	var docs []string
+	for _, kindYAML := range []func() (string, error){
+		func() (string, error) { return common.YamlUsingJSON(g.Namespaces) },
+		func() (string, error) { return common.YamlUsingJSON(g.UDNs) },
+		func() (string, error) { return common.YamlUsingJSON(g.ClusterUDNs) },
+		func() (string, error) { return common.YamlUsingJSON(g.VMs) },
+		func() (string, error) { return common.YamlUsingJSON(g.Pods) },
+		func() (string, error) { return common.YamlUsingJSON(g.NetworkPolicies) },
+		func() (string, error) { return common.YamlUsingJSON(g.AdminNetworkPolicies) },
+	} {
+		doc, err := kindYAML()
+		if err != nil {
+			return "", err
+		}
+		if doc != "" {
+			docs = append(docs, doc)
+		}
+	}
+	return strings.Join(docs, "---\n"), nil
 }
 
 func yamlWriter[A any](content []A, file, outDir string) error {
@@ -22,7 +22,9 @@ type resourcesGenerator struct {
 	resources.Generated
 
 	// additional output values
-	NotFullySupported bool
+	NotFullySupported     bool
+	NotFullySupportedVMs  []string
+	UnsupportedConstructs []string
 }
 
 func newResourcesGenerator(synthModel *model.AbstractModelSyn, createDNSPolicy bool, options *config.SynthesisOptions) *resourcesGenerator {
@@ -32,7 +34,8 @@ func newResourcesGenerator(synthModel *model.AbstractModelSyn, createDNSPolicy b
 		options:         options,
 
 		topologyGen: topology.NewNetworkTopologyGenerator(synthModel, options),
-		policyGen:   policy.NewPolicyGenerator(synthModel, createDNSPolicy),
+		policyGen: policy.NewPolicyGenerator(synthModel, createDNSPolicy, options.PolicyNamePrefix,
+			options.ANPPriorityOffset),
 	}
 }
 
@@ -52,6 +55,8 @@ func (r *resourcesGenerator) generate() {
 
 	// update NotFullySupported indication
 	r.NotFullySupported = r.topologyGen.NotFullySupported || r.policyGen.NotFullySupported
+	r.NotFullySupportedVMs = r.topologyGen.NotFullySupportedVMs
+	r.UnsupportedConstructs = r.policyGen.UnsupportedConstructs
 
 	// summarize generation process
 	r.Log()
@@ -1,23 +1,41 @@
 package topology
 
 import (
+	"strings"
+
 	core "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	kubevirt "kubevirt.io/api/core/v1"
 
+	"github.com/np-guard/vmware-analyzer/pkg/configuration/topology"
 	"github.com/np-guard/vmware-analyzer/pkg/synthesis/ocpvirt/utils"
 )
 
 const migratedLabelValue = "true"
 
+// vCenter inventory data of migrated VMs, kept as labels and annotations of their VirtualMachine resources
+const (
+	vcenterPrefix            = "vcenter/"
+	vcenterGuestOSAnnotation = vcenterPrefix + "guest-os"
+	vcenterFolderAnnotation  = vcenterPrefix + "folder"
+	maxLabelValueLen         = 63
+)
+
 func (nt *NetworkTopologyGenerator) createPods() (res []*core.Pod) {
 	for _, vm := range nt.synthModel.VMs {
 		if len(nt.synthModel.EndpointsToGroups[vm]) == 0 {
 			// skipping vms without groups
 			continue
 		}
+		if !nt.NamespacesInfo.IsMigrated(vm) {
+			// skipping vms which remain on NSX in a phased migration
+			continue
+		}
 
-		nt.NotFullySupported = nt.NotFullySupported || len(nt.synthModel.VMsSegments[vm]) > 1
+		if len(nt.synthModel.VMsSegments[vm]) > 1 {
+			nt.NotFullySupported = true
+			nt.NotFullySupportedVMs = append(nt.NotFullySupportedVMs, vm.Name())
+		}
 		pod := &core.Pod{}
 		pod.Kind = "Pod"
 		pod.APIVersion = apiVersion
@@ -40,6 +58,10 @@ func (nt *NetworkTopologyGenerator) createVMs() (res []*kubevirt.VirtualMachine)
 			// skipping vms without groups
 			continue
 		}
+		if !nt.NamespacesInfo.IsMigrated(vm) {
+			// skipping vms which remain on NSX in a phased migration
+			continue
+		}
 		ocpVM := &kubevirt.VirtualMachine{}
 		ocpVM.Kind = "VirtualMachine"
 		ocpVM.APIVersion = "kubevirt.io/v1"
@@ -59,7 +81,43 @@ func (nt *NetworkTopologyGenerator) createVMs() (res []*kubevirt.VirtualMachine)
 		for _, label := range nt.synthModel.VMToLablesMap[vm.Name()] {
 			ocpVM.Spec.Template.ObjectMeta.Labels[utils.ToLegalK8SString(label)] = migratedLabelValue
 		}
+		addVCenterMetadata(ocpVM, vm)
+		if nt.options.VMSpecs {
+			nt.addVMSpec(ocpVM, vm)
+		}
 		res = append(res, ocpVM)
 	}
 	return res
 }
+
+// addVCenterMetadata adds the guest OS and folder of a VM collected from vCenter as annotations of its VirtualMachine,
+// and the VM custom attributes as labels of the VirtualMachine (not of its template, which is selected by policies)
+func addVCenterMetadata(ocpVM *kubevirt.VirtualMachine, vm topology.Endpoint) {
+	nsxVM, ok := vm.(*topology.VM)
+	if !ok || nsxVM.VCenterInfo() == nil {
+		return
+	}
+	info := nsxVM.VCenterInfo()
+	ocpVM.Annotations = map[string]string{}
+	if info.GuestOS != "" {
+		ocpVM.Annotations[vcenterGuestOSAnnotation] = info.GuestOS
+	}
+	if info.Folder != "" {
+		ocpVM.Annotations[vcenterFolderAnnotation] = info.Folder
+	}
+	ocpVM.Labels = map[string]string{}
+	for name, value := range info.CustomAttributes {
+		if labelName := toLabelValue(name); labelName != "" {
+			ocpVM.Labels[vcenterPrefix+labelName] = toLabelValue(value)
+		}
+	}
+}
+
+// toLabelValue returns a legal k8s label value (or label name) of the given string
+func toLabelValue(s string) string {
+	s = utils.ToLegalK8SString(s)
+	if len(s) > maxLabelValueLen {
+		s = s[:maxLabelValueLen]
+	}
+	return strings.Trim(s, "-_.")
+}
@@ -71,6 +71,9 @@ type NamespacesInfo struct {
 	labelVMs map[string][]topology.Endpoint // map from label key to its list of vms
 	options  *config.SynthesisOptions
 
+	// the migrated vms in a phased migration (nil if all vms are migrated)
+	migratedVMs map[topology.Endpoint]bool
+
 	// internal caching
 	cacheConjNamespaces map[string][]string // cache conj namespaces computed by GetConjunctionNamespaces()
 
@@ -88,6 +91,7 @@ func newNamespacesInfo(synthModel *model.AbstractModelSyn, options *config.Synth
 		cacheConjNamespaces: map[string][]string{},
 	}
 	res.initNamespaces(synthModel)
+	res.initMigratedVMs(synthModel)
 	return res
 }
 
@@ -117,7 +121,7 @@ func (ni *NamespacesInfo) initNamespaces(synthModel *model.AbstractModelSyn) {
 }
 
 func (ni *NamespacesInfo) createNamespaces() (res []*core.Namespace) {
-	for _, namespace := range ni.Namespaces {
+	for _, namespace := range ni.MigratedNamespaces() {
 		if namespace.Name != meta.NamespaceDefault {
 			res = append(res, namespace.createNamespace())
 		}
@@ -126,7 +130,7 @@ func (ni *NamespacesInfo) createNamespaces() (res []*core.Namespace) {
 }
 
 func (ni *NamespacesInfo) createUDNs() (res []*udnv1.UserDefinedNetwork) {
-	for _, namespace := range ni.Namespaces {
+	for _, namespace := range ni.MigratedNamespaces() {
 		if namespace.Name != meta.NamespaceDefault {
 			if udn := namespace.createNamespacePrimaryUDN(); udn != nil {
 				res = append(res, udn)
@@ -0,0 +1,119 @@
+package topology
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/np-guard/models/pkg/netset"
+
+	"github.com/np-guard/vmware-analyzer/internal/common"
+	"github.com/np-guard/vmware-analyzer/pkg/configuration/topology"
+	"github.com/np-guard/vmware-analyzer/pkg/logging"
+	"github.com/np-guard/vmware-analyzer/pkg/synthesis/model"
+	"github.com/np-guard/vmware-analyzer/pkg/synthesis/model/symbolicexpr"
+)
+
+// this file contains the phased migration functionality: in a phased migration only some of the VMs are migrated,
+// while the other VMs remain on NSX. the migrated VMs get k8s endpoints and policies, while traffic to/from VMs
+// remaining on NSX is expressed by their IP addresses.
+
+// initMigratedVMs computes the VMs migrated in a phased migration: VMs given by name, and VMs on segments given by name
+func (ni *NamespacesInfo) initMigratedVMs(synthModel *model.AbstractModelSyn) {
+	if !ni.options.IsPhased() {
+		return
+	}
+	ni.migratedVMs = map[topology.Endpoint]bool{}
+	for _, vm := range ni.vms {
+		if slices.Contains(ni.options.MigratedVMs, vm.Name()) {
+			ni.migratedVMs[vm] = true
+		}
+	}
+	for _, segment := range synthModel.Segments {
+		if slices.Contains(ni.options.MigratedSegments, segment.Name) {
+			for _, vm := range segment.VMs {
+				ni.migratedVMs[vm] = true
+			}
+		}
+	}
+	logging.Infof("phased migration of %d out of %d VMs", len(ni.migratedVMs), len(ni.vms))
+}
+
+// IsPhased returns true iff only some of the VMs are migrated
+func (ni *NamespacesInfo) IsPhased() bool {
+	return ni.migratedVMs != nil
+}
+
+// IsMigrated returns true iff the VM is migrated (always true if the migration is not phased)
+func (ni *NamespacesInfo) IsMigrated(vm topology.Endpoint) bool {
+	return !ni.IsPhased() || ni.migratedVMs[vm]
+}
+
+// MigratedNamespaces returns the namespaces of the migrated VMs, to which the namespace-wide resources are generated
+// (all namespaces if the migration is not phased)
+func (ni *NamespacesInfo) MigratedNamespaces() []*Namespace {
+	if !ni.IsPhased() {
+		return ni.Namespaces
+	}
+	return slices.DeleteFunc(slices.Clone(ni.Namespaces), func(namespace *Namespace) bool {
+		return !slices.ContainsFunc(ni.vms, func(vm topology.Endpoint) bool {
+			return ni.migratedVMs[vm] && ni.vmNamespace[vm] == namespace
+		})
+	})
+}
+
+// conjunctionVMs returns the VMs satisfying a symbolic conjunction expression
+func (ni *NamespacesInfo) conjunctionVMs(conj symbolicexpr.Term) []topology.Endpoint {
+	res := slices.Clone(ni.vms)
+	for _, atom := range conj {
+		switch {
+		case atom.IsTautology(), atom.IsAllGroups():
+			continue
+		case atom.IsAllExternal(), atom.GetExternalBlock() != nil:
+			return nil // external IPs are not VMs
+		default:
+			label, neg := atom.AsSelector()
+			vms := ni.labelVMs[label]
+			res = slices.DeleteFunc(res, func(vm topology.Endpoint) bool { return slices.Contains(vms, vm) == neg })
+		}
+	}
+	return res
+}
+
+// MigratedConjunctionNamespaces returns the namespaces of migrated VMs satisfying the conjunction,
+// out of the given namespaces of the conjunction
+func (ni *NamespacesInfo) MigratedConjunctionNamespaces(conj symbolicexpr.Term, namespaces []string) []string {
+	if !ni.IsPhased() {
+		return namespaces
+	}
+	migratedVMs := slices.DeleteFunc(ni.conjunctionVMs(conj), func(vm topology.Endpoint) bool { return !ni.migratedVMs[vm] })
+	migratedNamespaces := ni.namespacesStrings(ni.vmsNamespaces(migratedVMs))
+	return slices.DeleteFunc(slices.Clone(namespaces), func(ns string) bool { return !slices.Contains(migratedNamespaces, ns) })
+}
+
+// NotMigratedConjunctionCidrs returns the cidrs of the IP addresses of VMs which are not migrated and satisfy the conjunction
+func (ni *NamespacesInfo) NotMigratedConjunctionCidrs(conj symbolicexpr.Term) []string {
+	if !ni.IsPhased() {
+		return nil
+	}
+	block := netset.NewIPBlock()
+	for _, vm := range ni.conjunctionVMs(conj) {
+		if ni.migratedVMs[vm] {
+			continue
+		}
+		for _, ip := range strings.Split(vm.IPAddressesStr(), common.CommaSeparator) {
+			if ip == "" {
+				continue
+			}
+			ipBlock, err := common.IPBlockFromCidrOrAddressOrIPRange(ip)
+			if err != nil {
+				logging.Debugf("failed to parse IP address %s of VM %s, ignoring this IP", ip, vm.Name())
+				continue
+			}
+			block = block.Union(ipBlock)
+		}
+	}
+	if block.IsEmpty() {
+		return nil
+	}
+	return block.ToCidrList()
+}
@@ -17,7 +17,11 @@ type NetworkTopologyGenerator struct {
 	// objects for generation process
 	NamespacesInfo *NamespacesInfo
 	// output indicators
-	NotFullySupported bool
+	NotFullySupported    bool
+	NotFullySupportedVMs []string // VMs connected to multiple segments
+
+	// the secondary networks of VM interfaces, recorded upon VM generation
+	secondaryNets secondaryNetworks
 
 	// generated resources
 	resources.Generated
@@ -50,4 +54,6 @@ func (nt *NetworkTopologyGenerator) Generate() {
 		nt.VMs = nt.createVMs()
 		nt.Pods = nt.createPods()
 	}
+	// secondary networks of the interfaces of the generated VM specs
+	nt.ClusterUDNs = nt.secondaryNets.createClusterUDNs()
 }
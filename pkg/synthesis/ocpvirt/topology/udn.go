@@ -1,6 +1,19 @@
 package topology
 
-import udnv1 "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/userdefinednetwork/v1"
+import (
+	"slices"
+
+	udnv1 "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/userdefinednetwork/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/np-guard/vmware-analyzer/pkg/configuration/topology"
+	"github.com/np-guard/vmware-analyzer/pkg/synthesis/ocpvirt/utils"
+)
+
+const (
+	secondaryNetworkPrefix = "cudn-"
+	namespaceNameLabel     = "kubernetes.io/metadata.name"
+)
 
 func createUDNResource(name, namespace, cidr string) *udnv1.UserDefinedNetwork {
 	res := &udnv1.UserDefinedNetwork{}
@@ -19,4 +32,52 @@ func createUDNResource(name, namespace, cidr string) *udnv1.UserDefinedNetwork {
 	return res
 }
 
-// todo: support NAD , CUDN, etc..
+// secondaryNetworks captures the segments of VM interfaces which are not on the segment of the VM namespace,
+// with the namespaces of their VMs, to generate a secondary network per segment shared by these namespaces
+type secondaryNetworks struct {
+	segments   []*topology.Segment
+	namespaces map[*topology.Segment][]string
+}
+
+func secondaryNetworkName(segment *topology.Segment) string {
+	return secondaryNetworkPrefix + utils.ToLegalK8SString(segment.Name)
+}
+
+// add records a VM interface on a segment, and returns the name of its network as referenced from the VM namespace
+func (sn *secondaryNetworks) add(segment *topology.Segment, namespace string) string {
+	if sn.namespaces == nil {
+		sn.namespaces = map[*topology.Segment][]string{}
+	}
+	if _, ok := sn.namespaces[segment]; !ok {
+		sn.segments = append(sn.segments, segment)
+	}
+	if !slices.Contains(sn.namespaces[segment], namespace) {
+		sn.namespaces[segment] = append(sn.namespaces[segment], namespace)
+	}
+	return namespace + "/" + secondaryNetworkName(segment)
+}
+
+// createClusterUDNs returns a secondary layer2 ClusterUserDefinedNetwork per recorded segment, available in the namespaces
+// of its VMs, so that the VM interfaces on the segment are connected to the same network across namespaces
+func (sn *secondaryNetworks) createClusterUDNs() (res []*udnv1.ClusterUserDefinedNetwork) {
+	for _, segment := range sn.segments {
+		namespaces := slices.Clone(sn.namespaces[segment])
+		slices.Sort(namespaces)
+		cidrs := segment.Block.ToCidrList()
+		layer2 := &udnv1.Layer2Config{Role: udnv1.NetworkRoleSecondary, IPAM: &udnv1.IPAMConfig{Mode: udnv1.IPAMDisabled}}
+		if len(cidrs) == 1 {
+			layer2.Subnets = udnv1.DualStackCIDRs{udnv1.CIDR(cidrs[0])}
+			layer2.IPAM = &udnv1.IPAMConfig{Lifecycle: udnv1.IPAMLifecyclePersistent}
+		}
+		cudn := &udnv1.ClusterUserDefinedNetwork{}
+		cudn.Kind = "ClusterUserDefinedNetwork"
+		cudn.APIVersion = "k8s.ovn.org/v1"
+		cudn.Name = secondaryNetworkName(segment)
+		cudn.Spec.NamespaceSelector = metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+			{Key: namespaceNameLabel, Operator: metav1.LabelSelectorOpIn, Values: namespaces},
+		}}
+		cudn.Spec.Network = udnv1.NetworkSpec{Topology: udnv1.NetworkTopologyLayer2, Layer2: layer2}
+		res = append(res, cudn)
+	}
+	return res
+}
@@ -0,0 +1,164 @@
+package topology
+
+import (
+	"fmt"
+
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirt "kubevirt.io/api/core/v1"
+	cdi "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+
+	"github.com/np-guard/vmware-analyzer/internal/common"
+	nsx "github.com/np-guard/vmware-analyzer/pkg/configuration/generated"
+	"github.com/np-guard/vmware-analyzer/pkg/configuration/topology"
+	"github.com/np-guard/vmware-analyzer/pkg/logging"
+	"github.com/np-guard/vmware-analyzer/pkg/synthesis/ocpvirt/policy_utils"
+)
+
+const (
+	// sizing of VMs whose vCenter inventory data was not collected
+	defaultVMCores     = 1
+	defaultVMMemoryMiB = 2048
+
+	rootDiskName             = "rootdisk"
+	placeholderDiskSize      = "30Gi"
+	placeholderDescription   = "placeholder for the disk of the migrated VM, the blank source should be replaced by the imported disk"
+	podNetworkName           = "default"
+	primaryUDNBindingPlugin  = "l2bridge" // the binding of VM interfaces to a layer2 primary UDN
+	secondaryNetworkIfPrefix = "nic"
+
+	mib = 1 << 20
+
+	// vCenter VM power states
+	vcenterPowerStateOn      = "POWERED_ON"
+	vcenterPowerStateOff     = "POWERED_OFF"
+	vcenterPowerStateSuspend = "SUSPENDED"
+)
+
+// addVMSpec populates the spec of a VirtualMachine from the collected data of its NSX VM:
+// compute resources, run strategy by power state, an interface per NSX VIF and a placeholder root disk
+func (nt *NetworkTopologyGenerator) addVMSpec(ocpVM *kubevirt.VirtualMachine, vm topology.Endpoint) {
+	nsxVM, ok := vm.(*topology.VM)
+	if !ok {
+		return
+	}
+	cores, memoryMiB := vmSizing(nsxVM)
+	runStrategy := vmRunStrategy(nsxVM)
+	ocpVM.Spec.RunStrategy = &runStrategy
+
+	template := &ocpVM.Spec.Template.Spec
+	template.Domain.CPU = &kubevirt.CPU{Cores: cores}
+	template.Domain.Memory = &kubevirt.Memory{Guest: resource.NewQuantity(memoryMiB*mib, resource.BinarySI)}
+	template.Domain.Devices.Interfaces, template.Networks = nt.vmInterfaces(nsxVM)
+
+	dataVolumeName := ocpVM.Name + "-" + rootDiskName
+	template.Domain.Devices.Disks = []kubevirt.Disk{{
+		Name:       rootDiskName,
+		DiskDevice: kubevirt.DiskDevice{Disk: &kubevirt.DiskTarget{Bus: kubevirt.DiskBusVirtio}},
+	}}
+	template.Volumes = []kubevirt.Volume{{
+		Name:         rootDiskName,
+		VolumeSource: kubevirt.VolumeSource{DataVolume: &kubevirt.DataVolumeSource{Name: dataVolumeName}},
+	}}
+	ocpVM.Spec.DataVolumeTemplates = []kubevirt.DataVolumeTemplateSpec{{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        dataVolumeName,
+			Annotations: map[string]string{policy_utils.AnnotationDescription: placeholderDescription},
+		},
+		Spec: cdi.DataVolumeSpec{
+			Source: &cdi.DataVolumeSource{Blank: &cdi.DataVolumeBlankImage{}},
+			Storage: &cdi.StorageSpec{
+				Resources: core.VolumeResourceRequirements{
+					Requests: core.ResourceList{core.ResourceStorage: resource.MustParse(placeholderDiskSize)},
+				},
+			},
+		},
+	}}
+}
+
+// vmSizing returns the number of cores and memory MiB of a VM, as collected from vCenter
+func vmSizing(vm *topology.VM) (cores uint32, memoryMiB int64) {
+	cores, memoryMiB = defaultVMCores, defaultVMMemoryMiB
+	info := vm.VCenterInfo()
+	if info == nil {
+		logging.Debugf("no vCenter data of VM %s, generating it with %d cores and %d MiB", vm.Name(), cores, memoryMiB)
+		return cores, memoryMiB
+	}
+	if info.CPUCount > 0 {
+		cores = uint32(info.CPUCount) //nolint:gosec // a cpu count is small
+	}
+	if info.MemoryMiB > 0 {
+		memoryMiB = int64(info.MemoryMiB)
+	}
+	return cores, memoryMiB
+}
+
+// vmRunStrategy maps the power state of a VM, as collected from vCenter or NSX, to the run strategy of its VirtualMachine
+func vmRunStrategy(vm *topology.VM) kubevirt.VirtualMachineRunStrategy {
+	powerState := vm.PowerState()
+	if info := vm.VCenterInfo(); info != nil && info.PowerState != "" {
+		powerState = info.PowerState
+	}
+	switch powerState {
+	case string(nsx.VirtualMachinePowerStateVMRUNNING), vcenterPowerStateOn:
+		return kubevirt.RunStrategyAlways
+	case string(nsx.VirtualMachinePowerStateVMSTOPPED), string(nsx.VirtualMachinePowerStateVMSUSPENDED),
+		vcenterPowerStateOff, vcenterPowerStateSuspend:
+		return kubevirt.RunStrategyHalted
+	default:
+		return kubevirt.RunStrategyManual
+	}
+}
+
+// vmInterfaces returns an interface per NSX VIF of the VM, preserving its MAC address.
+// The VIF on the segment of the VM namespace is connected to the pod network, which is the primary UDN of the namespace
+// when segments are mapped to UDNs; VIFs on other segments are connected to the secondary networks of their segments
+func (nt *NetworkTopologyGenerator) vmInterfaces(vm *topology.VM) (interfaces []kubevirt.Interface, networks []kubevirt.Network) {
+	var namespaceSegment *topology.Segment
+	namespaceName := metav1.NamespaceDefault
+	if namespace := nt.NamespacesInfo.vmNamespace[vm]; namespace != nil {
+		namespaceSegment = namespace.origNSXSegment
+		namespaceName = namespace.Name
+	}
+	podInterface := kubevirt.Interface{Name: podNetworkName}
+	if nt.options.SegmentsMapping == common.SegmentsToUDNs && namespaceSegment != nil {
+		podInterface.Binding = &kubevirt.PluginBinding{Name: primaryUDNBindingPlugin}
+	} else {
+		podInterface.Masquerade = &kubevirt.InterfaceMasquerade{}
+	}
+	podNetworkFound := false
+	for i, nic := range vm.NICs() {
+		if !podNetworkFound && (nic.Segment == namespaceSegment || namespaceSegment == nil) {
+			podNetworkFound = true
+			podInterface.MacAddress = nic.MACAddress
+			continue
+		}
+		name := fmt.Sprintf("%s%d", secondaryNetworkIfPrefix, i)
+		interfaces = append(interfaces, kubevirt.Interface{
+			Name:                   name,
+			MacAddress:             nic.MACAddress,
+			InterfaceBindingMethod: kubevirt.InterfaceBindingMethod{Bridge: &kubevirt.InterfaceBridge{}},
+		})
+		networkName := nt.secondaryNets.add(nic.Segment, namespaceName)
+		networks = append(networks, kubevirt.Network{
+			Name:          name,
+			NetworkSource: kubevirt.NetworkSource{Multus: &kubevirt.MultusNetwork{NetworkName: networkName}},
+		})
+	}
+	if !podNetworkFound {
+		podInterface.MacAddress = vcenterMACAddress(vm)
+	}
+	interfaces = append([]kubevirt.Interface{podInterface}, interfaces...)
+	networks = append([]kubevirt.Network{{Name: podNetworkName, NetworkSource: kubevirt.NetworkSource{Pod: &kubevirt.PodNetwork{}}}},
+		networks...)
+	return interfaces, networks
+}
+
+// vcenterMACAddress returns the MAC address of the first network adapter of a VM without NSX VIFs, as collected from vCenter
+func vcenterMACAddress(vm *topology.VM) string {
+	if info := vm.VCenterInfo(); info != nil && len(info.NICs) > 0 {
+		return info.NICs[0].MACAddress
+	}
+	return ""
+}
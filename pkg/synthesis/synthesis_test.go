@@ -11,7 +11,10 @@ import (
 	"strings"
 	"testing"
 
+	udnv1 "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/userdefinednetwork/v1"
 	"github.com/stretchr/testify/require"
+	kubevirt "kubevirt.io/api/core/v1"
+	adminv1alpha1 "sigs.k8s.io/network-policy-api/apis/v1alpha1"
 
 	"github.com/np-guard/models/pkg/netp"
 	"github.com/np-guard/models/pkg/netset"
@@ -20,6 +23,7 @@ import (
 	"github.com/np-guard/vmware-analyzer/pkg/analyzer/connectivity"
 	"github.com/np-guard/vmware-analyzer/pkg/collector"
 	"github.com/np-guard/vmware-analyzer/pkg/configuration"
+	nsxapi "github.com/np-guard/vmware-analyzer/pkg/configuration/generated"
 	"github.com/np-guard/vmware-analyzer/pkg/configuration/topology"
 	"github.com/np-guard/vmware-analyzer/pkg/data"
 	"github.com/np-guard/vmware-analyzer/pkg/internal/test_utils"
@@ -30,6 +34,7 @@ import (
 	"github.com/np-guard/vmware-analyzer/pkg/synthesis/model/symbolicexpr"
 	"github.com/np-guard/vmware-analyzer/pkg/synthesis/nsx"
 	"github.com/np-guard/vmware-analyzer/pkg/synthesis/ocpvirt"
+	"github.com/np-guard/vmware-analyzer/pkg/synthesis/ocpvirt/policy_utils"
 	"github.com/np-guard/vmware-analyzer/pkg/synthesis/ocpvirt/resources"
 	"github.com/np-guard/vmware-analyzer/pkg/synthesis/ocpvirt/utils"
 )
@@ -998,3 +1003,260 @@ func TestOptimizationLevel(t *testing.T) {
 		testOptimizationLevel(t, test.level, test.exData)
 	}
 }
+
+func testPhasedMigration(t *testing.T, exData *data.Example, useAdmin bool, migratedVMs, migratedSegments []string) {
+	rc, err := data.ExamplesGeneration(exData, false)
+	require.Nil(t, err)
+	adminName := "_noAdmin"
+	if useAdmin {
+		adminName = "_admin"
+	}
+	testDirName := filepath.Join("phased_migration_tests", exData.Name+adminName)
+
+	expectedOutputDir := filepath.Join(getTestsDirExpectedOut(), testDirName)
+	actualOutputDir := filepath.Join(getTestsDirActualOut(), testDirName)
+
+	runnerObj, err := runner.NewRunnerWithOptionsList(
+		runner.WithNSXResources(rc),
+		runner.WithHighVerbosity(true),
+		runner.WithCmd(common.CmdGenerate),
+		runner.WithSynthesisDir(actualOutputDir),
+		runner.WithSynthAdminPolicies(useAdmin),
+		runner.WithMigratedVMs(migratedVMs),
+		runner.WithMigratedSegments(migratedSegments),
+		runner.WithPolicyNamePrefix("phase-1"),
+	)
+	require.Nil(t, err)
+	_, err = runnerObj.Run()
+	require.Nil(t, err)
+	compareOrRegenerateOutputDirPerTest(t,
+		filepath.Join(actualOutputDir, resources.K8sResourcesDir),
+		filepath.Join(expectedOutputDir, resources.K8sResourcesDir),
+		testDirName)
+}
+
+func TestPhasedMigration(t *testing.T) {
+	phasedTests := []struct {
+		exData           *data.Example
+		useAdmin         bool
+		migratedVMs      []string
+		migratedSegments []string
+	}{
+		{
+			exData:           data.ExampleAppWithGroupsAndSegments,
+			useAdmin:         false,
+			migratedSegments: []string{"T1-192-168-0-0"},
+		},
+		{
+			exData:      data.ExampleHogwarts,
+			useAdmin:    true,
+			migratedVMs: []string{"Gryffindor-Web", "Gryffindor-App", "Gryffindor-DB"},
+		},
+	}
+	for _, test := range phasedTests {
+		testPhasedMigration(t, test.exData, test.useAdmin, test.migratedVMs, test.migratedSegments)
+	}
+}
+
+func TestPhasedMigrationInvalidPrefix(t *testing.T) {
+	_, err := runner.NewRunnerWithOptionsList(runner.WithPolicyNamePrefix("Phase_1"))
+	require.NotNil(t, err)
+}
+
+// validate that the admin policies of phases with different priority offsets do not conflict
+func TestPhasedMigrationPriorityOffset(t *testing.T) {
+	rc, err := data.ExamplesGeneration(data.ExampleHogwarts, false)
+	require.Nil(t, err)
+	generate := func(migratedVMs []string, offset int) []int32 {
+		runnerObj, err := runner.NewRunnerWithOptionsList(
+			runner.WithNSXResources(rc),
+			runner.WithCmd(common.CmdGenerate),
+			runner.WithSynthesisDir(t.TempDir()),
+			runner.WithSynthAdminPolicies(true),
+			runner.WithMigratedVMs(migratedVMs),
+			runner.WithANPPriorityOffset(offset),
+		)
+		require.Nil(t, err)
+		_, err = runnerObj.Run()
+		require.Nil(t, err)
+		priorities := []int32{}
+		for _, anp := range runnerObj.GetGeneratedResources().AdminNetworkPolicies {
+			priorities = append(priorities, anp.Spec.Priority)
+		}
+		return priorities
+	}
+	phase1 := generate([]string{"Gryffindor-Web", "Gryffindor-App", "Gryffindor-DB"}, 0)
+	phase2 := generate([]string{"Hufflepuff-Web", "Hufflepuff-App", "Hufflepuff-DB"}, len(phase1))
+	require.NotEmpty(t, phase1)
+	require.NotEmpty(t, phase2)
+	for _, priority := range phase2 {
+		require.Greater(t, priority, int32(len(phase1)))
+	}
+
+	_, err = runner.NewRunnerWithOptionsList(runner.WithANPPriorityOffset(1000))
+	require.NotNil(t, err)
+}
+
+// validate that the admin policies generated for a reject rule document the original action, as ANPs have no reject action
+func TestRejectAnnotation(t *testing.T) {
+	rc, err := data.ExamplesGeneration(data.ExampleAppWithGroupsAdditionalRejectRule, false)
+	require.Nil(t, err)
+	// admin policies are generated only for the rules of non-application categories, and not for ICMP;
+	// the reject rule is the first rule of the first policy
+	rejectPolicy := &rc.DomainList[0].Resources.SecurityPolicyList[0]
+	rejectPolicy.Category = common.PointerTo(collector.EnvironmentStr)
+	rejectPolicy.Rules[0].Services = []string{"/infra/services/HTTP"}
+	runnerObj, err := runner.NewRunnerWithOptionsList(
+		runner.WithNSXResources(rc),
+		runner.WithCmd(common.CmdGenerate),
+		runner.WithSynthAdminPolicies(true),
+	)
+	require.Nil(t, err)
+	_, err = runnerObj.Run()
+	require.Nil(t, err)
+	rejectPolicies := 0
+	for _, anp := range runnerObj.GetGeneratedResources().AdminNetworkPolicies {
+		action, ok := anp.Annotations[policy_utils.AnnotationNSXAction]
+		if anp.Annotations[policy_utils.AnnotationNSXRuleUID] != "1029" {
+			require.False(t, ok, "unexpected %s annotation on %s", policy_utils.AnnotationNSXAction, anp.Name)
+			continue
+		}
+		rejectPolicies++
+		require.Equal(t, "reject", action)
+		for i := range anp.Spec.Ingress {
+			require.Equal(t, adminv1alpha1.AdminNetworkPolicyRuleActionDeny, anp.Spec.Ingress[i].Action)
+		}
+		for i := range anp.Spec.Egress {
+			require.Equal(t, adminv1alpha1.AdminNetworkPolicyRuleActionDeny, anp.Spec.Egress[i].Action)
+		}
+	}
+	require.Positive(t, rejectPolicies)
+}
+
+// validate that vCenter inventory data of VMs is kept in the metadata of their generated VirtualMachine resources
+func TestVCenterInfo(t *testing.T) {
+	rc, err := data.ExamplesGeneration(data.ExampleStatelessPolicy, false)
+	require.Nil(t, err)
+	i := slices.IndexFunc(rc.VirtualMachineList, func(vm collector.VirtualMachine) bool { return *vm.DisplayName == "A" })
+	require.NotEqual(t, -1, i)
+	rc.VirtualMachineList[i].VCenterInfo = &collector.VCenterVMInfo{
+		ID:               "vm-1",
+		GuestOS:          "RHEL_9_64",
+		Folder:           "production",
+		CustomAttributes: map[string]string{"owner": "team a", "": "ignored"},
+	}
+
+	runnerObj, err := runner.NewRunnerWithOptionsList(
+		runner.WithNSXResources(rc),
+		runner.WithCmd(common.CmdGenerate),
+		runner.WithSynthesisDir(t.TempDir()),
+	)
+	require.Nil(t, err)
+	_, err = runnerObj.Run()
+	require.Nil(t, err)
+
+	require.NotEmpty(t, runnerObj.GetGeneratedResources().VMs)
+	for _, vm := range runnerObj.GetGeneratedResources().VMs {
+		if vm.Name != "A" {
+			require.Empty(t, vm.Annotations)
+			require.Empty(t, vm.Labels)
+			continue
+		}
+		require.Equal(t, map[string]string{"vcenter/guest-os": "RHEL_9_64", "vcenter/folder": "production"}, vm.Annotations)
+		require.Equal(t, map[string]string{"vcenter/owner": "teama"}, vm.Labels)
+		require.NotContains(t, vm.Spec.Template.ObjectMeta.Labels, "vcenter/owner")
+	}
+}
+
+func TestVMSpecs(t *testing.T) {
+	rc, err := data.ExamplesGeneration(data.ExampleInternalWithInterDenyAllowWithSegments, false)
+	require.Nil(t, err)
+	for i := range rc.VirtualMachineList {
+		switch *rc.VirtualMachineList[i].DisplayName {
+		case "vm1":
+			rc.VirtualMachineList[i].VCenterInfo = &collector.VCenterVMInfo{CPUCount: 4, MemoryMiB: 8192, PowerState: "POWERED_OFF"}
+		case "vm2":
+			rc.VirtualMachineList[i].PowerState = common.PointerTo(nsxapi.VirtualMachinePowerStateVMRUNNING)
+		}
+	}
+	macAddresses := map[string]string{}
+	for i := range rc.VirtualNetworkInterfaceList {
+		vni := &rc.VirtualNetworkInterfaceList[i]
+		macAddresses[*vni.OwnerVmId] = fmt.Sprintf("00:50:56:00:00:%02x", i)
+		vni.MacAddress = common.PointerTo(macAddresses[*vni.OwnerVmId])
+	}
+	// vm1 has a second NIC, on the segment of vm9 and vm10
+	const secondaryMAC, secondarySegment = "00:50:56:00:01:00", "seg-9-10"
+	secondaryPort := "port_vm1_" + secondarySegment
+	rc.GetSegment(secondarySegment).SegmentPorts = append(rc.GetSegment(secondarySegment).SegmentPorts, collector.SegmentPort{
+		SegmentPort: nsxapi.SegmentPort{DisplayName: &secondaryPort, UniqueId: &secondaryPort,
+			ParentPath: common.PointerTo(secondarySegment), Attachment: &nsxapi.PortAttachment{Id: &secondaryPort}},
+	})
+	rc.VirtualNetworkInterfaceList = append(rc.VirtualNetworkInterfaceList, collector.VirtualNetworkInterface{
+		VirtualNetworkInterface: nsxapi.VirtualNetworkInterface{LportAttachmentId: &secondaryPort,
+			OwnerVmId: common.PointerTo("vm1"), MacAddress: common.PointerTo(secondaryMAC)},
+	})
+
+	generate := func(vmSpecs bool) *resources.Generated {
+		runnerObj, err := runner.NewRunnerWithOptionsList(
+			runner.WithNSXResources(rc),
+			runner.WithCmd(common.CmdGenerate),
+			runner.WithSynthesisDir(t.TempDir()),
+			runner.WithSegmentsMapping(string(common.SegmentsToUDNs)),
+			runner.WithVMSpecs(vmSpecs),
+		)
+		require.Nil(t, err)
+		_, err = runnerObj.Run()
+		require.Nil(t, err)
+		return runnerObj.GetGeneratedResources()
+	}
+
+	// without the option, the VirtualMachines have only labels
+	withoutSpecs := generate(false)
+	require.Empty(t, withoutSpecs.ClusterUDNs)
+	for _, vm := range withoutSpecs.VMs {
+		require.Nil(t, vm.Spec.RunStrategy)
+		require.Empty(t, vm.Spec.Template.Spec.Domain.Devices.Interfaces)
+		require.Empty(t, vm.Spec.DataVolumeTemplates)
+	}
+
+	generated := generate(true)
+	require.NotEmpty(t, generated.VMs)
+	// the second NIC of vm1 is connected to a secondary network of its segment, available in the namespace of vm1
+	require.Len(t, generated.ClusterUDNs, 1)
+	cudn := generated.ClusterUDNs[0]
+	require.Equal(t, "cudn-"+secondarySegment, cudn.Name)
+	require.Equal(t, udnv1.NetworkRoleSecondary, cudn.Spec.Network.Layer2.Role)
+	for _, vm := range generated.VMs {
+		spec := vm.Spec.Template.Spec
+		if vm.Name == "vm1" {
+			require.Len(t, spec.Domain.Devices.Interfaces, 2)
+			require.Equal(t, secondaryMAC, spec.Domain.Devices.Interfaces[1].MacAddress)
+			require.NotNil(t, spec.Domain.Devices.Interfaces[1].Bridge)
+			require.Equal(t, vm.Namespace+"/"+cudn.Name, spec.Networks[1].Multus.NetworkName)
+			require.Equal(t, []string{vm.Namespace}, cudn.Spec.NamespaceSelector.MatchExpressions[0].Values)
+		} else {
+			require.Len(t, spec.Domain.Devices.Interfaces, 1)
+		}
+		require.Equal(t, macAddresses[vm.Name], spec.Domain.Devices.Interfaces[0].MacAddress)
+		require.NotNil(t, spec.Networks[0].Pod)
+		if _, ok := macAddresses[vm.Name]; ok {
+			// the interface on the segment of the VM is bound to the primary UDN of its namespace
+			require.NotNil(t, spec.Domain.Devices.Interfaces[0].Binding)
+		}
+		require.Len(t, vm.Spec.DataVolumeTemplates, 1)
+		require.Equal(t, vm.Spec.DataVolumeTemplates[0].Name, spec.Volumes[0].DataVolume.Name)
+		switch vm.Name {
+		case "vm1":
+			require.Equal(t, kubevirt.RunStrategyHalted, *vm.Spec.RunStrategy)
+			require.Equal(t, uint32(4), spec.Domain.CPU.Cores)
+			require.Equal(t, "8Gi", spec.Domain.Memory.Guest.String())
+		case "vm2":
+			require.Equal(t, kubevirt.RunStrategyAlways, *vm.Spec.RunStrategy)
+			require.Equal(t, uint32(1), spec.Domain.CPU.Cores)
+			require.Equal(t, "2Gi", spec.Domain.Memory.Guest.String())
+		default:
+			require.Equal(t, kubevirt.RunStrategyManual, *vm.Spec.RunStrategy)
+		}
+	}
+}